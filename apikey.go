@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// apiKey Статический ключ API клиента
+type apiKey struct {
+	name string   // Имя клиента (subject в контексте запроса)
+	role string   // Роль клиента (пусто - без роли)
+	hash [32]byte // SHA-256 ключа: сравнение хэшей одинаковой длины не раскрывает длину ключа
+}
+
+// APIKeys Набор ключей API (каждый клиент со своим ключом, чтобы отзывать их независимо)
+type APIKeys struct {
+	keys []apiKey
+}
+
+// parseAPIKey Разбор записи ключа вида name:key или name:key:role
+func parseAPIKey(entry string) (apiKey, error) {
+	name, key, ok := strings.Cut(strings.TrimSpace(entry), ":")
+	key, role, _ := strings.Cut(key, ":")
+	name, key, role = strings.TrimSpace(name), strings.TrimSpace(key), strings.TrimSpace(role)
+	if !ok || name == "" || key == "" {
+		return apiKey{}, fmt.Errorf("invalid API key entry %q: want name:key or name:key:role", entry)
+	}
+	return apiKey{name: name, role: role, hash: sha256.Sum256([]byte(key))}, nil
+}
+
+// add Добавление ключа (имена клиентов должны быть уникальны)
+func (k *APIKeys) add(entry string) error {
+	key, err := parseAPIKey(entry)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(k.keys, func(other apiKey) bool { return other.name == key.name }) {
+		return fmt.Errorf("duplicate API key name %q", key.name)
+	}
+	k.keys = append(k.keys, key)
+	return nil
+}
+
+// readAPIKeys Чтение ключей из потока: по записи name:key[:role] в строке, пустые строки и строки с # пропускаются
+func (k *APIKeys) readAPIKeys(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if err := k.add(entry); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// loadAPIKeys Загрузка ключей из флагов: список через запятую и файл (nil, если ключей нет - API открыт)
+func loadAPIKeys(list, file string) (*APIKeys, error) {
+	keys := &APIKeys{}
+	for _, entry := range strings.Split(list, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		if err := keys.add(entry); err != nil {
+			return nil, err
+		}
+	}
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := keys.readAPIKeys(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	if len(keys.keys) == 0 {
+		return nil, nil
+	}
+	return keys, nil
+}
+
+// Lookup Поиск клиента по ключу. Сравниваются все ключи за постоянное время, чтобы время ответа не выдавало,
+// какой ключ совпал частично
+func (k *APIKeys) Lookup(key string) (Identity, bool) {
+	hash := sha256.Sum256([]byte(key))
+	var identity Identity
+	found := false
+	for _, candidate := range k.keys {
+		if subtle.ConstantTimeCompare(hash[:], candidate.hash[:]) == 1 {
+			identity, found = Identity{Subject: candidate.name}, true
+			if candidate.role != "" {
+				identity.Roles = []string{candidate.role}
+			}
+		}
+	}
+	return identity, found
+}
+
+// apiKeyMiddleware Middleware, требующее известный ключ в заголовке X-API-Key (кроме publicPaths)
+// и кладущее имя клиента в контекст запроса
+func apiKeyMiddleware(keys *APIKeys, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(publicPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			logRequest(r, slog.LevelWarn, "[apiKeyMiddleware] Missing API key", nil)
+			writeUnauthorized(w, "APIKey", "missing API key")
+			return
+		}
+		identity, ok := keys.Lookup(key)
+		if !ok {
+			logRequest(r, slog.LevelWarn, "[apiKeyMiddleware] Unknown API key", nil)
+			writeUnauthorized(w, "APIKey", "invalid API key")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Проверка аутентификации по ключам API
+// Сценарий:
+// 1. Загрузить ключ ci из флага и ключ backup из файла (с комментарием и пустой строкой).
+// 2. Запросить /todos без ключа и с неизвестным ключом - ожидаем 401 Unauthorized.
+// 3. Запросить с каждым из ключей - ожидаем 200 и имя клиента в контексте.
+// 4. Запросить /healthz без ключа - ожидаем 200.
+func TestAPIKeyMiddleware(t *testing.T) {
+	keys, err := loadAPIKeys("ci:ci-secret", "")
+	if err != nil {
+		t.Fatalf("failed to load keys: %v", err)
+	}
+	if err := keys.readAPIKeys(strings.NewReader("# резервный клиент\n\nbackup: backup-secret\n")); err != nil {
+		t.Fatalf("failed to read keys: %v", err)
+	}
+	var subject string
+	handler := apiKeyMiddleware(keys, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity, ok := IdentityFromContext(r.Context()); ok {
+			subject = identity.Subject
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name    string
+		path    string
+		key     string
+		status  int
+		subject string
+	}{
+		{"missing key", "/todos", "", http.StatusUnauthorized, ""},
+		{"unknown key", "/todos", "ci-secret2", http.StatusUnauthorized, ""},
+		{"flag key", "/todos", "ci-secret", http.StatusOK, "ci"},
+		{"file key", "/todos", "backup-secret", http.StatusOK, "backup"},
+		{"healthz", "/healthz", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		subject = ""
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status { // неожиданный статус
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, rec.Code)
+		}
+		if subject != tt.subject { // клиент определён неверно
+			t.Errorf("%s: expected subject %q, got %q", tt.name, tt.subject, subject)
+		}
+	}
+}
+
+// Проверка разбора ключей: без ключей API открыт, некорректные и повторяющиеся записи отклоняются
+func TestLoadAPIKeys(t *testing.T) {
+	if keys, err := loadAPIKeys(" , ", ""); err != nil || keys != nil {
+		t.Errorf("expected no keys, got %v (%v)", keys, err)
+	}
+	for _, list := range []string{"no-colon", ":secret", "a:1,a:2"} {
+		if _, err := loadAPIKeys(list, ""); err == nil {
+			t.Errorf("%q: expected error", list)
+		}
+	}
+}
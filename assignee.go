@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// normalizeAssignee Нормализация исполнителя: обрезка пробелов по краям и схлопывание пробелов внутри имени
+func normalizeAssignee(assignee string) string {
+	return strings.Join(strings.Fields(assignee), " ")
+}
+
+// parseAssigneeFilter Разбор параметров ?assignee= и ?unassigned=true (ok = false, если фильтр не задан).
+// Пустой assignee, как и unassigned=true, отбирает задачи без исполнителя
+func parseAssigneeFilter(r *http.Request) (assignee string, ok bool, err error) {
+	unassigned, err := parseBoolQuery(r, "unassigned")
+	if err != nil {
+		return "", false, err
+	}
+	if !r.URL.Query().Has("assignee") {
+		return "", unassigned, nil
+	}
+	assignee = normalizeAssignee(r.URL.Query().Get("assignee"))
+	if unassigned && assignee != "" {
+		return "", false, fmt.Errorf("assignee and unassigned=true cannot be combined")
+	}
+	return assignee, true, nil
+}
+
+// assignedHandler Обработчик эндпоинта /users/{user}/todos: список задач исполнителя
+// (то же, что GET /todos?assignee={user}, включая сортировку и пагинацию)
+func assignedHandler(ts Store) http.HandlerFunc {
+	list := todosHandler(ts)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			logRequest(r, slog.LevelWarn, "[assignedHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		r = r.Clone(r.Context())
+		query := r.URL.Query()
+		query.Set("assignee", r.PathValue("user"))
+		query.Del("unassigned")
+		r.URL.RawQuery = query.Encode()
+		list(w, r)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка назначения задач исполнителям
+// Сценарий:
+// 1. Создать задачи с исполнителями "  Alice   Smith " и "Bob" и задачу без исполнителя.
+// 2. Запросить GET /todos?assignee=Alice%20Smith - ожидаем одну задачу с нормализованным именем.
+// 3. Запросить GET /todos?assignee= и GET /todos?unassigned=true - ожидаем задачу без исполнителя.
+// 4. Запросить GET /users/Bob/todos - ожидаем задачу Bob.
+// 5. Запросить GET /todos?assignee=Bob&unassigned=true - ожидаем 400 Bad Request.
+func TestAssignee(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	for _, assignee := range []string{"  Alice   Smith ", "Bob", ""} {
+		body, _ := json.Marshal(Task{Title: "Task", Status: StatusNotStarted, Assignee: assignee})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	list := func(path string) ([]Task, int) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return tasks, resp.StatusCode
+	}
+	tests := []struct {
+		path     string
+		wantID   int
+		assignee string
+	}{
+		{"/todos?assignee=Alice%20Smith", 1, "Alice Smith"},
+		{"/todos?assignee=", 3, ""},
+		{"/todos?unassigned=true", 3, ""},
+		{"/users/Bob/todos", 2, "Bob"},
+	}
+	for _, tt := range tests {
+		tasks, _ := list(tt.path)
+		if len(tasks) != 1 || tasks[0].ID != tt.wantID || tasks[0].Assignee != tt.assignee { // неверная выборка
+			t.Errorf("%s: expected task %d assigned to %q, got %+v", tt.path, tt.wantID, tt.assignee, tasks)
+		}
+	}
+	// Противоречивые фильтры
+	if _, status := list("/todos?assignee=Bob&unassigned=true"); status != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", status)
+	}
+}
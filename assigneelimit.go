@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrAssigneeLimit Ошибка: у исполнителя уже максимальное число незавершённых задач
+var ErrAssigneeLimit = errors.New("assignee task limit reached")
+
+// AssigneeLimits Ограничение числа незавершённых задач одного исполнителя
+type AssigneeLimits struct {
+	Default     int            // Лимит для всех исполнителей (0 - без ограничения)
+	PerAssignee map[string]int // Лимиты отдельных исполнителей, заменяют Default (0 - без ограничения)
+}
+
+// limit Лимит исполнителя (0 - без ограничения)
+func (l AssigneeLimits) limit(assignee string) int {
+	if limit, ok := l.PerAssignee[assignee]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// parseAssigneeLimits Разбор списка лимитов вида "alice:5,bob:10"
+func parseAssigneeLimits(list string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, ":")
+		name = normalizeAssignee(name)
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || name == "" || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid assignee limit %q: must be name:limit with a non-negative limit", entry)
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// countsTowardLimit Задача занимает место в лимите исполнителя: назначена, не завершена и не в корзине
+func countsTowardLimit(task Task) bool {
+	return task.Assignee != "" && task.Status != StatusCompleted && task.DeletedAt == nil
+}
+
+// indexAssignee Добавляет задачу в индекс задач по исполнителю, если она занимает место в его лимите
+// (вызывается под блокировкой после каждого изменения исполнителя, статуса или удаления задачи)
+func (ds *TaskStore) indexAssignee(task Task) {
+	if !countsTowardLimit(task) {
+		return
+	}
+	ids, ok := ds.byAssignee[task.Assignee]
+	if !ok {
+		ids = make(map[int]struct{})
+		ds.byAssignee[task.Assignee] = ids
+	}
+	ids[task.ID] = struct{}{}
+}
+
+// unindexAssignee Удаляет задачу из индекса задач по исполнителю (вызывается под блокировкой)
+func (ds *TaskStore) unindexAssignee(task Task) {
+	ids, ok := ds.byAssignee[task.Assignee]
+	if !ok {
+		return
+	}
+	delete(ids, task.ID)
+	if len(ids) == 0 {
+		delete(ds.byAssignee, task.Assignee)
+	}
+}
+
+// checkAssigneeLimit Проверяет, что исполнителю можно добавить added незавершённых задач (вызывается под блокировкой)
+func (ds *TaskStore) checkAssigneeLimit(assignee string, added int) error {
+	limit := ds.config.AssigneeLimits.limit(assignee)
+	if assignee == "" || limit <= 0 || added <= 0 {
+		return nil
+	}
+	active := len(ds.byAssignee[assignee])
+	if active+added > limit {
+		return fmt.Errorf("%w: %q has %d of %d active tasks", ErrAssigneeLimit, assignee, active, limit)
+	}
+	return nil
+}
+
+// checkAssigneeSlot Проверяет лимит исполнителя, если после изменения задача начинает занимать в нём место:
+// её назначают другому исполнителю, восстанавливают из корзины или возвращают к прежней версии (вызывается под блокировкой)
+func (ds *TaskStore) checkAssigneeSlot(before, after Task) error {
+	if !countsTowardLimit(after) || (countsTowardLimit(before) && before.Assignee == after.Assignee) {
+		return nil
+	}
+	return ds.checkAssigneeLimit(after.Assignee, 1)
+}
+
+// checkReopenedAncestors Проверяет лимиты исполнителей завершённых предков, которых переоткроет незавершённая
+// подзадача при выводе статуса из подзадач (вызывается под блокировкой)
+func (ds *TaskStore) checkReopenedAncestors(parentID *int, status TaskStatus) error {
+	if !ds.config.InferParentStatus || status == StatusCompleted {
+		return nil
+	}
+	added := make(map[string]int)
+	// у незавершённого предка все предки выше тоже не завершены, поэтому подъём на нём заканчивается
+	for id, steps := parentID, 0; id != nil && steps < len(ds.tasks); steps++ {
+		parent, ok := ds.liveTask(*id)
+		if !ok || parent.Status != StatusCompleted {
+			break
+		}
+		if parent.Assignee != "" {
+			added[parent.Assignee]++
+			if err := ds.checkAssigneeLimit(parent.Assignee, added[parent.Assignee]); err != nil {
+				return fmt.Errorf("reopening task %d: %w", parent.ID, err)
+			}
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// Проверка лимита незавершённых задач на исполнителя
+// Сценарий:
+// 1. Задать общий лимит 2 и лимит 1 для Bob.
+// 2. Создать две задачи Alice - ожидаем 201, третью - ожидаем 409 Conflict.
+// 3. Завершить задачу Alice через PUT - ожидаем 200, после чего новая задача Alice создаётся (201).
+// 4. Создать задачу Bob (201) и передать ему задачу Alice - ожидаем 409 Conflict.
+// 5. Создать пакет из задачи Carol и задачи Bob - ожидаем 409 Conflict с index 1 и ни одной новой задачи.
+// 6. Удалить задачу Bob - ожидаем, что место освободилось и задача Alice передаётся Bob (200).
+func TestAssigneeLimit(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.AssigneeLimits = AssigneeLimits{Default: 2, PerAssignee: map[string]int{"Bob": 1}}
+	ds := NewTaskStoreWithConfig(config)
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	send := func(method, path string, v any) (int, ErrorResponse) {
+		body, _ := json.Marshal(v)
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		var errResp ErrorResponse
+		if resp.StatusCode >= http.StatusBadRequest {
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode, errResp
+	}
+	task := func(assignee string) Task {
+		return Task{Title: "Task", Status: StatusInProgress, Assignee: assignee}
+	}
+
+	for i, want := range []int{http.StatusCreated, http.StatusCreated, http.StatusConflict} { // задачи 1 и 2
+		if status, _ := send(http.MethodPost, "/todos", task("Alice")); status != want {
+			t.Fatalf("task %d for Alice: expected %d, got %d", i+1, want, status)
+		}
+	}
+	completed := task("Alice")
+	completed.Status = StatusCompleted
+	if status, _ := send(http.MethodPut, "/todos/1", completed); status != http.StatusOK { // получили НЕ 200
+		t.Fatalf("completing task: expected 200, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/todos", task("Alice")); status != http.StatusCreated { // задача 3: место НЕ освободилось
+		t.Fatalf("after completion: expected 201, got %d", status)
+	}
+	if status, _ := send(http.MethodPost, "/todos", task("Bob")); status != http.StatusCreated { // задача 4
+		t.Fatalf("task for Bob: expected 201, got %d", status)
+	}
+	if status, _ := send(http.MethodPut, "/todos/2", task("Bob")); status != http.StatusConflict { // передача сверх лимита
+		t.Fatalf("reassigning to Bob: expected 409, got %d", status)
+	}
+	status, errResp := send(http.MethodPost, "/todos", []Task{task("Carol"), task("Bob")})
+	if status != http.StatusConflict || errResp.Index == nil || *errResp.Index != 1 { // неверная ошибка пакета
+		t.Fatalf("batch: expected 409 with index 1, got %d %+v", status, errResp)
+	}
+	if _, err := ds.GetTask(5); err == nil { // часть пакета сохранилась
+		t.Fatal("expected rejected batch to create no tasks")
+	}
+	if status, _ := send(http.MethodDelete, "/todos/4", nil); status != http.StatusNoContent { // получили НЕ 204
+		t.Fatalf("deleting Bob's task: expected 204, got %d", status)
+	}
+	if status, _ := send(http.MethodPut, "/todos/2", task("Bob")); status != http.StatusOK { // место НЕ освободилось
+		t.Fatalf("reassigning after delete: expected 200, got %d", status)
+	}
+}
+
+// Проверка разбора лимитов исполнителей
+// Сценарий:
+// 1. Разобрать корректный список - ожидаем нормализованные имена и значения.
+// 2. Разобрать списки без лимита, с отрицательным лимитом и без имени - ожидаем ошибку.
+func TestParseAssigneeLimits(t *testing.T) {
+	limits, err := parseAssigneeLimits(" Alice  Smith :5, bob:0,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(limits) != 2 || limits["Alice Smith"] != 5 || limits["bob"] != 0 { // неверный разбор
+		t.Fatalf("unexpected limits: %v", limits)
+	}
+	for _, list := range []string{"alice", "alice:-1", ":3", "alice:x"} {
+		if _, err := parseAssigneeLimits(list); err == nil { // ошибка НЕ обнаружена
+			t.Errorf("%q: expected error", list)
+		}
+	}
+}
+
+// Проверка лимита исполнителя на путях, которые возвращают задачу в его лимит
+// Сценарий:
+// 1. Задать лимит 1, создать задачу alice и удалить её в корзину - ожидаем, что индекс её не учитывает.
+// 2. Создать вторую задачу alice и восстановить первую - ожидаем ErrAssigneeLimit.
+// 3. Передать вторую задачу carol, создать третью задачу alice и отменить передачу - ожидаем ErrAssigneeLimit.
+// 4. С выводом статуса завершить подзадачу родителя alice, создать задачу alice и добавить родителю
+// незавершённую подзадачу - ожидаем ErrAssigneeLimit: она переоткрыла бы родителя.
+func TestAssigneeLimitRestoreUndoReopen(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.AssigneeLimits = AssigneeLimits{Default: 1}
+	ds := NewTaskStoreWithConfig(config)
+	add := func(ds *TaskStore, task Task) {
+		if _, err := ds.AddTask(task); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+
+	add(ds, Task{Title: "First", Status: StatusInProgress, Assignee: "alice"})
+	if err := ds.DeleteTask(1, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if n := len(ds.byAssignee["alice"]); n != 0 { // задача из корзины осталась в индексе
+		t.Errorf("expected no indexed tasks of alice, got %d", n)
+	}
+	add(ds, Task{Title: "Second", Status: StatusInProgress, Assignee: "alice"})
+	if _, err := ds.RestoreTask(1); !errors.Is(err, ErrAssigneeLimit) {
+		t.Errorf("expected ErrAssigneeLimit on restore, got %v", err)
+	}
+	// Отмена передачи задачи другому исполнителю
+	if _, err := ds.UpdateTask(2, Task{Title: "Second", Status: StatusInProgress, Assignee: "carol"}); err != nil {
+		t.Fatalf("failed to reassign task: %v", err)
+	}
+	add(ds, Task{Title: "Third", Status: StatusInProgress, Assignee: "alice"})
+	if _, err := ds.UndoTask(2); !errors.Is(err, ErrAssigneeLimit) {
+		t.Errorf("expected ErrAssigneeLimit on undo, got %v", err)
+	}
+
+	// Переоткрытие завершённого родителя при выводе статуса
+	config.InferParentStatus = true
+	ds = NewTaskStoreWithConfig(config)
+	parentID := 1
+	add(ds, Task{Title: "Parent", Status: StatusInProgress, Assignee: "alice"})
+	add(ds, Task{Title: "Child", Status: StatusInProgress, ParentID: &parentID})
+	if _, err := ds.UpdateTask(2, Task{Title: "Child", Status: StatusCompleted, ParentID: &parentID}); err != nil {
+		t.Fatalf("failed to complete subtask: %v", err)
+	}
+	add(ds, Task{Title: "Other", Status: StatusInProgress, Assignee: "alice"})
+	if _, err := ds.AddTask(Task{Title: "Late", Status: StatusNotStarted, ParentID: &parentID}); !errors.Is(err, ErrAssigneeLimit) {
+		t.Errorf("expected ErrAssigneeLimit when reopening the parent, got %v", err)
+	}
+	if parent, _ := ds.GetTask(1); parent.Status != StatusCompleted { // родитель переоткрыт в обход лимита
+		t.Errorf("expected parent to stay completed, got %q", parent.Status)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMaxTrackedAssignees Число исполнителей, для которых по умолчанию ведётся статистика создания задач
+const defaultMaxTrackedAssignees = 1000
+
+// rateBucket Ячейка кольцевого буфера: номер минуты или часа (Unix) и число задач, созданных за это время
+type rateBucket struct {
+	slot  int64
+	count int64
+}
+
+// assigneeRate Кольцевые буферы созданных задач исполнителя: по минутам за последний час и по часам за последние сутки
+type assigneeRate struct {
+	minutes  [60]rateBucket
+	hours    [24]rateBucket
+	lastSeen time.Time
+}
+
+// add Учёт созданной задачи
+func (r *assigneeRate) add(at time.Time) {
+	for _, ring := range []struct {
+		buckets []rateBucket
+		slot    int64
+	}{
+		{r.minutes[:], at.Unix() / 60},
+		{r.hours[:], at.Unix() / 3600},
+	} {
+		b := &ring.buckets[ring.slot%int64(len(ring.buckets))]
+		if b.slot != ring.slot { // ячейка осталась от прошлого круга
+			*b = rateBucket{slot: ring.slot}
+		}
+		b.count++
+	}
+	r.lastSeen = at
+}
+
+// sum Число задач в ячейках, попадающих в последние len(buckets) слотов до now включительно
+func sum(buckets []rateBucket, now int64) int64 {
+	var total int64
+	for _, b := range buckets {
+		if b.slot > now-int64(len(buckets)) && b.slot <= now {
+			total += b.count
+		}
+	}
+	return total
+}
+
+// AssigneeRate Число задач исполнителя, созданных за последний час и за последние сутки (с точностью до часа)
+type AssigneeRate struct {
+	LastHour int64 `json:"last_hour"`
+	LastDay  int64 `json:"last_day"`
+}
+
+// assigneeRates Статистика создания задач по исполнителям. Память ограничена: при превышении limit
+// вытесняется исполнитель, дольше всех не получавший задач
+type assigneeRates struct {
+	mutex sync.Mutex
+	limit int // максимум отслеживаемых исполнителей (0 - не отслеживать)
+	rates map[string]*assigneeRate
+}
+
+// record Учёт задачи, созданной для исполнителя в момент at
+func (a *assigneeRates) record(assignee string, at time.Time) {
+	if assignee == "" || a.limit <= 0 {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.rates == nil {
+		a.rates = make(map[string]*assigneeRate)
+	}
+	rate, ok := a.rates[assignee]
+	if !ok {
+		if len(a.rates) >= a.limit {
+			a.evictOldest()
+		}
+		rate = new(assigneeRate)
+		a.rates[assignee] = rate
+	}
+	rate.add(at)
+}
+
+// evictOldest Вытесняет исполнителя, дольше всех не получавшего задач (вызывается под блокировкой)
+func (a *assigneeRates) evictOldest() {
+	var oldest string
+	var oldestSeen time.Time
+	for assignee, rate := range a.rates {
+		if oldest == "" || rate.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = assignee, rate.lastSeen
+		}
+	}
+	delete(a.rates, oldest)
+}
+
+// snapshot Число созданных задач по исполнителям на момент now (исполнители без задач за сутки пропускаются)
+func (a *assigneeRates) snapshot(now time.Time) map[string]AssigneeRate {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	snapshot := make(map[string]AssigneeRate, len(a.rates))
+	for assignee, rate := range a.rates {
+		r := AssigneeRate{LastHour: sum(rate.minutes[:], now.Unix()/60), LastDay: sum(rate.hours[:], now.Unix()/3600)}
+		if r.LastDay > 0 {
+			snapshot[assignee] = r
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultHistoryLimit Сколько последних записей журнала хранится в памяти для каждой задачи по умолчанию
+const defaultHistoryLimit = 100
+
+// AuditEntry Запись журнала изменений задачи
+type AuditEntry struct {
+	Time   time.Time     `json:"time"`
+	Tenant string        `json:"tenant,omitempty"` // Тенант хранилища (пусто - общее пространство)
+	TaskID int           `json:"task_id"`
+	Seq    int           `json:"seq"` // Номер записи в журнале задачи, начиная с 1
+	Action TaskEventType `json:"action"`
+	Before *Task         `json:"before,omitempty"` // Задача до изменения (nil при создании)
+	After  *Task         `json:"after,omitempty"`  // Задача после изменения (nil при вытеснении из хранилища)
+}
+
+// snapshot Копия задачи для журнала (без номера в статусе, он вычисляется при чтении)
+func snapshot(task *Task) *Task {
+	if task == nil {
+		return nil
+	}
+	copied := *task
+	copied.StatusNumber = 0
+	return &copied
+}
+
+// record Записывает изменение задачи в журнал и рассылает событие подписчикам
+// (вызывается под блокировкой, поэтому порядок записей совпадает с порядком изменений)
+func (ds *TaskStore) record(action TaskEventType, before, after *Task) {
+	entry := AuditEntry{
+		Time:   time.Now().UTC(),
+		Tenant: ds.tenant,
+		Action: action,
+		Before: snapshot(before),
+		After:  snapshot(after),
+	}
+	event := entry.After
+	if event == nil {
+		event = entry.Before
+	}
+	entry.TaskID = event.ID
+	entries := ds.history[entry.TaskID]
+	entry.Seq = 1
+	if len(entries) > 0 { // номера продолжаются и после вытеснения старых записей
+		entry.Seq = entries[len(entries)-1].Seq + 1
+	}
+	entries = append(entries, entry)
+	if limit := ds.config.HistoryLimit; limit > 0 && len(entries) > limit { // старые записи остаются только в файле журнала
+		entries = entries[len(entries)-limit:]
+	}
+	ds.history[entry.TaskID] = entries
+	if action == TaskEventUpdated { // отменить можно только изменение, не создание, удаление или саму отмену
+		ds.pushUndo(*entry.Before)
+	}
+	if ds.config.AuditLog != nil {
+		line, err := json.Marshal(entry)
+		if err == nil {
+			_, err = ds.config.AuditLog.Write(append(line, '\n'))
+		}
+		if err != nil {
+			slog.Warn("[record] Writing audit log", "task_id", entry.TaskID, "error", err)
+		}
+	}
+	if after != nil {
+		ds.publish(action, *after)
+	} else {
+		ds.publish(action, *before)
+	}
+	ds.inferStatuses(before, after)
+}
+
+// History Возвращает журнал изменений задачи по ID в хронологическом порядке (в том числе для удалённой задачи).
+// В памяти хранятся последние HistoryLimit записей, поэтому seq первой записи может быть больше 1
+func (ds *TaskStore) History(id int) ([]AuditEntry, error) {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+	entries, ok := ds.history[id]
+	if !ok {
+		return nil, fmt.Errorf("no history for task with id %d", id)
+	}
+	return append([]AuditEntry(nil), entries...), nil
+}
+
+// historyHandler Обработчик эндпоинта /todos/{id}/history
+func historyHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[historyHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[historyHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		entries, err := ts.History(id)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[historyHandler] Getting history", err, "task_id", id)
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		var body any = entries
+		if fields := requestedFields(r); fields != nil { // маска X-Fields применяется к состояниям задачи
+			if body, err = maskHistory(entries, fields); err != nil {
+				logRequest(r, slog.LevelError, "[historyHandler] Masking history", err, "task_id", id)
+				writeJSONError(w, http.StatusInternalServerError, "failed to encode history")
+				return
+			}
+		}
+		w.Header().Add("Vary", "X-Fields")
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			logRequest(r, slog.LevelError, "[historyHandler] Encoding history", err, "task_id", id)
+			return
+		}
+	}
+}
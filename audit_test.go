@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Проверка журнала изменений задач
+// Сценарий:
+// 1. Создать, обновить и удалить задачу в хранилище с журналом в буфере.
+// 2. Запросить GET /todos/1/history - ожидаем записи created, updated, deleted с состояниями до и после.
+// 3. Проверить буфер - ожидаем три строки JSON.
+// 4. Запросить историю несуществующей задачи - ожидаем 404 Not Found.
+func TestAuditHistory(t *testing.T) {
+	var auditLog bytes.Buffer
+	config := DefaultStoreConfig()
+	config.AuditLog = &auditLog
+	ds := NewTaskStoreWithConfig(config)
+	if _, err := ds.AddTask(Task{Title: "Draft", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	if _, err := ds.UpdateTask(1, Task{Title: "Final", Status: StatusInProgress}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if err := ds.DeleteTask(1, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	// История задачи
+	resp, err := http.Get(ts.URL + "/todos/1/history")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var entries []AuditEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	for i, action := range []TaskEventType{TaskEventCreated, TaskEventUpdated, TaskEventDeleted} {
+		if entries[i].Action != action || entries[i].TaskID != 1 { // порядок записей НЕ совпадает с изменениями
+			t.Errorf("entry %d: expected %s of task 1, got %s of task %d", i, action, entries[i].Action, entries[i].TaskID)
+		}
+	}
+	if entries[0].Before != nil || entries[0].After == nil { // у создания есть состояние "до"
+		t.Errorf("expected only after snapshot on create, got %+v", entries[0])
+	}
+	if update := entries[1]; update.Before == nil || update.After == nil ||
+		update.Before.Title != "Draft" || update.After.Title != "Final" { // состояния до и после НЕ сохранены
+		t.Errorf("expected Draft -> Final on update, got %+v", update)
+	}
+	if entries[2].After == nil || entries[2].After.DeletedAt == nil { // удаление НЕ отражено
+		t.Errorf("expected deleted_at in after snapshot, got %+v", entries[2].After)
+	}
+	// Журнал в файле
+	if lines := strings.Count(auditLog.String(), "\n"); lines != 3 {
+		t.Errorf("expected 3 audit log lines, got %d", lines)
+	}
+	// Несуществующая задача
+	resp, err = http.Get(ts.URL + "/todos/99/history")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка различий задачи между записями журнала
+// Сценарий:
+// 1. Создать задачу, изменить её статус, затем заголовок и срок.
+// 2. Запросить GET /todos/1/diff?from=1&to=3 - ожидаем изменения status, title, due_at, updated_at и version,
+// у due_at нет значения from (поле не было задано).
+// 3. Запросить diff с несуществующей записью и для несуществующей задачи - ожидаем 404, с нечисловым from - 400.
+func TestTaskDiff(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	task, err := ds.AddTask(Task{Title: "Draft", Status: StatusNotStarted})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	task.Status = StatusInProgress
+	if task, err = ds.UpdateTask(1, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	due := task.CreatedAt.Add(24 * time.Hour)
+	task.Title, task.DueAt = "Final", &due
+	if _, err = ds.UpdateTask(1, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+
+	get := func(path string) (TaskDiff, int) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var diff TaskDiff
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&diff); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return diff, resp.StatusCode
+	}
+	diff, status := get("/todos/1/diff?from=1&to=3")
+	if status != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", status)
+	}
+	changes := make(map[string]FieldChange)
+	var fields []string
+	for _, change := range diff.Changes {
+		changes[change.Field] = change
+		fields = append(fields, change.Field)
+	}
+	if strings.Join(fields, ",") != "due_at,status,title,updated_at,version" { // неверный набор полей
+		t.Fatalf("unexpected changed fields %v", fields)
+	}
+	if string(changes["status"].From) != `"not started"` || string(changes["status"].To) != `"in progress"` { // неверные значения
+		t.Errorf("unexpected status change %s -> %s", changes["status"].From, changes["status"].To)
+	}
+	if changes["due_at"].From != nil || changes["due_at"].To == nil { // due_at НЕ добавлен
+		t.Errorf("expected due_at to be added, got %+v", changes["due_at"])
+	}
+	for path, want := range map[string]int{
+		"/todos/1/diff?from=1&to=4": http.StatusNotFound,
+		"/todos/9/diff?from=1&to=1": http.StatusNotFound,
+		"/todos/1/diff?from=x&to=1": http.StatusBadRequest,
+	} {
+		if _, status := get(path); status != want {
+			t.Errorf("%s: expected %d, got %d", path, want, status)
+		}
+	}
+}
+
+// Проверка маски X-Fields для журнала и различий задачи
+// Сценарий:
+// 1. Создать задачу и изменить её статус и заголовок.
+// 2. Запросить GET /todos/1/history с X-Fields: id,status - ожидаем в before и after только id и status,
+// поля самой записи (seq, action) сохранены, Vary содержит X-Fields.
+// 3. Запросить GET /todos/1/diff?from=1&to=2 с X-Fields: title - ожидаем только изменение title.
+func TestAuditHistoryFields(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	task, err := ds.AddTask(Task{Title: "Draft", Status: StatusNotStarted})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	task.Title, task.Status = "Final", StatusInProgress
+	if _, err := ds.UpdateTask(1, task); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	get := func(path, fields string, v any) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("X-Fields", fields)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp
+	}
+
+	var entries []map[string]json.RawMessage
+	resp := get("/todos/1/history", "id,status", &entries)
+	if vary := strings.Join(resp.Header.Values("Vary"), ", "); !strings.Contains(vary, "X-Fields") { // кэш НЕ различает маски
+		t.Errorf("expected Vary to include X-Fields, got %q", vary)
+	}
+	if len(entries) != 2 || string(entries[1]["seq"]) != "2" || string(entries[1]["action"]) != `"updated"` { // поля записи потеряны
+		t.Fatalf("unexpected history %v", entries)
+	}
+	for _, key := range []string{"before", "after"} {
+		var state map[string]any
+		if err := json.Unmarshal(entries[1][key], &state); err != nil {
+			t.Fatalf("failed to decode %s: %v", key, err)
+		}
+		if len(state) != 2 || state["id"] == nil || state["status"] == nil { // маска НЕ применена
+			t.Errorf("%s: expected only id and status, got %v", key, state)
+		}
+	}
+
+	var diff TaskDiff
+	get("/todos/1/diff?from=1&to=2", "title", &diff)
+	if len(diff.Changes) != 1 || diff.Changes[0].Field != "title" { // маска НЕ применена к различиям
+		t.Errorf("expected only title change, got %+v", diff.Changes)
+	}
+}
+
+// Проверка ограничения журнала задачи в памяти
+// Сценарий:
+// 1. С лимитом в 3 записи создать задачу и изменить её 5 раз - ожидаем 3 последние записи с seq 4, 5 и 6.
+// 2. Запросить различия с вытесненной записью - ожидаем ошибку, с оставшимися - успех.
+func TestHistoryLimit(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.HistoryLimit = 3
+	ds := NewTaskStoreWithConfig(config)
+	if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	for i := range 5 {
+		if _, err := ds.UpdateTask(1, Task{Title: fmt.Sprintf("Edit %d", i), Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to update task: %v", err)
+		}
+	}
+	entries, err := ds.History(1)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	var seqs []int
+	for _, entry := range entries {
+		seqs = append(seqs, entry.Seq)
+	}
+	if !slices.Equal(seqs, []int{4, 5, 6}) { // журнал НЕ ограничен или нумерация сбилась
+		t.Errorf("expected seqs [4 5 6], got %v", seqs)
+	}
+	if _, err := ds.Diff(1, 1, 6); err == nil { // вытесненная запись доступна
+		t.Error("expected an error for an evicted entry")
+	}
+	diff, err := ds.Diff(1, 4, 6)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	if len(diff.Changes) == 0 || diff.Changes[0].Field != "title" || string(diff.Changes[0].To) != `"Edit 4"` { // различия НЕ те
+		t.Errorf("expected a title change to Edit 4, got %+v", diff.Changes)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxBatchIDs Максимальное число ID в одном запросе ?ids=
+const maxBatchIDs = 100
+
+// parseIDs Разбор параметра ?ids=1,2,3 без повторов, в порядке первого упоминания (ok = false, если параметр не передан)
+func parseIDs(r *http.Request) (ids []int, ok bool, err error) {
+	if !r.URL.Query().Has("ids") {
+		return nil, false, nil
+	}
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || id <= 0 {
+			return nil, false, fmt.Errorf("ids must be a comma-separated list of positive integers")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) > maxBatchIDs {
+		return nil, false, fmt.Errorf("ids must contain at most %d ids", maxBatchIDs)
+	}
+	return ids, true, nil
+}
+
+// GetTasks Задачи с заданными ID (кроме удалённых) и ID, которых в хранилище нет
+func (ds *TaskStore) GetTasks(ids []int) (tasks []Task, missing []int) {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+	tasks = make([]Task, 0, len(ids))
+	for _, id := range ids {
+		if task, ok := ds.liveTask(id); ok {
+			tasks = append(tasks, task)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	ds.numberTasks(tasks)
+	return tasks, missing
+}
+
+// formatIDs Список ID через запятую (для заголовка X-Missing-IDs)
+func formatIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
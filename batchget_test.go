@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Проверка получения задач по списку ID
+// Сценарий:
+// 1. Создать 3 задачи и удалить задачу 2.
+// 2. Запросить ?ids=3,1,2,99,1 - ожидаем задачи 1 и 3 (без повторов) и X-Missing-IDs: 2,99.
+// 3. Запросить ?ids= с нечисловым ID и со слишком длинным списком - ожидаем ошибку (400 Bad Request).
+func TestBatchGet(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	// Создаём задачи
+	for range 3 {
+		if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	if err := ds.DeleteTask(2, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	// Запрашиваем задачи по списку
+	resp, err := http.Get(ts.URL + "/todos?ids=3,1,2,99,1")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var tasks []Task
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != 1 || tasks[1].ID != 3 { // задачи НЕ те
+		t.Errorf("expected tasks 1 and 3, got %+v", tasks)
+	}
+	if got := resp.Header.Get("X-Missing-IDs"); got != "2,99" { // отсутствующие ID НЕ перечислены
+		t.Errorf("expected X-Missing-IDs 2,99, got %q", got)
+	}
+
+	// Некорректные списки
+	many := make([]string, maxBatchIDs+1)
+	for i := range many {
+		many[i] = strconv.Itoa(i + 1)
+	}
+	for _, query := range []string{"?ids=1,x", "?ids=1,,2", "?ids=" + strings.Join(many, ",")} {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+			t.Errorf("query %.20q: expected 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
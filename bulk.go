@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// BulkError Ошибка пакетной операции с индексом элемента, на котором она произошла
+type BulkError struct {
+	Index int   // Индекс элемента в пакете
+	Err   error // Исходная ошибка
+}
+
+// Error Текст ошибки с индексом элемента
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap Исходная ошибка (для errors.Is)
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
+
+// isJSONArray Проверяет, что тело запроса - JSON-массив (первый значащий символ '[')
+func isJSONArray(body []byte) bool {
+	body = bytes.TrimLeft(body, " \t\r\n")
+	return len(body) > 0 && body[0] == '['
+}
+
+// AddTasks Атомарно добавляет пакет задач с автоматически назначенными ID: либо все, либо ни одной
+func (ds *TaskStore) AddTasks(tasks []Task) ([]Task, error) {
+	ds.mutex.Lock()
+	// сначала проверяем весь пакет, чтобы при ошибке не пришлось откатывать вставку
+	seen := make(map[string]int)
+	for i, task := range tasks {
+		if err := ds.checkExternalID(task.ExternalID, 0); err != nil { // внешний ID уже занят в хранилище
+			ds.mutex.Unlock()
+			slog.Warn("[AddTasks] Rejecting batch", "index", i, "error", err)
+			return nil, &BulkError{Index: i, Err: err}
+		}
+		if err := ds.checkParent(task.ParentID, 0, task.Status); err != nil { // родительской задачи нет в хранилище
+			ds.mutex.Unlock()
+			slog.Warn("[AddTasks] Rejecting batch", "index", i, "error", err)
+			return nil, &BulkError{Index: i, Err: err}
+		}
+		if task.ExternalID == "" || !ds.config.UniqueExternalIDs {
+			continue
+		}
+		if first, ok := seen[task.ExternalID]; ok { // внешний ID повторяется внутри пакета
+			ds.mutex.Unlock()
+			err := fmt.Errorf("%w: %q (duplicates item %d)", ErrExternalIDConflict, task.ExternalID, first)
+			slog.Warn("[AddTasks] Rejecting batch", "index", i, "error", err)
+			return nil, &BulkError{Index: i, Err: err}
+		}
+		seen[task.ExternalID] = i
+	}
+	added := make(map[string]int) // незавершённые задачи пакета по исполнителям
+	for i, task := range tasks {
+		if !countsTowardLimit(task) {
+			continue
+		}
+		added[task.Assignee]++
+		if err := ds.checkAssigneeLimit(task.Assignee, added[task.Assignee]); err != nil { // пакет не помещается в лимит
+			ds.mutex.Unlock()
+			slog.Warn("[AddTasks] Rejecting batch", "index", i, "error", err)
+			return nil, &BulkError{Index: i, Err: err}
+		}
+	}
+	if err := ds.makeRoom(len(tasks)); err != nil { // пакет не помещается в хранилище
+		ds.mutex.Unlock()
+		slog.Warn("[AddTasks] Rejecting batch", "error", err)
+		return nil, err
+	}
+	created := make([]Task, len(tasks))
+	for i, task := range tasks {
+		task.ID = ds.newID()
+		created[i] = ds.insertTask(task)
+	}
+	ds.numberTasks(created)
+	for _, task := range created {
+		ds.record(TaskEventCreated, nil, &task)
+	}
+	ds.mutex.Unlock()
+	for _, task := range created {
+		ds.config.Stats.TaskCreated(task.Assignee)
+	}
+	return created, nil
+}
+
+// createTasksBulk Обработка POST /todos с JSON-массивом задач в теле
+func createTasksBulk(w http.ResponseWriter, r *http.Request, ts Store, body []byte) {
+	var tasks []Task
+	if err := json.Unmarshal(body, &tasks); err != nil {
+		logRequest(r, slog.LevelWarn, "[todosHandler] Decoding batch", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(tasks) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "batch must not be empty")
+		return
+	}
+	validation := validationConfig(ts)
+	for i := range tasks {
+		tasks[i].ID = 0 // ID назначает хранилище, присланный клиентом игнорируется
+		tasks[i].Preprocess()
+		if err := tasks[i].Validate(validation); err != nil {
+			err = &BulkError{Index: i, Err: err}
+			logRequest(r, slog.LevelWarn, "[todosHandler] Batch validation", err)
+			writeBulkError(w, err)
+			return
+		}
+	}
+	created, err := ts.AddTasks(tasks)
+	if err != nil {
+		logRequest(r, slog.LevelWarn, "[todosHandler] Creating batch", err)
+		if errors.Is(err, ErrStoreFull) {
+			writeJSONError(w, http.StatusInsufficientStorage, err.Error())
+			return
+		}
+		writeBulkError(w, err)
+		return
+	}
+	if err := writeTaskJSON(w, r, http.StatusCreated, created); err != nil {
+		logRequest(r, slog.LevelError, "[todosHandler] Encoding batch", err)
+		return
+	}
+}
+
+// writeBulkError Ответ 400 с индексом элемента пакета, на котором произошла ошибка
+// (и всеми проблемами валидации этого элемента)
+func writeBulkError(w http.ResponseWriter, err error) {
+	response := ErrorResponse{Error: err.Error(), Status: http.StatusBadRequest}
+	if errors.Is(err, ErrAssigneeLimit) {
+		response.Status = http.StatusConflict
+	}
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		response.Index = &bulkErr.Index
+	}
+	var problems ValidationErrors
+	if errors.As(err, &problems) {
+		response.Errors = problems
+	}
+	writeErrorResponse(w, response)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка пакетного создания задач
+// Сценарий:
+// 1. Отправить массив из двух задач - ожидаем успех (201 Created) и задачи с ID 1 и 2 в теле ответа.
+// 2. Отправить массив, где вторая задача без заголовка - ожидаем ошибку (400 Bad Request) с index 1.
+// 3. Отправить массив с повторяющимся external_id - ожидаем ошибку (400 Bad Request) с index 1.
+// 4. Получить список задач - ожидаем, что неудачные пакеты не добавили ни одной задачи.
+func TestBulkCreate(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Успешный пакет
+	body, _ := json.Marshal([]Task{{Title: "First", Status: StatusNotStarted}, {Title: "Second", Status: StatusInProgress}})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	var created []Task
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if len(created) != 2 || created[0].ID != 1 || created[1].ID != 2 { // ID назначены НЕ по порядку
+		t.Fatalf("expected tasks with ids 1 and 2, got %+v", created)
+	}
+	// Неудачные пакеты
+	for _, batch := range [][]Task{
+		{{Title: "Ok", Status: StatusNotStarted}, {Title: " ", Status: StatusNotStarted}},
+		{{Title: "A", Status: StatusNotStarted, ExternalID: "EXT"}, {Title: "B", Status: StatusNotStarted, ExternalID: "EXT"}},
+	} {
+		body, _ := json.Marshal(batch)
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var errResp ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+			t.Errorf("expected 400, got %d", resp.StatusCode)
+		}
+		if errResp.Index == nil || *errResp.Index != 1 { // индекс ошибочного элемента НЕ передан
+			t.Errorf("expected index 1, got %v (%s)", errResp.Index, errResp.Error)
+		}
+	}
+	// Проверяем, что неудачные пакеты откатились целиком
+	resp, err = http.Get(ts.URL + "/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var list []Task
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(list) != 2 { // часть неудачного пакета попала в хранилище
+		t.Errorf("expected 2 tasks, got %d", len(list))
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// bulkDeleteRequest Тело DELETE /todos с явным списком ID
+type bulkDeleteRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// bulkDeleteResponse Ответ на DELETE /todos
+type bulkDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// DeleteTasks Атомарно удаляет в корзину задачи с заданными ID: либо все, либо ни одной
+func (ds *TaskStore) DeleteTasks(ids []int) (int, error) {
+	ds.mutex.Lock()
+	set := make(map[int]Task, len(ids))
+	for _, id := range ids {
+		task, ok := ds.liveTask(id)
+		if !ok { // задача с таким ID не найдена
+			ds.mutex.Unlock()
+			err := fmt.Errorf("task with id %d not found", id)
+			slog.Warn("[DeleteTasks] Rejecting delete", "task_id", id, "error", err)
+			return 0, err
+		}
+		set[id] = task
+	}
+	return ds.deleteSet(set)
+}
+
+// DeleteMatching Атомарно удаляет в корзину все задачи, подходящие под условие
+func (ds *TaskStore) DeleteMatching(match func(Task) bool) (int, error) {
+	ds.mutex.Lock()
+	set := make(map[int]Task)
+	for id, task := range ds.tasks {
+		if task.DeletedAt == nil && match(task) {
+			set[id] = task
+		}
+	}
+	return ds.deleteSet(set)
+}
+
+// deleteSet Удаляет набор задач, если ни у одной не останется подзадач вне набора (вызывается под блокировкой,
+// снимает её)
+func (ds *TaskStore) deleteSet(set map[int]Task) (int, error) {
+	for id := range set {
+		for _, child := range ds.children(id) {
+			if _, ok := set[child.ID]; !ok { // подзадача осталась бы без родителя
+				ds.mutex.Unlock()
+				err := fmt.Errorf("%w: task %d has subtask %d that is not being deleted", ErrHasSubtasks, id, child.ID)
+				slog.Warn("[deleteSet] Rejecting delete", "task_id", id, "error", err)
+				return 0, err
+			}
+		}
+	}
+	now := time.Now().UTC()
+	for _, task := range set {
+		ds.softDelete(task, now)
+	}
+	ds.mutex.Unlock()
+	for range set {
+		ds.config.Stats.TaskDeleted()
+	}
+	return len(set), nil
+}
+
+// deleteTasksBulk Обработка DELETE /todos: по списку ID в теле, по фильтру ?status= или всех задач с ?all=true
+func deleteTasksBulk(w http.ResponseWriter, r *http.Request, ts Store) {
+	status, filterStatus, err := parseStatusFilter(r)
+	if err != nil {
+		logRequest(r, slog.LevelWarn, "[todosHandler] Invalid status filter", err)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	all, err := parseBoolQuery(r, "all")
+	if err != nil {
+		logRequest(r, slog.LevelWarn, "[todosHandler] Invalid all", err)
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logRequest(r, slog.LevelWarn, "[todosHandler] Reading body", err)
+		writeDecodeError(w, err)
+		return
+	}
+	var deleted int
+	switch {
+	case len(bytes.TrimSpace(body)) > 0: // DELETE /todos с {"ids":[...]}
+		var req bulkDeleteRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			logRequest(r, slog.LevelWarn, "[todosHandler] Decoding", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if len(req.IDs) == 0 || filterStatus || all {
+			writeJSONError(w, http.StatusBadRequest, "ids must be a non-empty list and cannot be combined with filters")
+			return
+		}
+		deleted, err = ts.DeleteTasks(req.IDs)
+	case filterStatus: // DELETE /todos?status=completed
+		deleted, err = ts.DeleteMatching(func(t Task) bool { return t.Status == status })
+	case all: // DELETE /todos?all=true
+		deleted, err = ts.DeleteMatching(func(Task) bool { return true })
+	default:
+		writeJSONError(w, http.StatusBadRequest, "pass ids in the body, a status filter or all=true to delete tasks")
+		return
+	}
+	if err != nil {
+		logRequest(r, slog.LevelWarn, "[todosHandler] Deleting tasks", err)
+		writeJSONError(w, updateErrorStatus(err), err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(bulkDeleteResponse{Deleted: deleted}); err != nil {
+		logRequest(r, slog.LevelError, "[todosHandler] Encoding result", err)
+		return
+	}
+}
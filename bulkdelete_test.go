@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка массового удаления задач
+// Сценарий:
+// 1. Создать две завершённые и две незавершённые задачи.
+// 2. Выполнить DELETE /todos без фильтра - ожидаем 400 Bad Request.
+// 3. Выполнить DELETE /todos?status=completed - ожидаем {"deleted":2}.
+// 4. Удалить задачи 3 и 99 списком - ожидаем 404, задача 3 не удалена.
+// 5. Удалить задачи 3 и 4 списком - ожидаем {"deleted":2} и пустой список задач.
+func TestBulkDelete(t *testing.T) {
+	ds := NewTaskStore()
+	for _, status := range []TaskStatus{StatusCompleted, StatusCompleted, StatusInProgress, StatusNotStarted} {
+		if _, err := ds.AddTask(Task{Title: "Task", Status: status}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	deleteTasks := func(query, body string) (int, int) {
+		req, err := http.NewRequest(http.MethodDelete, ts.URL+"/todos"+query, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make DELETE: %v", err)
+		}
+		var result bulkDeleteResponse
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode, result.Deleted
+	}
+	// Без фильтра
+	if status, _ := deleteTasks("", ""); status != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 without filter, got %d", status)
+	}
+	// По статусу
+	if status, deleted := deleteTasks("?status=completed", ""); status != http.StatusOK || deleted != 2 {
+		t.Errorf("expected 2 completed tasks deleted, got %d (status %d)", deleted, status)
+	}
+	// Список с несуществующим ID
+	if status, _ := deleteTasks("", `{"ids":[3,99]}`); status != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404, got %d", status)
+	}
+	if _, err := ds.GetTask(3); err != nil { // удаление НЕ атомарно
+		t.Errorf("expected task 3 to survive failed bulk delete: %v", err)
+	}
+	// Список
+	if status, deleted := deleteTasks("", `{"ids":[3,4]}`); status != http.StatusOK || deleted != 2 {
+		t.Errorf("expected 2 tasks deleted, got %d (status %d)", deleted, status)
+	}
+	if remaining := len(ds.GetAllTasks()); remaining != 0 {
+		t.Errorf("expected no tasks left, got %d", remaining)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CacheConfig Настройки заголовка Cache-Control для GET-ответов
+type CacheConfig struct {
+	MaxAge               time.Duration // Сколько ответ считается свежим
+	StaleWhileRevalidate time.Duration // Сколько можно отдавать устаревший ответ, обновляя его в фоне
+}
+
+// directive Значение Cache-Control для GET/HEAD-ответов
+func (c CacheConfig) directive() string {
+	if c.MaxAge <= 0 && c.StaleWhileRevalidate <= 0 { // кэшировать без проверки нельзя
+		return "no-cache"
+	}
+	directive := fmt.Sprintf("max-age=%d", int(c.MaxAge.Seconds()))
+	if c.StaleWhileRevalidate > 0 {
+		directive += fmt.Sprintf(", stale-while-revalidate=%d", int(c.StaleWhileRevalidate.Seconds()))
+	}
+	return directive
+}
+
+// hasCredentials Запрос передаёт учётные данные (API-ключ или JWT), ответ на него принадлежит конкретному клиенту
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("X-API-Key") != "" || r.Header.Get("Authorization") != ""
+}
+
+// cacheControlMiddleware Middleware, выставляющее Cache-Control по умолчанию (обработчик может его переопределить).
+// Ответы на запросы с учётными данными помечаются private: общий кэш не должен отдавать их другим клиентам
+func cacheControlMiddleware(c CacheConfig, next http.Handler) http.Handler {
+	directive := c.directive()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			if hasCredentials(r) {
+				w.Header().Set("Cache-Control", "private, "+directive)
+			} else {
+				w.Header().Set("Cache-Control", directive)
+			}
+		} else { // ответы на изменяющие запросы не кэшируются
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Проверка заголовков Cache-Control по методам
+// Сценарий:
+// 1. Создать задачу (POST) - ожидаем Cache-Control: no-store.
+// 2. Получить список и задачу (GET) - ожидаем настроенные max-age и stale-while-revalidate
+// и Vary с X-Fields: тело зависит от маски полей.
+// 3. Удалить задачу (DELETE) - ожидаем Cache-Control: no-store.
+func TestCacheControl(t *testing.T) {
+	cache := CacheConfig{MaxAge: 30 * time.Second, StaleWhileRevalidate: time.Minute}
+	store := NewTaskStore()
+	ts := httptest.NewServer(cacheControlMiddleware(cache, newMux(store, NewTenantRegistry(DefaultStoreConfig()), NewStats(), new(atomic.Bool))))
+
+	body, _ := json.Marshal(Task{ID: 1, Title: "Cached", Status: StatusNotStarted})
+	// Создаём задачу
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-store" { // изменяющий ответ кэшируется
+		t.Errorf("expected no-store on POST, got %q", got)
+	}
+	// Получаем список и задачу
+	for _, path := range []string{"/todos", "/todos/1"} {
+		resp2, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if got := resp2.Header.Get("Cache-Control"); got != "max-age=30, stale-while-revalidate=60" {
+			t.Errorf("unexpected Cache-Control on GET %s: %q", path, got)
+		}
+		if got := resp2.Header.Values("Vary"); !slices.Contains(got, "Accept, X-Fields") { // кэш смешает маски полей
+			t.Errorf("expected Vary with X-Fields on GET %s, got %q", path, got)
+		}
+		if err := resp2.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Удаляем задачу
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
+	resp3, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make DELETE: %v", err)
+	}
+	if got := resp3.Header.Get("Cache-Control"); got != "no-store" { // изменяющий ответ кэшируется
+		t.Errorf("expected no-store on DELETE, got %q", got)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp3.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка Cache-Control для запросов с учётными данными
+// Сценарий:
+// 1. Запросить список задач с X-API-Key и с Authorization - ожидаем private перед настроенными директивами.
+// 2. Запросить список без учётных данных - ожидаем директивы без private.
+func TestCacheControlPrivate(t *testing.T) {
+	cache := CacheConfig{MaxAge: 30 * time.Second}
+	ts := httptest.NewServer(cacheControlMiddleware(cache, newMux(NewTaskStore(), NewTenantRegistry(DefaultStoreConfig()), NewStats(), new(atomic.Bool))))
+	defer ts.Close()
+
+	for _, c := range []struct {
+		header, value, want string
+	}{
+		{"X-API-Key", "secret", "private, max-age=30"},
+		{"Authorization", "Bearer token", "private, max-age=30"},
+		{"", "", "max-age=30"},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/todos", nil)
+		if c.header != "" {
+			req.Header.Set(c.header, c.value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != c.want { // ответ клиента доступен общему кэшу
+			t.Errorf("%q: expected Cache-Control %q, got %q", c.header, c.want, got)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+}
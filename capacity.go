@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// ErrStoreFull Ошибка создания задачи в заполненном хранилище
+var ErrStoreFull = errors.New("task store is full")
+
+// EvictionPolicy Поведение хранилища при достижении лимита задач
+type EvictionPolicy string
+
+const (
+	EvictionReject    EvictionPolicy = "reject"          // Отклонять новые задачи
+	EvictionCompleted EvictionPolicy = "evict-completed" // Вытеснять самые старые завершённые и удалённые задачи
+)
+
+// parseEvictionPolicy Разбор политики вытеснения из строки
+func parseEvictionPolicy(s string) (EvictionPolicy, error) {
+	switch policy := EvictionPolicy(s); policy {
+	case EvictionReject, EvictionCompleted:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown eviction policy %q (want %s or %s)", s, EvictionReject, EvictionCompleted)
+}
+
+// NewTaskStoreWithCapacity Создание хранилища задач с настройками по умолчанию и лимитом в n задач
+func NewTaskStoreWithCapacity(n int) *TaskStore {
+	config := DefaultStoreConfig()
+	config.MaxTasks = n
+	return NewTaskStoreWithConfig(config)
+}
+
+// makeRoom Освобождает место под n новых задач согласно политике вытеснения (вызывается под блокировкой)
+func (ds *TaskStore) makeRoom(n int) error {
+	victims, err := ds.roomFor(n)
+	if err != nil {
+		return err
+	}
+	for _, task := range victims {
+		ds.evictTask(task)
+	}
+	return nil
+}
+
+// roomFor Проверяет, что под n новых задач есть место, и возвращает задачи, которые для этого придётся вытеснить
+// (вызывается под блокировкой, хранилище не меняет).
+// Задачи в корзине тоже занимают память, поэтому учитываются в лимите и вытесняются в первую очередь вместе с завершёнными.
+// Задачи, на которые ссылаются подзадачи (в том числе из корзины), не вытесняются, чтобы подзадачи не остались без родителя
+func (ds *TaskStore) roomFor(n int) ([]Task, error) {
+	if ds.config.MaxTasks <= 0 { // лимит не задан
+		return nil, nil
+	}
+	excess := len(ds.tasks) + n - ds.config.MaxTasks
+	if excess <= 0 {
+		return nil, nil
+	}
+	if ds.config.EvictionPolicy != EvictionCompleted {
+		return nil, fmt.Errorf("%w: limit is %d tasks", ErrStoreFull, ds.config.MaxTasks)
+	}
+	var candidates []Task
+	for _, task := range ds.tasks {
+		if (task.Status == StatusCompleted || task.DeletedAt != nil) && !ds.hasSubtasks(task.ID) {
+			candidates = append(candidates, task)
+		}
+	}
+	if len(candidates) < excess { // вытеснять нечего, сохранять частично не будем
+		return nil, fmt.Errorf("%w: limit is %d tasks and no completed tasks to evict", ErrStoreFull, ds.config.MaxTasks)
+	}
+	slices.SortFunc(candidates, func(a, b Task) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return candidates[:excess], nil
+}
+
+// evictTask Окончательно удаляет задачу из хранилища (вызывается под блокировкой)
+func (ds *TaskStore) evictTask(task Task) {
+	delete(ds.tasks, task.ID)
+	ds.unindexAssignee(task)
+	ds.unindexParent(task)
+	if !ds.hasSubtasks(task.ID) { // иначе новая задача с этим ID унаследовала бы чужие подзадачи
+		ds.releaseID(task.ID)
+	}
+	ds.deletedSinceCompaction++
+	slog.Info("[evictTask] Evicting task", "task_id", task.ID)
+	ds.record(TaskEventEvicted, &task, nil)
+	// журнал в памяти тоже освобождается, в файле журнала записи остаются
+	delete(ds.history, task.ID)
+	delete(ds.undo, task.ID)
+	if task.DeletedAt != nil { // задача уже была удалена, в статистике она не учитывается
+		return
+	}
+	ds.unindexExternalID(task)
+	ds.config.Stats.TaskDeleted()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// Проверка лимита числа задач с политикой reject
+// Сценарий:
+// 1. Создать хранилище на 2 задачи и заполнить его.
+// 2. Создать третью задачу через POST /todos - ожидаем 507 Insufficient Storage.
+// 3. Удалить задачу в корзину и снова создать - ожидаем отказ: удалённая задача тоже занимает место.
+func TestCapacityReject(t *testing.T) {
+	ds := NewTaskStoreWithCapacity(2)
+	for range 2 {
+		if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	// Создание сверх лимита
+	body, _ := json.Marshal(Task{Title: "Overflow", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusInsufficientStorage { // получили НЕ 507
+		t.Errorf("expected 507, got %d", resp.StatusCode)
+	}
+	// Удалённая задача остаётся в хранилище
+	if err := ds.DeleteTask(1, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if _, err := ds.AddTask(Task{Title: "Overflow", Status: StatusNotStarted}); !errors.Is(err, ErrStoreFull) {
+		t.Errorf("expected ErrStoreFull, got %v", err)
+	}
+}
+
+// Проверка вытеснения завершённых задач при достижении лимита
+// Сценарий:
+// 1. Создать хранилище на 3 задачи с политикой evict-completed: завершённые задачи 1 и 2 и незавершённую 3.
+// 2. Создать задачу 4 - ожидаем, что вытеснена самая старая завершённая задача 1.
+// 3. Создать пакет из двух задач - ожидаем отказ: завершённая задача для вытеснения осталась одна.
+func TestCapacityEvictCompleted(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.MaxTasks = 3
+	config.EvictionPolicy = EvictionCompleted
+	ds := NewTaskStoreWithConfig(config)
+	for _, status := range []TaskStatus{StatusCompleted, StatusCompleted, StatusNotStarted} {
+		if _, err := ds.AddTask(Task{Title: "Task", Status: status}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+
+	// Вытеснение одной задачи
+	created, err := ds.AddTask(Task{Title: "New", Status: StatusNotStarted})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	if created.ID != 4 { // ID вытесненной задачи переиспользован
+		t.Errorf("expected id 4, got %d", created.ID)
+	}
+	if _, err := ds.GetTask(1); err == nil { // самая старая завершённая задача НЕ вытеснена
+		t.Errorf("expected task 1 to be evicted")
+	}
+	if got := len(ds.GetAllTasks()); got != 3 {
+		t.Errorf("expected 3 tasks, got %d", got)
+	}
+	// Пакет не помещается даже после вытеснения
+	if _, err := ds.AddTasks([]Task{{Title: "A", Status: StatusNotStarted}, {Title: "B", Status: StatusNotStarted}}); !errors.Is(err, ErrStoreFull) {
+		t.Errorf("expected ErrStoreFull, got %v", err)
+	}
+	if _, err := ds.GetTask(2); err != nil { // задача вытеснена, хотя пакет отклонён
+		t.Errorf("expected task 2 to survive rejected batch: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrChecklistItemNotFound Ошибка обращения к несуществующему пункту чек-листа
+var ErrChecklistItemNotFound = errors.New("checklist item not found")
+
+// ChecklistItem Пункт чек-листа задачи
+type ChecklistItem struct {
+	Text string `json:"text" xml:"text"`
+	Done bool   `json:"done" xml:"done"`
+}
+
+// ChecklistOp Операция над чек-листом задачи (тело PATCH /todos/{id}/checklist)
+type ChecklistOp struct {
+	Op    string `json:"op"`    // add, toggle или remove
+	Text  string `json:"text"`  // текст нового пункта (для add)
+	Index int    `json:"index"` // номер пункта, начиная с 0 (для toggle и remove)
+}
+
+// Validate Валидация операции над чек-листом
+func (op *ChecklistOp) Validate() error {
+	switch op.Op {
+	case "add":
+		if strings.TrimSpace(op.Text) == "" {
+			return fmt.Errorf("checklist item text cannot be empty")
+		}
+	case "toggle", "remove":
+		if op.Index < 0 {
+			return fmt.Errorf("checklist item index must be non-negative")
+		}
+	default:
+		return fmt.Errorf("unknown checklist operation %q", op.Op)
+	}
+	return nil
+}
+
+// countChecklistDone Пересчёт числа выполненных пунктов чек-листа
+func (t *Task) countChecklistDone() {
+	t.ChecklistDone = 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			t.ChecklistDone++
+		}
+	}
+}
+
+// UpdateChecklist Применяет операцию к чек-листу задачи по ID (version, если не 0, - ожидаемая версия задачи)
+func (ds *TaskStore) UpdateChecklist(id int, op ChecklistOp, version int) (Task, error) {
+	ds.mutex.Lock()
+	task, ok := ds.liveTask(id)
+	if !ok { // задача с таким ID не найдена
+		ds.mutex.Unlock()
+		err := fmt.Errorf("task with id %d not found", id)
+		slog.Warn("[UpdateChecklist] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if err := checkVersion(task, version); err != nil { // задачу успели изменить
+		ds.mutex.Unlock()
+		slog.Warn("[UpdateChecklist] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if op.Op != "add" && op.Index >= len(task.Checklist) { // пункта с таким номером нет
+		ds.mutex.Unlock()
+		err := fmt.Errorf("%w: index %d", ErrChecklistItemNotFound, op.Index)
+		slog.Warn("[UpdateChecklist] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	// копируем чек-лист, чтобы не менять срез, который мог быть отдан читателям
+	checklist := make([]ChecklistItem, 0, len(task.Checklist)+1)
+	checklist = append(checklist, task.Checklist...)
+	switch op.Op {
+	case "add":
+		checklist = append(checklist, ChecklistItem{Text: strings.TrimSpace(op.Text)})
+	case "toggle":
+		checklist[op.Index].Done = !checklist[op.Index].Done
+	case "remove":
+		checklist = append(checklist[:op.Index], checklist[op.Index+1:]...)
+	}
+	before := task
+	task.Checklist = checklist
+	task.countChecklistDone()
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	ds.tasks[id] = task
+	task = ds.numberTask(task)
+	ds.record(TaskEventUpdated, &before, &task)
+	ds.mutex.Unlock()
+	return task, nil
+}
+
+// checklistHandler Обработчик эндпоинта /todos/{id}/checklist
+func checklistHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			logRequest(r, slog.LevelWarn, "[checklistHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[checklistHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var op ChecklistOp
+		if err := json.NewDecoder(r.Body).Decode(&op); err != nil {
+			logRequest(r, slog.LevelWarn, "[checklistHandler] Decoding", err, "task_id", id)
+			writeDecodeError(w, err)
+			return
+		}
+		if err := op.Validate(); err != nil {
+			logRequest(r, slog.LevelWarn, "[checklistHandler] Validation", err, "task_id", id)
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		task, err := ts.UpdateChecklist(id, op, ifMatchVersion(r))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[checklistHandler] Updating checklist", err, "task_id", id)
+			status := updateErrorStatus(err)
+			if errors.Is(err, ErrChecklistItemNotFound) {
+				status = http.StatusBadRequest
+			}
+			writeJSONError(w, status, err.Error())
+			return
+		}
+		location := strings.TrimSuffix(r.URL.Path, "/checklist")
+		if err := writeMutationResult(w, r, http.StatusOK, task, location, true); err != nil {
+			logRequest(r, slog.LevelError, "[checklistHandler] Encoding task", err, "task_id", id)
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// patchChecklist Отправка операции над чек-листом задачи
+func patchChecklist(t *testing.T, url string, op ChecklistOp) *http.Response {
+	body, _ := json.Marshal(op)
+	req, _ := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PATCH: %v", err)
+	}
+	return resp
+}
+
+// Проверка работы с чек-листом задачи
+// Сценарий:
+// 1. Создать задачу и добавить в неё два пункта - ожидаем успех (200 OK).
+// 2. Отметить второй пункт выполненным - ожидаем checklist_done = 1.
+// 3. Удалить несуществующий пункт - ожидаем ошибку (400 Bad Request).
+// 4. Добавить пункт с пустым текстом - ожидаем ошибку (400 Bad Request).
+func TestChecklist(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{ID: 1, Title: "With checklist", Status: StatusNotStarted})
+	// Создаём задачу
+	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	url := ts.URL + "/todos/1/checklist"
+	// Добавляем пункты
+	for _, text := range []string{"first", "second"} {
+		resp := patchChecklist(t, url, ChecklistOp{Op: "add", Text: text})
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Отмечаем второй пункт выполненным
+	resp := patchChecklist(t, url, ChecklistOp{Op: "toggle", Index: 1})
+	var got Task
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got.Checklist) != 2 || !got.Checklist[1].Done || got.ChecklistDone != 1 { // чек-лист НЕ корректен
+		t.Errorf("unexpected checklist %+v (done %d)", got.Checklist, got.ChecklistDone)
+	}
+	// Удаляем несуществующий пункт
+	resp2 := patchChecklist(t, url, ChecklistOp{Op: "remove", Index: 5})
+	if resp2.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for missing item, got %d", resp2.StatusCode)
+	}
+	// Добавляем пустой пункт
+	resp3 := patchChecklist(t, url, ChecklistOp{Op: "add", Text: "   "})
+	if resp3.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for empty text, got %d", resp3.StatusCode)
+	}
+	for _, r := range []*http.Response{resp, resp2, resp3} {
+		if err := r.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"log/slog"
+	"maps"
+	"time"
+)
+
+// PurgeTrash Окончательно удаляет задачи, пролежавшие в корзине дольше TrashRetention, и возвращает их число
+func (ds *TaskStore) PurgeTrash(now time.Time) int {
+	if ds.config.TrashRetention <= 0 { // корзина хранится бессрочно
+		return 0
+	}
+	cutoff := now.Add(-ds.config.TrashRetention)
+	ds.mutex.Lock()
+	var expired []Task
+	for _, task := range ds.tasks {
+		if task.DeletedAt != nil && !task.DeletedAt.After(cutoff) {
+			expired = append(expired, task)
+		}
+	}
+	// родитель удаляется только после своих подзадач, поэтому дерево из корзины очищается снизу вверх за несколько проходов;
+	// задача, у которой осталась неистёкшая подзадача, ждёт следующей очистки
+	purged := 0
+	for progress := true; progress; {
+		progress = false
+		remaining := expired[:0]
+		for _, task := range expired {
+			if ds.hasSubtasks(task.ID) {
+				remaining = append(remaining, task)
+				continue
+			}
+			ds.evictTask(task)
+			purged++
+			progress = true
+		}
+		expired = remaining
+	}
+	ds.mutex.Unlock()
+	return purged
+}
+
+// MaybeCompact Пересоздаёт карту задач, если доля удалённых с прошлого сжатия записей достигла порога.
+// Go не уменьшает карту после удалений, поэтому при активном удалении задач память иначе не освобождается.
+// Удаление в корзину запись не убирает: она освобождается, когда задачу окончательно удаляет PurgeTrash или вытеснение
+func (ds *TaskStore) MaybeCompact() bool {
+	if ds.config.CompactionRatio <= 0 { // сжатие выключено
+		return false
+	}
+	ds.mutex.Lock()
+	deleted := ds.deletedSinceCompaction
+	if deleted == 0 || float64(deleted)/float64(len(ds.tasks)+deleted) < ds.config.CompactionRatio {
+		ds.mutex.Unlock()
+		return false
+	}
+	tasks := make(map[int]Task, len(ds.tasks))
+	maps.Copy(tasks, ds.tasks)
+	ds.tasks = tasks
+	ds.deletedSinceCompaction = 0
+	ds.mutex.Unlock()
+	ds.config.Stats.Compacted(time.Now())
+	return true
+}
+
+// runCompaction Периодически очищает корзину и проверяет необходимость сжатия хранилищ (блокирует вызывающую горутину)
+func runCompaction(interval time.Duration, stores func() []*TaskStore) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for _, ds := range stores() {
+			if purged := ds.PurgeTrash(now); purged > 0 {
+				slog.Info("[runCompaction] Purged expired trash", "tenant", ds.tenant, "purged", purged)
+			}
+			if ds.MaybeCompact() {
+				slog.Info("[runCompaction] Task map compacted")
+			}
+		}
+	}
+}
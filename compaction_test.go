@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Проверка сжатия карты задач
+// Сценарий:
+// 1. Создать 10 задач и удалить 6 из них в корзину при пороге 0.5 - ожидаем, что сжатие не выполнится:
+// задачи в корзине остаются в карте.
+// 2. Очистить корзину после истечения срока хранения - ожидаем, что 6 задач удалятся окончательно,
+// а сжатие выполнится.
+// 3. Проверить, что оставшиеся задачи доступны, а время сжатия попало в статистику.
+// 4. Повторно вызвать сжатие без новых удалений - ожидаем, что оно не выполнится.
+func TestCompaction(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.CompactionRatio = 0.5
+	config.TrashRetention = time.Hour
+	config.Stats = NewStats()
+	ds := NewTaskStoreWithConfig(config)
+
+	// Создаём и удаляем задачи
+	for id := 1; id <= 10; id++ {
+		if err := ds.CreateTask(Task{ID: id, Title: "Task", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	for id := 1; id <= 6; id++ {
+		if err := ds.DeleteTask(id, 0); err != nil {
+			t.Fatalf("failed to delete task: %v", err)
+		}
+	}
+	if ds.MaybeCompact() { // задачи из корзины ещё в карте, сжимать нечего
+		t.Fatalf("expected no compaction while deleted tasks are in the trash")
+	}
+	// Срок хранения ещё не истёк
+	if purged := ds.PurgeTrash(time.Now()); purged != 0 {
+		t.Fatalf("expected nothing purged before retention expires, got %d", purged)
+	}
+	// Срок хранения истёк
+	if purged := ds.PurgeTrash(time.Now().Add(2 * time.Hour)); purged != 6 { // корзина НЕ очищена
+		t.Fatalf("expected 6 purged tasks, got %d", purged)
+	}
+	// Сжимаем хранилище
+	if !ds.MaybeCompact() { // сжатие НЕ выполнено
+		t.Fatalf("expected compaction after purging 60%% of tasks")
+	}
+	if got := len(ds.GetAllTasksIncludingDeleted()); got != 4 { // задачи потерялись при сжатии
+		t.Errorf("expected 4 tasks after compaction, got %d", got)
+	}
+	if snapshot := config.Stats.Snapshot(); snapshot.Compactions != 1 || snapshot.LastCompaction == nil {
+		t.Errorf("compaction not reflected in stats: %+v", snapshot)
+	}
+	// Повторное сжатие не нужно
+	if ds.MaybeCompact() {
+		t.Errorf("expected no compaction without new deletions")
+	}
+}
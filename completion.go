@@ -0,0 +1,15 @@
+package main
+
+import "time"
+
+// trackCompletion Ведёт время завершения задачи при смене статуса (from - прежний статус, "" для новой задачи):
+// переход в completed ставит текущее время, повторное сохранение завершённой задачи его не меняет,
+// а уход из completed сбрасывает
+func trackCompletion(from TaskStatus, task *Task, now time.Time) {
+	switch {
+	case task.Status != StatusCompleted:
+		task.CompletedAt = nil
+	case from != StatusCompleted || task.CompletedAt == nil:
+		task.CompletedAt = &now
+	}
+}
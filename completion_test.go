@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Проверка времени завершения задачи
+// Сценарий:
+// 1. Создать задачу и перевести её в in progress - ожидаем пустой completed_at.
+// 2. Завершить задачу - ожидаем completed_at и среднее время завершения в сводке.
+// 3. Повторно сохранить завершённую задачу - ожидаем, что completed_at не изменился.
+// 4. Вернуть завершённую задачу в работу (в обход правил переходов) - ожидаем, что completed_at сброшен.
+func TestCompletedAt(t *testing.T) {
+	ds := NewTaskStore()
+
+	// Создаём задачу и берём её в работу
+	task, err := ds.AddTask(Task{Title: "Finish", Status: StatusNotStarted})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	if task, err = ds.UpdateTask(task.ID, Task{Title: "Finish", Status: StatusInProgress}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if task.CompletedAt != nil { // незавершённая задача получила время завершения
+		t.Errorf("expected no completed_at, got %v", task.CompletedAt)
+	}
+	// Завершаем задачу
+	if task, err = ds.UpdateTask(task.ID, Task{Title: "Finish", Status: StatusCompleted}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if task.CompletedAt == nil || task.CompletedAt.Before(task.CreatedAt) { // время завершения НЕ выставлено
+		t.Fatalf("expected completed_at after created_at, got %v", task.CompletedAt)
+	}
+	completedAt := *task.CompletedAt
+	if stats := ds.Stats(); stats.AvgCompletionSeconds == nil || *stats.AvgCompletionSeconds < 0 { // среднее НЕ посчитано
+		t.Errorf("expected average completion time, got %v", stats.AvgCompletionSeconds)
+	}
+	// Повторно сохраняем завершённую задачу
+	time.Sleep(time.Millisecond)
+	if task, err = ds.UpdateTask(task.ID, Task{Title: "Finished", Status: StatusCompleted}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if task.CompletedAt == nil || !task.CompletedAt.Equal(completedAt) { // время завершения перезаписано
+		t.Errorf("expected completed_at %v to be kept, got %v", completedAt, task.CompletedAt)
+	}
+	// Возвращаем задачу в работу
+	reopened := Task{Status: StatusInProgress, CompletedAt: &completedAt}
+	trackCompletion(StatusCompleted, &reopened, time.Now())
+	if reopened.CompletedAt != nil { // время завершения НЕ сброшено
+		t.Errorf("expected completed_at to be cleared, got %v", reopened.CompletedAt)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// CountByStatus Возвращает число задач в каждом статусе (удалённые в корзину не учитываются)
+func (ds *TaskStore) CountByStatus() map[TaskStatus]int {
+	counts := map[TaskStatus]int{StatusNotStarted: 0, StatusInProgress: 0, StatusCompleted: 0}
+	ds.mutex.RLock()
+	for _, t := range ds.tasks {
+		if t.DeletedAt == nil {
+			counts[t.Status]++
+		}
+	}
+	ds.mutex.RUnlock()
+	return counts
+}
+
+// countHandler Обработчик эндпоинта /todos/count: {"total":N} или, с ?by=status, число задач по статусам
+func countHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[countHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		counts := ts.CountByStatus()
+		var body any
+		switch by := r.URL.Query().Get("by"); by {
+		case "":
+			total := 0
+			for _, n := range counts {
+				total += n
+			}
+			body = map[string]int{"total": total}
+		case "status":
+			body = counts
+		default:
+			err := fmt.Errorf("unknown grouping %q: must be status", by)
+			logRequest(r, slog.LevelWarn, "[countHandler] Invalid grouping", err)
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			logRequest(r, slog.LevelError, "[countHandler] Encoding counts", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка подсчёта задач
+// Сценарий:
+// 1. Создать три задачи (две not started, одна in progress) и удалить одну из not started.
+// 2. Запросить /todos/count - ожидаем {"total":2}.
+// 3. Запросить /todos/count?by=status - ожидаем по одной задаче в not started и in progress и 0 в completed.
+// 4. Запросить неизвестную разбивку - ожидаем ошибку (400 Bad Request).
+func TestCount(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём задачи и удаляем одну
+	for _, status := range []TaskStatus{StatusNotStarted, StatusNotStarted, StatusInProgress} {
+		body, _ := json.Marshal(Task{Title: "Counted", Status: status})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make DELETE: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	// Проверяем подсчёт
+	for query, want := range map[string]map[string]int{
+		"":           {"total": 2},
+		"?by=status": {"not started": 1, "in progress": 1, "completed": 0},
+	} {
+		resp, err := http.Get(ts.URL + "/todos/count" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var got map[string]int
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Errorf("GET /todos/count%s: expected %v, got %v", query, want, got)
+		}
+		for key, n := range want {
+			if got[key] != n { // подсчёт НЕ совпал
+				t.Errorf("GET /todos/count%s: expected %s=%d, got %d", query, key, n, got[key])
+			}
+		}
+	}
+	resp, err = http.Get(ts.URL + "/todos/count?by=priority")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for unknown grouping, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest Нестандартный код ответа (nginx): клиент закрыл соединение, не дождавшись ответа
+const StatusClientClosedRequest = 499
+
+// contextErrorStatus Подбор HTTP статуса для ошибки контекста запроса (ok = false, если это другая ошибка)
+func contextErrorStatus(err error) (status int, ok bool) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest, true
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, true
+	}
+	return 0, false
+}
+
+// GetTaskCtx Вариант Store.GetTask, прерывающийся, если контекст уже отменён
+func GetTaskCtx(ctx context.Context, s Store, id int) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+	return s.GetTask(id)
+}
+
+// GetAllTasksCtx Вариант Store.GetAllTasks, прерывающийся, если контекст уже отменён
+func GetAllTasksCtx(ctx context.Context, s Store) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.GetAllTasks(), nil
+}
+
+// AddTaskCtx Вариант Store.AddTask, прерывающийся, если контекст уже отменён
+func AddTaskCtx(ctx context.Context, s Store, task Task) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+	return s.AddTask(task)
+}
+
+// UpsertTaskCtx Вариант Store.UpsertTask, прерывающийся, если контекст уже отменён
+func UpsertTaskCtx(ctx context.Context, s Store, id int, task Task) (Task, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, false, err
+	}
+	return s.UpsertTask(id, task)
+}
+
+// DeleteTaskCtx Вариант Store.DeleteTask (Store.DeleteTaskTree при cascade), прерывающийся, если контекст уже отменён
+func DeleteTaskCtx(ctx context.Context, s Store, id int, version int, cascade bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if cascade {
+		return s.DeleteTaskTree(id, version)
+	}
+	return s.DeleteTask(id, version)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Проверка прерывания операций при отменённом контексте
+// Сценарий:
+// 1. Вызвать GetTaskCtx с отменённым контекстом - ожидаем context.Canceled, хотя задача существует.
+// 2. Выполнить GET /todos/{id} и DELETE /todos/{id} с отменённым контекстом - ожидаем 499, задача не удалена.
+func TestStoreContextCancel(t *testing.T) {
+	ds := NewTaskStore()
+	if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// Операция хранилища
+	if _, err := GetTaskCtx(ctx, ds, 1); !errors.Is(err, context.Canceled) { // операция НЕ прервалась
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	// Обработчик
+	handler := todoHandler(ds)
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		req := httptest.NewRequestWithContext(ctx, method, "/todos/1", nil)
+		req.SetPathValue("id", "1")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != StatusClientClosedRequest { // получили НЕ 499
+			t.Errorf("%s: expected 499, got %d", method, rec.Code)
+		}
+	}
+	if _, err := ds.GetTask(1); err != nil { // задача удалена, хотя клиент ушёл
+		t.Errorf("expected task to survive cancelled delete: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// FieldChange Изменение поля задачи между двумя её состояниями (значения в JSON; отсутствует - поле не задано)
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from,omitempty"`
+	To    json.RawMessage `json:"to,omitempty"`
+}
+
+// TaskDiff Различия задачи между двумя записями журнала
+type TaskDiff struct {
+	TaskID  int           `json:"task_id"`
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// taskFields Поля задачи в JSON-представлении (nil - пустой набор)
+func taskFields(task *Task) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if task == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// diffTasks Различия двух состояний задачи по полям JSON-представления, отсортированные по имени поля
+func diffTasks(from, to *Task) ([]FieldChange, error) {
+	before, err := taskFields(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := taskFields(to)
+	if err != nil {
+		return nil, err
+	}
+	union := maps.Clone(before)
+	maps.Copy(union, after)
+	names := slices.Sorted(maps.Keys(union))
+	changes := []FieldChange{}
+	for _, name := range names {
+		if !bytes.Equal(before[name], after[name]) {
+			changes = append(changes, FieldChange{Field: name, From: before[name], To: after[name]})
+		}
+	}
+	return changes, nil
+}
+
+// entryState Состояние задачи после записи журнала (для вытеснения - последнее известное)
+func entryState(entry AuditEntry) *Task {
+	if entry.After != nil {
+		return entry.After
+	}
+	return entry.Before
+}
+
+// Diff Возвращает различия задачи между записями журнала с номерами from и to
+func (ds *TaskStore) Diff(id, from, to int) (TaskDiff, error) {
+	entries, err := ds.History(id)
+	if err != nil {
+		return TaskDiff{}, err
+	}
+	first := entries[0].Seq // старые записи могли быть вытеснены из памяти
+	for _, seq := range []int{from, to} {
+		if seq < first || seq >= first+len(entries) {
+			return TaskDiff{}, fmt.Errorf("no history entry %d for task with id %d", seq, id)
+		}
+	}
+	changes, err := diffTasks(entryState(entries[from-first]), entryState(entries[to-first]))
+	if err != nil {
+		return TaskDiff{}, err
+	}
+	return TaskDiff{TaskID: id, From: from, To: to, Changes: changes}, nil
+}
+
+// diffHandler Обработчик эндпоинта /todos/{id}/diff?from=<seq>&to=<seq>
+func diffHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[diffHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[diffHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var seqs [2]int
+		for i, name := range []string{"from", "to"} {
+			if seqs[i], err = strconv.Atoi(r.URL.Query().Get(name)); err != nil {
+				err = fmt.Errorf("%s must be a history entry number", name)
+				logRequest(r, slog.LevelWarn, "[diffHandler] Invalid sequence", err, "task_id", id)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		diff, err := ts.Diff(id, seqs[0], seqs[1])
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[diffHandler] Diffing history", err, "task_id", id)
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if fields := requestedFields(r); fields != nil { // с X-Fields - только изменения запрошенных полей
+			diff.Changes = slices.DeleteFunc(diff.Changes, func(change FieldChange) bool { return !fields[change.Field] })
+		}
+		w.Header().Add("Vary", "X-Fields")
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(diff); err != nil {
+			logRequest(r, slog.LevelError, "[diffHandler] Encoding diff", err, "task_id", id)
+			return
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+// PreviewAddTask Проверки AddTask без сохранения: возвращает задачу, которая была бы создана.
+// ID в ней - тот, что получила бы задача, если бы до неё никто ничего не создал
+func (ds *TaskStore) PreviewAddTask(task Task) (Task, error) {
+	return ds.addTask(task, true)
+}
+
+// PreviewUpsertTask Проверки UpsertTask без сохранения: возвращает задачу, которая была бы создана
+// (created = true), или задачу, какой она стала бы после обновления
+func (ds *TaskStore) PreviewUpsertTask(id int, task Task) (Task, bool, error) {
+	return ds.upsertTask(id, task, true)
+}
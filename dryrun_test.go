@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка пробного создания и обновления задачи (?dry_run=true)
+// Сценарий:
+// 1. Пробно создать задачу - ожидаем 200 с задачей, которая была бы создана, а хранилище пусто.
+// 2. Пробно создать некорректную задачу - ожидаем ту же ошибку, что и без dry_run (422).
+// 3. Создать задачу и пробно перевести её в in progress - ожидаем 200 с новым статусом и версией,
+// а сохранённая задача не изменилась.
+// 4. Пробно выполнить недопустимый переход статуса - ожидаем 409, как у настоящего обновления.
+// 5. Передать некорректное значение dry_run или массив задач - ожидаем 400.
+func TestDryRun(t *testing.T) {
+	store := NewTaskStore()
+	ts := startTestServerWithStore(store)
+	defer ts.Close()
+
+	do := func(method, path string, v any) (*http.Response, Task) {
+		body, _ := json.Marshal(v)
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		var task Task
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, task
+	}
+
+	// Пробное создание
+	resp, preview := do(http.MethodPost, "/todos?dry_run=true", Task{Title: "  Draft  ", Status: StatusNotStarted})
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if preview.Title != "Draft" || preview.Version != 1 || preview.Priority != PriorityMedium { // задача НЕ подготовлена как при создании
+		t.Errorf("expected preprocessed task, got %+v", preview)
+	}
+	if tasks := store.GetAllTasks(); len(tasks) != 0 { // задача сохранена
+		t.Errorf("expected empty store, got %d tasks", len(tasks))
+	}
+	// Пробное создание с ошибкой валидации
+	if resp, _ := do(http.MethodPost, "/todos?dry_run=true", Task{Status: StatusNotStarted}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid dry run, got %d", resp.StatusCode)
+	}
+
+	// Пробное обновление
+	do(http.MethodPost, "/todos", Task{Title: "Real", Status: StatusNotStarted})
+	resp, preview = do(http.MethodPut, "/todos/1?dry_run=true", Task{Title: "Real", Status: StatusInProgress})
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if preview.Status != StatusInProgress || preview.Version != 2 { // предпросмотр НЕ отражает обновление
+		t.Errorf("expected in progress task at version 2, got %+v", preview)
+	}
+	stored, err := store.GetTask(1)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if stored.Status != StatusNotStarted || stored.Version != 1 { // задача изменена
+		t.Errorf("expected unchanged task, got %+v", stored)
+	}
+	// Недопустимый переход статуса
+	if resp, _ := do(http.MethodPut, "/todos/1?dry_run=true", Task{Title: "Real", Status: StatusCompleted}); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for forbidden transition, got %d", resp.StatusCode)
+	}
+
+	// Некорректные запросы
+	if resp, _ := do(http.MethodPost, "/todos?dry_run=maybe", Task{Title: "Draft", Status: StatusNotStarted}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid dry_run, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(http.MethodPost, "/todos?dry_run=true", []Task{{Title: "Draft", Status: StatusNotStarted}}); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for batch dry run, got %d", resp.StatusCode)
+	}
+}
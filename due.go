@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// dueFilter Фильтр задач по сроку выполнения (?due_before=, ?due_after=, ?overdue=true)
+type dueFilter struct {
+	before  *time.Time // Срок раньше этого момента
+	after   *time.Time // Срок позже этого момента
+	overdue bool       // Только незавершённые задачи с истёкшим сроком
+	now     time.Time  // Текущее время сервера (для overdue)
+}
+
+// parseTimeQuery Разбор параметра запроса в формате RFC3339 (nil, если параметр не передан)
+func parseTimeQuery(r *http.Request, name string) (*time.Time, error) {
+	if !r.URL.Query().Has(name) {
+		return nil, nil
+	}
+	value, err := time.Parse(time.RFC3339, r.URL.Query().Get(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &value, nil
+}
+
+// parseDueFilter Разбор параметров фильтра по сроку (ok = false, если ни один параметр не передан)
+func parseDueFilter(r *http.Request) (filter dueFilter, ok bool, err error) {
+	if filter.before, err = parseTimeQuery(r, "due_before"); err != nil {
+		return dueFilter{}, false, err
+	}
+	if filter.after, err = parseTimeQuery(r, "due_after"); err != nil {
+		return dueFilter{}, false, err
+	}
+	if filter.overdue, err = parseBoolQuery(r, "overdue"); err != nil {
+		return dueFilter{}, false, err
+	}
+	filter.now = time.Now()
+	return filter, filter.before != nil || filter.after != nil || filter.overdue, nil
+}
+
+// match Проверка, что задача подходит под фильтр (задачи без срока не подходят)
+func (f dueFilter) match(task Task) bool {
+	if task.DueAt == nil {
+		return false
+	}
+	if f.before != nil && !task.DueAt.Before(*f.before) {
+		return false
+	}
+	if f.after != nil && !task.DueAt.After(*f.after) {
+		return false
+	}
+	if f.overdue && (task.Status == StatusCompleted || !task.DueAt.Before(f.now)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Проверка фильтров по сроку выполнения
+// Сценарий:
+// 1. Создать задачи: просроченную, просроченную завершённую, со сроком в будущем и без срока.
+// 2. Получить список с ?overdue=true - ожидаем только просроченную незавершённую задачу.
+// 3. Получить список с ?due_before= и ?due_after= текущего времени - ожидаем две и одну задачу соответственно.
+// 4. Передать некорректную дату - ожидаем ошибку (400 Bad Request).
+func TestDueFilters(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	// Создаём задачи
+	for _, task := range []Task{
+		{Title: "Overdue", Status: StatusInProgress, DueAt: &past},
+		{Title: "Done late", Status: StatusCompleted, DueAt: &past},
+		{Title: "Upcoming", Status: StatusNotStarted, DueAt: &future},
+		{Title: "Someday", Status: StatusNotStarted},
+	} {
+		body, _ := json.Marshal(task)
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Проверяем фильтры
+	stamp := url.QueryEscape(now.Format(time.RFC3339))
+	for query, want := range map[string]int{
+		"?overdue=true":          1,
+		"?due_before=" + stamp:   2,
+		"?due_after=" + stamp:    1,
+		"?due_before=tomorrow":   -1,
+		"?overdue=sometimes":     -1,
+		"?due_after=2024-01-01Z": -1,
+	} {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if want < 0 {
+			if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+				t.Errorf("GET %s: expected 400, got %d", query, resp.StatusCode)
+			}
+			continue
+		}
+		if len(tasks) != want { // фильтр вернул НЕ те задачи
+			t.Errorf("GET %s: expected %d tasks, got %d", query, want, len(tasks))
+		}
+	}
+}
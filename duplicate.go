@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// duplicateTask Копия задачи для POST /todos/{id}/duplicate: статус и чек-лист сброшены, к заголовку добавлен " (copy)".
+// Внешний идентификатор не копируется, ID и служебные поля назначаются при создании.
+// Указатели копируются по значению: тело запроса декодируется поверх копии и не должно менять исходную задачу
+func duplicateTask(task Task) Task {
+	copied := Task{
+		Title:       task.Title + " (copy)",
+		Description: task.Description,
+		Status:      StatusNotStarted,
+		Priority:    task.Priority,
+		DueAt:       clonePtr(task.DueAt),
+		Recurrence:  task.Recurrence,
+		ParentID:    clonePtr(task.ParentID),
+		Assignee:    task.Assignee,
+		Watchers:    slices.Clone(task.Watchers),
+	}
+	for _, item := range task.Checklist {
+		copied.Checklist = append(copied.Checklist, ChecklistItem{Text: item.Text})
+	}
+	return copied
+}
+
+// clonePtr Копия значения по указателю (nil остаётся nil)
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// sameParent Копия осталась под тем же родителем, что и исходная задача
+func sameParent(a, b *int) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// duplicateHandler Обработчик эндпоинта /todos/{id}/duplicate. Необязательное тело переопределяет поля копии
+func duplicateHandler(ts Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		source, err := GetTaskCtx(r.Context(), ts, id)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Getting task", err, "task_id", id)
+			writeJSONError(w, updateErrorStatus(err), err.Error())
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Reading body", err, "task_id", id)
+			writeDecodeError(w, err)
+			return
+		}
+		if !utf8.Valid(body) { // encoding/json молча заменил бы некорректные байты на U+FFFD
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Invalid UTF-8 in body", nil, "task_id", id)
+			writeJSONError(w, http.StatusBadRequest, "request body must be valid UTF-8")
+			return
+		}
+		t := duplicateTask(source)
+		if len(bytes.TrimSpace(body)) > 0 { // переданные поля заменяют скопированные
+			if err := json.Unmarshal(body, &t); err != nil {
+				logRequest(r, slog.LevelWarn, "[duplicateHandler] Decoding", err, "task_id", id)
+				writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+				return
+			}
+		}
+		t.ID = 0 // ID назначает хранилище
+		t.Preprocess()
+		if err := t.Validate(validationConfig(ts)); err != nil {
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Validation", err, "task_id", id)
+			writeValidationError(w, validationConfig(ts), err)
+			return
+		}
+		created, err := AddTaskCtx(r.Context(), ts, t)
+		if err != nil && errors.Is(err, ErrInvalidParent) && sameParent(t.ParentID, source.ParentID) {
+			// родитель исходной задачи не принимает новые подзадачи (например, уже завершён): дело не в запросе
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Parent rejects the copy", err, "task_id", id)
+			writeJSONError(w, http.StatusConflict, fmt.Sprintf(
+				"cannot duplicate task %d under its parent: %v; pass parent_id in the body (null for a top-level copy)", id, err))
+			return
+		}
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[duplicateHandler] Creating task", err, "task_id", id)
+			writeJSONError(w, createErrorStatus(err), err.Error())
+			return
+		}
+		// /todos/{id}/duplicate -> /todos/{новый id} с сохранением префикса версии и тенанта
+		location := strings.TrimSuffix(r.URL.Path, "/"+strconv.Itoa(id)+"/duplicate") + "/" + strconv.Itoa(created.ID)
+		w.Header().Set("Location", location)
+		if err := writeMutationResult(w, r, http.StatusCreated, created, location, true); err != nil {
+			logRequest(r, slog.LevelError, "[duplicateHandler] Encoding task", err, "task_id", id)
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка копирования задачи
+// Сценарий:
+// 1. Создать задачу в статусе in progress с выполненным пунктом чек-листа.
+// 2. Скопировать её без тела - ожидаем 201, новый ID, статус not started, заголовок с " (copy)",
+// сброшенный чек-лист и Location на копию.
+// 3. Скопировать с телом {"title":"Other"} - ожидаем копию с заголовком Other.
+// 4. Скопировать несуществующую задачу - ожидаем 404 Not Found.
+func TestDuplicateTask(t *testing.T) {
+	ds := NewTaskStore()
+	if _, err := ds.AddTask(Task{Title: "Report", Description: "Q3", Status: StatusInProgress,
+		Checklist: []ChecklistItem{{Text: "Draft", Done: true}}}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	duplicate := func(id, body string) (Task, *http.Response) {
+		resp, err := http.Post(ts.URL+"/todos/"+id+"/duplicate", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var task Task
+		if resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return task, resp
+	}
+	// Копия без переопределений
+	copied, resp := duplicate("1", "")
+	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if copied.ID != 2 || copied.Title != "Report (copy)" || copied.Description != "Q3" || copied.Status != StatusNotStarted {
+		t.Errorf("unexpected copy %+v", copied)
+	}
+	if len(copied.Checklist) != 1 || copied.ChecklistDone != 0 { // чек-лист НЕ сброшен
+		t.Errorf("expected reset checklist, got %+v", copied.Checklist)
+	}
+	if location := resp.Header.Get("Location"); location != "/todos/2" {
+		t.Errorf("expected Location /todos/2, got %q", location)
+	}
+	// Копия с переопределением
+	if copied, _ := duplicate("1", `{"title":"Other"}`); copied.Title != "Other" || copied.Description != "Q3" {
+		t.Errorf("expected overridden title with copied description, got %+v", copied)
+	}
+	// Несуществующая задача
+	if _, resp := duplicate("99", ""); resp.StatusCode != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка копирования подзадачи завершённого родителя
+// Сценарий:
+// 1. Создать завершённую задачу с завершённой подзадачей.
+// 2. Скопировать подзадачу без тела - ожидаем ошибку (409 Conflict): копия not started не может быть под завершённым родителем.
+// 3. Скопировать с телом {"parent_id":null} - ожидаем успех (201 Created) и копию верхнего уровня.
+// 4. Скопировать с телом {"parent_id":99} - ожидаем ошибку запроса (400 Bad Request).
+// 5. Получить исходную подзадачу - ожидаем, что её parent_id не изменился.
+func TestDuplicateSubtaskOfCompletedParent(t *testing.T) {
+	ds := NewTaskStore()
+	if _, err := ds.AddTask(Task{Title: "Parent", Status: StatusInProgress}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	parentID := 1
+	if _, err := ds.AddTask(Task{Title: "Child", Status: StatusCompleted, ParentID: &parentID}); err != nil {
+		t.Fatalf("failed to add subtask: %v", err)
+	}
+	if _, err := ds.UpdateTask(1, Task{Title: "Parent", Status: StatusCompleted}); err != nil {
+		t.Fatalf("failed to complete parent: %v", err)
+	}
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	duplicate := func(body string) (Task, int) {
+		resp, err := http.Post(ts.URL+"/todos/2/duplicate", "application/json", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var task Task
+		if resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return task, resp.StatusCode
+	}
+	// Под завершённым родителем
+	if _, status := duplicate(""); status != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409, got %d", status)
+	}
+	// Копия верхнего уровня
+	copied, status := duplicate(`{"parent_id":null}`)
+	if status != http.StatusCreated { // получили НЕ 201
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if copied.ParentID != nil || copied.Title != "Child (copy)" {
+		t.Errorf("expected top-level copy, got %+v", copied)
+	}
+	// Несуществующий родитель из тела - ошибка запроса
+	if _, status := duplicate(`{"parent_id":99}`); status != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", status)
+	}
+	// Тело декодируется поверх копии и не меняет исходную задачу
+	if source, err := ds.GetTask(2); err != nil || source.ParentID == nil || *source.ParentID != parentID {
+		t.Errorf("expected source to keep parent %d, got %+v (%v)", parentID, source, err)
+	}
+}
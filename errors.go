@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrEmptyBody Ошибка: тело запроса не передано (или состоит из одних пробелов)
+var ErrEmptyBody = errors.New("request body is required")
+
+// ErrorResponse Тело ответа с ошибкой
+type ErrorResponse struct {
+	Error        string       `json:"error"`
+	Status       int          `json:"status"`
+	Index        *int         `json:"index,omitempty"`         // Индекс элемента пакета, на котором произошла ошибка
+	RequiredRole string       `json:"required_role,omitempty"` // Роль, которой не хватило для запроса (для 403)
+	Errors       []FieldError `json:"errors,omitempty"`        // Все проблемы валидации (для 422)
+	Dependency   string       `json:"dependency,omitempty"`    // Зависимость, не прошедшая проверку (для 503 на /readyz)
+}
+
+// writeJSONError Ответ с ошибкой в формате JSON: {"error":"...","status":N}
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeErrorResponse(w, ErrorResponse{Error: message, Status: status})
+}
+
+// writeErrorResponse Ответ с заранее собранным телом ошибки
+func writeErrorResponse(w http.ResponseWriter, response ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(response.Status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("[writeErrorResponse] Encoding error", "error", err)
+	}
+}
+
+// defaultRetryAfter Через сколько клиенту повторять запрос после ответа 503 о перегрузке или неготовности сервера
+const defaultRetryAfter = 5 * time.Second
+
+// setRetryAfter Выставляет заголовок Retry-After в целых секундах (с округлением вверх, не меньше секунды).
+// Вызывается на всех путях, отвечающих 503, чтобы клиенты и балансировщики выдерживали паузу перед повтором
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	seconds := max(int64(math.Ceil(d.Seconds())), 1)
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
+
+// readBody Чтение обязательного тела запроса (ErrEmptyBody, если тела нет)
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ErrEmptyBody
+	}
+	return body, nil
+}
+
+// writeDecodeError Ответ на ошибку чтения тела запроса: 413, если тело больше лимита, иначе 400
+// (с отдельным сообщением для пустого тела)
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	if errors.Is(err, ErrEmptyBody) {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSONError(w, http.StatusBadRequest, "invalid JSON")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrVersionMismatch Ошибка: задача изменилась с момента, когда клиент её получил (If-Match не совпал)
+var ErrVersionMismatch = errors.New("task version mismatch")
+
+// taskETag Значение заголовка ETag для задачи (строгий тег из номера версии)
+func taskETag(task Task) string {
+	return strconv.Quote(strconv.Itoa(task.Version))
+}
+
+// collectionETag Значение заголовка ETag для списка задач: хеш отсортированных пар ID+версия
+// и общего числа задач (страница может не измениться, когда меняется число задач вне её)
+func collectionETag(tasks []Task, total int) string {
+	pairs := make([][2]int, len(tasks))
+	for i, task := range tasks {
+		pairs[i] = [2]int{task.ID, task.Version}
+	}
+	slices.SortFunc(pairs, func(a, b [2]int) int { return cmp.Compare(a[0], b[0]) })
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d;", total)
+	for _, pair := range pairs {
+		fmt.Fprintf(hash, "%d:%d;", pair[0], pair[1])
+	}
+	return strconv.Quote(hex.EncodeToString(hash.Sum(nil)[:16]))
+}
+
+// etagMatches Проверка заголовка If-None-Match (список тегов через запятую или "*", сравнение слабое)
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// lastModified Время последнего изменения списка задач (нулевое для пустого списка)
+func lastModified(tasks []Task) time.Time {
+	var newest time.Time
+	for _, task := range tasks {
+		if task.UpdatedAt.After(newest) {
+			newest = task.UpdatedAt
+		}
+	}
+	return newest
+}
+
+// modifiedSince Проверка заголовка If-Modified-Since (некорректное значение игнорируется - ответ отдаётся полностью)
+func modifiedSince(header string, modified time.Time) bool {
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return true
+	}
+	return modified.Truncate(time.Second).After(since) // в заголовке точность до секунды
+}
+
+// notModified Выставляет ETag и Last-Modified и, если ресурс не изменился, отвечает 304 Not Modified без тела.
+// If-None-Match приоритетнее If-Modified-Since (RFC 9110, 13.2.2)
+func notModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		if !etagMatches(header, etag) {
+			return false
+		}
+	} else if header := r.Header.Get("If-Modified-Since"); header == "" || modified.IsZero() || modifiedSince(header, modified) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// ifMatchVersion Ожидаемая версия задачи из заголовка If-Match:
+// 0 - заголовка нет или передан "*", -1 - тег не распознан (с ним не совпадёт ни одна версия)
+func ifMatchVersion(r *http.Request) int {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0
+	}
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version <= 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return -1
+	}
+	return version
+}
+
+// checkVersion Проверка ожидаемой версии задачи (0 означает "любая версия")
+func checkVersion(task Task, expected int) error {
+	if expected != 0 && expected != task.Version {
+		return fmt.Errorf("%w: expected %s, current %s", ErrVersionMismatch, strconv.Quote(strconv.Itoa(expected)), taskETag(task))
+	}
+	return nil
+}
+
+// requireIfMatchMiddleware Middleware, отклоняющее PUT/PATCH/DELETE без заголовка If-Match (428 Precondition Required).
+// Безусловное изменение по-прежнему возможно, но только явно: If-Match: *
+func requireIfMatchMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		write := r.Method == http.MethodPut || r.Method == http.MethodPatch || r.Method == http.MethodDelete
+		if write && r.Header.Get("If-Match") == "" {
+			logRequest(r, slog.LevelWarn, "[requireIfMatchMiddleware] Missing If-Match", nil)
+			writeJSONError(w, http.StatusPreconditionRequired, "If-Match header is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
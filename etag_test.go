@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Проверка оптимистичной блокировки через ETag и If-Match
+// Сценарий:
+// 1. Создать задачу и получить её - ожидаем ETag "1".
+// 2. Обновить задачу с If-Match: "1" - ожидаем успех (200 OK) и ETag "2".
+// 3. Повторить обновление с устаревшим If-Match: "1" - ожидаем ошибку (412 Precondition Failed).
+// 4. Изменить прогресс с устаревшим If-Match - ожидаем 412, без If-Match - успех (200 OK).
+// 5. При обязательном If-Match обновить задачу без заголовка - ожидаем ошибку (428 Precondition Required).
+func TestETagIfMatch(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	do := func(method, path, ifMatch string, v any) *http.Response {
+		var body []byte
+		if v != nil {
+			body, _ = json.Marshal(v)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp
+	}
+	// Создаём и получаем задачу
+	do(http.MethodPost, "/todos", "", Task{Title: "Versioned", Status: StatusNotStarted})
+	resp := do(http.MethodGet, "/todos/1", "", nil)
+	if got := resp.Header.Get("ETag"); got != `"1"` { // ETag НЕ соответствует первой версии
+		t.Fatalf(`expected ETag "1", got %q`, got)
+	}
+	// Обновляем с актуальным If-Match
+	update := Task{Title: "Versioned", Status: StatusInProgress}
+	resp = do(http.MethodPut, "/todos/1", `"1"`, update)
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got != `"2"` { // версия НЕ увеличилась
+		t.Errorf(`expected ETag "2", got %q`, got)
+	}
+	// Обновляем с устаревшим If-Match
+	if resp = do(http.MethodPut, "/todos/1", `"1"`, update); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 on stale PUT, got %d", resp.StatusCode)
+	}
+	progress := 50
+	if resp = do(http.MethodPatch, "/todos/1/progress", `"1"`, ProgressUpdate{Progress: &progress}); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 on stale PATCH, got %d", resp.StatusCode)
+	}
+	// Без If-Match обновление проходит
+	if resp = do(http.MethodPatch, "/todos/1/progress", "", ProgressUpdate{Progress: &progress}); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 without If-Match, got %d", resp.StatusCode)
+	}
+	// Обязательный If-Match
+	strict := httptest.NewServer(requireIfMatchMiddleware(newMux(NewTaskStore(), NewTenantRegistry(DefaultStoreConfig()), NewStats(), new(atomic.Bool))))
+	defer strict.Close()
+	body, _ := json.Marshal(update)
+	req, _ := http.NewRequest(http.MethodPut, strict.URL+"/todos/1", bytes.NewBuffer(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusPreconditionRequired { // получили НЕ 428
+		t.Errorf("expected 428 without If-Match, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка If-Match для DELETE
+// Сценарий:
+// 1. Включить обязательный If-Match и удалить задачу без заголовка - ожидаем ошибку (428 Precondition Required).
+// 2. Удалить задачу с устаревшей версией - ожидаем ошибку (412 Precondition Failed), задача не удалена.
+// 3. Удалить задачу с текущей версией - ожидаем успех (204 No Content).
+// 4. Удалить задачи пакетом с If-Match: * - ожидаем, что заголовок считается переданным (не 428).
+func TestIfMatchDelete(t *testing.T) {
+	ds := NewTaskStore()
+	strict := httptest.NewServer(requireIfMatchMiddleware(newMux(ds, NewTenantRegistry(DefaultStoreConfig()), NewStats(), new(atomic.Bool))))
+	defer strict.Close()
+	for range 2 {
+		if _, err := ds.AddTask(Task{Title: "Guarded", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+
+	del := func(path, ifMatch, body string) int {
+		req, _ := http.NewRequest(http.MethodDelete, strict.URL+path, bytes.NewBufferString(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make DELETE: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode
+	}
+	if status := del("/todos/1", "", ""); status != http.StatusPreconditionRequired { // получили НЕ 428
+		t.Errorf("expected 428 without If-Match, got %d", status)
+	}
+	if status := del("/todos/1", `"5"`, ""); status != http.StatusPreconditionFailed { // получили НЕ 412
+		t.Errorf("expected 412 on stale DELETE, got %d", status)
+	}
+	if _, err := ds.GetTask(1); err != nil { // задача удалена несмотря на 412
+		t.Errorf("expected task 1 to survive stale DELETE: %v", err)
+	}
+	if status := del("/todos/1", `"1"`, ""); status != http.StatusNoContent { // получили НЕ 204
+		t.Errorf("expected 204 with current If-Match, got %d", status)
+	}
+	if status := del("/todos", "*", `{"ids":[2]}`); status != http.StatusOK && status != http.StatusNoContent {
+		t.Errorf("expected bulk DELETE with If-Match: * to pass, got %d", status)
+	}
+}
+
+// Проверка условного GET через If-None-Match
+// Сценарий:
+// 1. Создать задачу, получить её и список - ожидаем ETag у обоих ответов.
+// 2. Повторить запросы с If-None-Match - ожидаем 304 Not Modified без тела.
+// 3. Изменить задачу - ожидаем, что старые теги больше не совпадают (200 OK).
+// 4. Создать ещё одну задачу - ожидаем, что тег первой страницы списка изменился.
+func TestIfNoneMatch(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	get := func(path, ifNoneMatch string) (*http.Response, []byte) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var body bytes.Buffer
+		if _, err := body.ReadFrom(resp.Body); err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, body.Bytes()
+	}
+	create := func(title string) {
+		body, _ := json.Marshal(Task{Title: title, Status: StatusNotStarted})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+
+	// Получаем задачу и список
+	create("Polled")
+	resp, _ := get("/todos/1", "")
+	taskTag := resp.Header.Get("ETag")
+	resp, _ = get("/todos?limit=1", "")
+	listTag := resp.Header.Get("ETag")
+	if taskTag == "" || listTag == "" { // ETag НЕ выставлен
+		t.Fatalf("expected ETag on task and list, got %q and %q", taskTag, listTag)
+	}
+	// Повторяем с If-None-Match
+	for path, tag := range map[string]string{"/todos/1": taskTag, "/todos?limit=1": "W/" + listTag} {
+		resp, body := get(path, tag)
+		if resp.StatusCode != http.StatusNotModified { // получили НЕ 304
+			t.Errorf("%s: expected 304, got %d", path, resp.StatusCode)
+		}
+		if len(body) != 0 { // у 304 есть тело
+			t.Errorf("%s: expected empty body, got %q", path, body)
+		}
+	}
+	// Изменяем задачу
+	body, _ := json.Marshal(Task{Title: "Polled", Status: StatusInProgress})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	for path, tag := range map[string]string{"/todos/1": taskTag, "/todos?limit=1": listTag} {
+		if resp, _ := get(path, tag); resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Errorf("%s: expected 200 after update, got %d", path, resp.StatusCode)
+		}
+	}
+	// Новая задача вне первой страницы меняет тег списка
+	resp, _ = get("/todos?limit=1", "")
+	listTag = resp.Header.Get("ETag")
+	create("Another")
+	if resp, _ := get("/todos?limit=1", listTag); resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Errorf("expected 200 after create, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка условного GET через If-Modified-Since
+// Сценарий:
+// 1. Создать задачу, получить её и список - ожидаем Last-Modified у обоих ответов.
+// 2. Повторить запросы с If-Modified-Since, равным Last-Modified, - ожидаем 304 Not Modified.
+// 3. Повторить с более ранним временем - ожидаем 200 OK.
+// 4. Повторить с некорректным значением - ожидаем, что заголовок проигнорирован (200 OK).
+// 5. Передать совпадающий If-Modified-Since вместе с несовпадающим If-None-Match - ожидаем 200 OK.
+// 6. Передать совпадающий If-None-Match вместе с устаревшим If-Modified-Since - ожидаем 304 Not Modified.
+// 7. Запросить без условных заголовков - ожидаем 200 OK.
+func TestIfModifiedSince(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	get := func(path string, headers map[string]string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp
+	}
+
+	// Создаём задачу
+	body, _ := json.Marshal(Task{Title: "Polled", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	for _, path := range []string{"/todos/1", "/todos"} {
+		first := get(path, nil)
+		modified, etag := first.Header.Get("Last-Modified"), first.Header.Get("ETag")
+		if etag == "" { // ETag НЕ выставлен
+			t.Fatalf("%s: expected ETag", path)
+		}
+		since, err := http.ParseTime(modified)
+		if err != nil { // Last-Modified НЕ выставлен или некорректен
+			t.Fatalf("%s: invalid Last-Modified %q: %v", path, modified, err)
+		}
+		tests := []struct {
+			name    string
+			headers map[string]string
+			status  int
+		}{
+			{"same time", map[string]string{"If-Modified-Since": modified}, http.StatusNotModified},
+			{"earlier time", map[string]string{"If-Modified-Since": since.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+			{"malformed", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+			{"etag wins", map[string]string{"If-Modified-Since": modified, "If-None-Match": `"stale"`}, http.StatusOK},
+			{"etag matches", map[string]string{"If-Modified-Since": since.Add(-time.Hour).Format(http.TimeFormat), "If-None-Match": etag}, http.StatusNotModified},
+			{"no conditions", nil, http.StatusOK},
+		}
+		for _, tt := range tests {
+			if resp := get(path, tt.headers); resp.StatusCode != tt.status { // неожиданный статус
+				t.Errorf("%s %s: expected %d, got %d", path, tt.name, tt.status, resp.StatusCode)
+			}
+		}
+	}
+}
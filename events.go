@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TaskEventType Тип изменения задачи
+type TaskEventType string
+
+const (
+	TaskEventCreated  TaskEventType = "created"
+	TaskEventUpdated  TaskEventType = "updated"
+	TaskEventDeleted  TaskEventType = "deleted"
+	TaskEventRestored TaskEventType = "restored"
+	TaskEventEvicted  TaskEventType = "evicted"  // задача окончательно удалена при переполнении хранилища
+	TaskEventReverted TaskEventType = "reverted" // последнее изменение задачи отменено
+)
+
+// TaskEvent Событие изменения задачи
+type TaskEvent struct {
+	Type   TaskEventType
+	Task   Task
+	Tenant string // Тенант хранилища, в котором изменилась задача (пусто - общее пространство)
+}
+
+// subscriberBuffer Размер буфера канала подписчика (при переполнении события для него теряются)
+const subscriberBuffer = 64
+
+// eventsKeepAlive Интервал комментариев-пингов в потоке событий (чтобы прокси не закрывали соединение)
+const eventsKeepAlive = 15 * time.Second
+
+// Subscribe Подписка на изменения задач. Возвращает канал событий и функцию отписки
+func (ds *TaskStore) Subscribe() (<-chan TaskEvent, func()) {
+	ch := make(chan TaskEvent, subscriberBuffer)
+	ds.subMutex.Lock()
+	ds.subscribers[ch] = struct{}{}
+	ds.subMutex.Unlock()
+	return ch, func() {
+		ds.subMutex.Lock()
+		delete(ds.subscribers, ch)
+		ds.subMutex.Unlock()
+	}
+}
+
+// publish Рассылка события подписчикам и в общий канал уведомлений без ожидания
+// (вызывается под блокировкой, чтобы сохранить порядок событий)
+func (ds *TaskStore) publish(eventType TaskEventType, task Task) {
+	event := TaskEvent{Type: eventType, Task: task, Tenant: ds.tenant}
+	ds.subMutex.Lock()
+	defer ds.subMutex.Unlock()
+	for ch := range ds.subscribers {
+		select {
+		case ch <- event:
+		default: // подписчик не успевает читать
+			slog.Warn("[publish] Dropping event for slow subscriber", "task_id", task.ID, "event", eventType)
+		}
+	}
+	if ds.config.Notify == nil {
+		return
+	}
+	select {
+	case ds.config.Notify <- event:
+	default: // рассылка уведомлений не успевает
+		slog.Warn("[publish] Dropping event for notifications", "tenant", ds.tenant, "task_id", task.ID, "event", eventType)
+	}
+}
+
+// eventsHandler Обработчик эндпоинта /todos/events (Server-Sent Events с изменениями задач).
+// С ?watcher= в поток попадают только события задач, у которых этот наблюдатель; X-Fields маскирует данные событий
+func eventsHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[eventsHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		watcher := strings.TrimSpace(r.URL.Query().Get("watcher"))
+		if r.URL.Query().Has("watcher") {
+			if err := validateWatcher(watcher); err != nil {
+				logRequest(r, slog.LevelWarn, "[eventsHandler] Invalid watcher", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		fields := requestedFields(r)
+		events, unsubscribe := ts.Subscribe()
+		defer unsubscribe()
+
+		rc := http.NewResponseController(w)
+		// поток живёт дольше таймаутов сервера: снимаем их для этого соединения
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			logRequest(r, slog.LevelDebug, "[eventsHandler] Clearing write deadline", err)
+		}
+		if err := rc.SetReadDeadline(time.Time{}); err != nil {
+			logRequest(r, slog.LevelDebug, "[eventsHandler] Clearing read deadline", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			logRequest(r, slog.LevelError, "[eventsHandler] Streaming is not supported", err)
+			return
+		}
+		keepAlive := time.NewTicker(eventsKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-r.Context().Done(): // клиент отключился
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case event := <-events:
+				if watcher != "" && !slices.Contains(event.Task.Watchers, watcher) { // событие не для этого наблюдателя
+					continue
+				}
+				data, err := marshalMasked(event.Task, fields)
+				if err != nil {
+					logRequest(r, slog.LevelError, "[eventsHandler] Encoding task", err, "task_id", event.Task.ID)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Проверка потока событий об изменениях задач
+// Сценарий:
+// 1. Подключиться к /todos/events - ожидаем Content-Type text/event-stream.
+// 2. Создать задачу - ожидаем событие created с этой задачей.
+// 3. Удалить задачу - ожидаем событие deleted.
+// 4. Закрыть соединение - ожидаем, что подписка будет снята.
+func TestTaskEvents(t *testing.T) {
+	store := NewTaskStore()
+	ts := startTestServerWithStore(store)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/todos/events")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" { // НЕ поток событий
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	// readEvent Чтение одного события (тип и данные)
+	readEvent := func() (string, Task) {
+		var eventType string
+		var task Task
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return eventType, task
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &task); err != nil {
+					t.Fatalf("failed to decode event data: %v", err)
+				}
+			}
+		}
+	}
+
+	// Создаём задачу
+	body, _ := json.Marshal(Task{Title: "Streamed", Status: StatusNotStarted})
+	created, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := created.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	eventType, task := readEvent()
+	if eventType != "created" || task.Title != "Streamed" { // событие создания НЕ получено
+		t.Errorf("expected created event for Streamed, got %q %+v", eventType, task)
+	}
+	// Удаляем задачу
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
+	deleted, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make DELETE: %v", err)
+	}
+	if err := deleted.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	eventType, task = readEvent()
+	if eventType != "deleted" || task.ID != 1 { // событие удаления НЕ получено
+		t.Errorf("expected deleted event for task 1, got %q %+v", eventType, task)
+	}
+	// Отключаемся и ждём снятия подписки
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		store.subMutex.Lock()
+		remaining := len(store.subscribers)
+		store.subMutex.Unlock()
+		if remaining == 0 {
+			break
+		}
+		if time.Now().After(deadline) { // подписка осталась после отключения
+			t.Fatalf("expected subscriber to be removed, %d remaining", remaining)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Проверка маски X-Fields в потоке событий
+// Сценарий:
+// 1. Подключиться к /todos/events с X-Fields: id,title.
+// 2. Создать задачу - ожидаем в данных события только id и title.
+func TestTaskEventsFields(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/todos/events", nil)
+	req.Header.Set("X-Fields", "id,title")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := json.Marshal(Task{Title: "Streamed", Status: StatusNotStarted})
+	created, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := created.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		data, ok := strings.CutPrefix(strings.TrimSuffix(line, "\n"), "data: ")
+		if !ok {
+			continue
+		}
+		var task map[string]any
+		if err := json.Unmarshal([]byte(data), &task); err != nil {
+			t.Fatalf("failed to decode event data: %v", err)
+		}
+		if len(task) != 2 || task["title"] != "Streamed" || task["id"] == nil { // маска НЕ применена
+			t.Errorf("expected only id and title, got %v", task)
+		}
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// csvHeader Колонки CSV-выгрузки задач
+var csvHeader = []string{"id", "title", "description", "status", "created_at", "due_at"}
+
+// csvRecord Строка CSV-выгрузки для задачи (время - RFC3339, пустой due_at - срок не задан)
+func csvRecord(task Task) []string {
+	dueAt := ""
+	if task.DueAt != nil {
+		dueAt = task.DueAt.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.Itoa(task.ID), task.Title, task.Description, string(task.Status),
+		task.CreatedAt.Format(time.RFC3339), dueAt,
+	}
+}
+
+// exportHandler Обработчик эндпоинта /todos/export: все задачи в формате ?format=json (по умолчанию) или csv
+func exportHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[exportHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			err := fmt.Errorf("unknown format %q: must be json or csv", format)
+			logRequest(r, slog.LevelWarn, "[exportHandler] Invalid format", err)
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		tasks, err := GetAllTasksCtx(r.Context(), ts)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[exportHandler] Listing tasks", err)
+			writeJSONError(w, updateErrorStatus(err), err.Error())
+			return
+		}
+		if err := SortTasks(tasks, "id", false); err != nil {
+			logRequest(r, slog.LevelError, "[exportHandler] Sorting tasks", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		switch format {
+		case "": // формат не задан - по заголовку Accept
+			if err := writeTaskJSON(w, r, http.StatusOK, tasks); err != nil {
+				logRequest(r, slog.LevelError, "[exportHandler] Encoding tasks", err)
+			}
+			return
+		case "json": // явный ?format= важнее Accept
+			w.Header().Add("Vary", "X-Fields")
+			if err := writeTasksAs(w, r, http.StatusOK, mediaJSON, tasks); err != nil {
+				logRequest(r, slog.LevelError, "[exportHandler] Encoding tasks", err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="tasks.csv"`)
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			logRequest(r, slog.LevelError, "[exportHandler] Writing CSV", err)
+			return
+		}
+		for _, task := range tasks {
+			if err := writer.Write(csvRecord(task)); err != nil {
+				logRequest(r, slog.LevelError, "[exportHandler] Writing CSV", err)
+				return
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			logRequest(r, slog.LevelError, "[exportHandler] Writing CSV", err)
+		}
+	}
+}
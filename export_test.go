@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка выгрузки задач в CSV
+// Сценарий:
+// 1. Создать задачи, в описании одной из них запятые и кавычки.
+// 2. Выгрузить их с ?format=csv - ожидаем заголовок Content-Disposition, строку заголовков и корректно
+// экранированные поля, которые читаются обратно без потерь.
+// 3. Выгрузить с неизвестным форматом - ожидаем ошибку (400 Bad Request).
+func TestExportCSV(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём задачи
+	tricky := `Купить "молоко", хлеб, сыр`
+	for _, task := range []Task{
+		{Title: "Покупки", Description: tricky, Status: StatusNotStarted},
+		{Title: "Отчёт", Status: StatusInProgress},
+	} {
+		body, _ := json.Marshal(task)
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Выгружаем CSV
+	resp, err := http.Get(ts.URL + "/todos/export?format=csv")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="tasks.csv"` {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+	if len(records) != 3 || records[0][0] != "id" { // НЕТ строки заголовков или задач
+		t.Fatalf("expected header and 2 rows, got %v", records)
+	}
+	if records[1][2] != tricky || records[2][3] != string(StatusInProgress) { // поля искажены при экранировании
+		t.Errorf("unexpected rows %v", records[1:])
+	}
+	// Неизвестный формат
+	resp, err = http.Get(ts.URL + "/todos/export?format=xml")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for unknown format, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка приоритета ?format= над заголовком Accept
+// Сценарий:
+// 1. Создать задачу.
+// 2. Выгрузить с ?format=json и Accept: application/xml - ожидаем JSON-массив.
+// 3. Выгрузить без ?format= с Accept: application/xml - ожидаем XML.
+func TestExportFormatOverridesAccept(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	body, _ := json.Marshal(Task{Title: "Export", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	for path, want := range map[string]string{
+		"/todos/export?format=json": "application/json",
+		"/todos/export":             "application/xml",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("Accept", "application/xml")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Errorf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Type"); got != want { // формат выбран НЕ тот
+			t.Errorf("GET %s: expected %s, got %q", path, want, got)
+		}
+		if want == "application/json" {
+			var tasks []Task
+			if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil || len(tasks) != 1 {
+				t.Errorf("GET %s: expected JSON array with one task, got %v (%v)", path, tasks, err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// requestedFields Маска полей задачи для ответа: заголовок X-Fields (список полей через запятую) без полей,
+// которых нет в выбранной версии схемы. nil означает "все поля"
+func requestedFields(r *http.Request) map[string]bool {
+	fields := headerFields(r)
+	if version, err := schemaVersion(r); err != nil || version != schemaV1 {
+		return fields
+	}
+	if fields == nil {
+		fields = make(map[string]bool, len(taskFieldNames))
+		for _, name := range taskFieldNames {
+			fields[name] = true
+		}
+	}
+	for _, name := range v1OmittedFields {
+		delete(fields, name)
+	}
+	return fields
+}
+
+// headerFields Разбор заголовка X-Fields (список полей через запятую). nil означает "все поля"
+func headerFields(r *http.Request) map[string]bool {
+	header := strings.TrimSpace(r.Header.Get("X-Fields"))
+	if header == "" {
+		return nil
+	}
+	fields := make(map[string]bool)
+	for _, name := range strings.Split(header, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields[name] = true
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// maskObject Оставляет в JSON-объекте только запрошенные поля (неизвестные поля игнорируются)
+func maskObject(object map[string]json.RawMessage, fields map[string]bool) {
+	for name := range object {
+		if !fields[name] {
+			delete(object, name)
+		}
+	}
+}
+
+// maskFields Применение маски полей к задаче или списку задач
+func maskFields(v any, fields map[string]bool) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[0] == '[' { // список задач
+		var list []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		for _, object := range list {
+			maskObject(object, fields)
+		}
+		return list, nil
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	maskObject(object, fields)
+	return object, nil
+}
+
+// marshalMasked Сериализация задачи в JSON с маской полей (nil - все поля)
+func marshalMasked(task Task, fields map[string]bool) ([]byte, error) {
+	if fields == nil {
+		return json.Marshal(task)
+	}
+	masked, err := maskFields(task, fields)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(masked)
+}
+
+// maskHistory Применение маски полей к состояниям задачи (before и after) в записях журнала
+func maskHistory(entries []AuditEntry, fields map[string]bool) (any, error) {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	for _, object := range list {
+		for _, key := range []string{"before", "after"} {
+			state, ok := object[key]
+			if !ok {
+				continue
+			}
+			masked, err := maskFields(state, fields)
+			if err != nil {
+				return nil, err
+			}
+			if object[key], err = json.Marshal(masked); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return list, nil
+}
+
+// writeTaskJSON Сериализация задачи или списка задач в ответ с учётом заголовка X-Fields.
+// Если клиент предпочёл XML (Accept), ответ отдаётся в XML с той же маской полей.
+// На HEAD отдаются только заголовки, Content-Length - как у соответствующего GET
+func writeTaskJSON(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	w.Header().Add("Vary", "Accept, X-Fields") // тело зависит от формата и маски полей, общий кэш должен их различать
+	media, _ := negotiateMedia(r)
+	return writeTasksAs(w, r, status, media, v)
+}
+
+// writeTasksAs Сериализация задачи или списка задач в заданном формате (mediaJSON или mediaXML) с учётом X-Fields,
+// когда формат выбран без заголовка Accept
+func writeTasksAs(w http.ResponseWriter, r *http.Request, status int, media string, v any) error {
+	fields := requestedFields(r)
+	if media == mediaXML {
+		var body bytes.Buffer
+		if err := encodeXML(&body, v, fields); err != nil {
+			return err
+		}
+		return writeBody(w, r, status, mediaXML, body.Bytes())
+	}
+	if fields != nil {
+		masked, err := maskFields(v, fields)
+		if err != nil {
+			return err
+		}
+		v = masked
+	}
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		return err
+	}
+	return writeBody(w, r, status, mediaJSON, body.Bytes())
+}
+
+// writeBody Ответ с готовым телом (на HEAD - только заголовки)
+func writeBody(w http.ResponseWriter, r *http.Request, status int, contentType string, body []byte) error {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return nil
+	}
+	_, err := w.Write(body)
+	return err
+}
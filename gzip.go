@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// gzipMinSize Минимальный размер ответа, начиная с которого он сжимается
+const gzipMinSize = 1024
+
+// acceptsGzip Проверяет, что клиент принимает gzip (Accept-Encoding: gzip без q=0)
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			if name = strings.TrimSpace(name); name != "gzip" && name != "*" {
+				continue
+			}
+			q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !found {
+				return true
+			}
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compressible Проверяет, что ответ с таким Content-Type имеет смысл сжимать
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "text/") && mediaType != "text/event-stream")
+}
+
+// gzipResponseWriter Обёртка над http.ResponseWriter, сжимающая ответ, когда он набирает gzipMinSize байт
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool         // обработчик выставил код ответа
+	committed   bool         // заголовки отправлены клиенту
+	buf         bytes.Buffer // начало тела, пока не ясно, сжимать ли ответ
+	gz          *gzip.Writer // nil, если ответ отдаётся без сжатия
+}
+
+// WriteHeader Запоминает код ответа до решения о сжатии
+func (gw *gzipResponseWriter) WriteHeader(status int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.status = status
+	gw.wroteHeader = true
+}
+
+// Write Копит тело ответа до gzipMinSize байт, затем начинает сжатие
+func (gw *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !gw.wroteHeader {
+		gw.WriteHeader(http.StatusOK)
+	}
+	if gw.committed {
+		if gw.gz != nil {
+			return gw.gz.Write(b)
+		}
+		return gw.ResponseWriter.Write(b)
+	}
+	gw.buf.Write(b)
+	if gw.buf.Len() >= gzipMinSize {
+		if err := gw.commit(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// commit Отправляет заголовки и накопленное тело, сжимая его, если ответ достаточно большой
+func (gw *gzipResponseWriter) commit() error {
+	gw.committed = true
+	header := gw.Header()
+	if gw.buf.Len() >= gzipMinSize && header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	if gw.wroteHeader {
+		gw.ResponseWriter.WriteHeader(gw.status)
+	}
+	if gw.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(gw.buf.Bytes())
+	} else {
+		_, err = gw.ResponseWriter.Write(gw.buf.Bytes())
+	}
+	gw.buf.Reset()
+	return err
+}
+
+// Flush Отправляет клиенту уже записанную часть ответа
+func (gw *gzipResponseWriter) Flush() {
+	if !gw.committed {
+		_ = gw.commit()
+	}
+	if gw.gz != nil {
+		_ = gw.gz.Flush()
+	}
+	_ = http.NewResponseController(gw.ResponseWriter).Flush()
+}
+
+// Close Завершает ответ: отправляет остаток тела и закрывает gzip-поток
+func (gw *gzipResponseWriter) Close() error {
+	if !gw.committed {
+		if err := gw.commit(); err != nil {
+			return err
+		}
+	}
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
+
+// Unwrap Доступ к исходному http.ResponseWriter (для http.ResponseController)
+func (gw *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return gw.ResponseWriter
+}
+
+// gzipMiddleware Middleware, сжимающее ответы размером от gzipMinSize байт, если клиент принимает gzip
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(probePaths, r.URL.Path) { // ответы проверок здоровья крошечные, сжимать нечего
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(gw, r)
+		if err := gw.Close(); err != nil {
+			logRequest(r, slog.LevelError, "[gzipMiddleware] Closing gzip stream", err)
+		}
+	})
+}
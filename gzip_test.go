@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Проверка сжатия ответов gzip
+// Сценарий:
+// 1. Создать 20 задач с длинным описанием и получить список с Accept-Encoding: gzip - ожидаем сжатый ответ
+// с Content-Type application/json, который распаковывается в 20 задач.
+// 2. Получить одну задачу (меньше 1KB) - ожидаем ответ без сжатия.
+// 3. Удалить задачу (204) и запросить /healthz - ожидаем ответы без сжатия.
+func TestGzip(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём задачи
+	batch := make([]Task, 20)
+	for i := range batch {
+		batch[i] = Task{Title: "Task", Description: strings.Repeat("long text ", 20), Status: StatusNotStarted}
+	}
+	body, _ := json.Marshal(batch)
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	// Запрос с явным Accept-Encoding, поэтому клиент НЕ распаковывает ответ сам
+	get := func(method, path string) *http.Response {
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		return resp
+	}
+	// Большой список сжимается
+	resp = get(http.MethodGet, "/todos")
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" { // ответ НЕ сжат
+		t.Fatalf("expected gzip encoding, got %q", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" { // потерялся Content-Type
+		t.Errorf("expected application/json, got %q", got)
+	}
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to open gzip stream: %v", err)
+	}
+	var list []Task
+	if err := json.NewDecoder(reader).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(list) != 20 { // распаковали НЕ весь список
+		t.Errorf("expected 20 tasks, got %d", len(list))
+	}
+	// Маленькие ответы, 204 и /healthz не сжимаются
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/todos/1"},
+		{http.MethodDelete, "/todos/1"},
+		{http.MethodGet, "/healthz"},
+	} {
+		resp := get(tc.method, tc.path)
+		if got := resp.Header.Get("Content-Encoding"); got != "" { // ответ сжат
+			t.Errorf("%s %s: expected no encoding, got %q", tc.method, tc.path, got)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// Проверка HEAD-запросов к задаче и списку задач
+// Сценарий:
+// 1. Создать задачу и получить её через GET.
+// 2. Выполнить HEAD /todos/1 - ожидаем 200, пустое тело, ETag и Content-Length, совпадающий с длиной тела GET.
+// 3. Выполнить HEAD /todos/99 - ожидаем 404 Not Found.
+// 4. Выполнить HEAD /todos - ожидаем 200 и X-Total-Count: 1.
+func TestHead(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	body, _ := json.Marshal(Task{Title: "Task", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	resp, err = http.Get(ts.URL + "/todos/1")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	getBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+
+	head := func(path string) (*http.Response, []byte) {
+		resp, err := http.Head(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make HEAD: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, body
+	}
+	// Существующая задача
+	resp, headBody := head("/todos/1")
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	if len(headBody) != 0 { // тело передано
+		t.Errorf("expected empty body, got %q", headBody)
+	}
+	if got := resp.Header.Get("Content-Length"); got != strconv.Itoa(len(getBody)) { // длина НЕ как у GET
+		t.Errorf("expected Content-Length %d, got %q", len(getBody), got)
+	}
+	if resp.Header.Get("ETag") != `"1"` {
+		t.Errorf("expected ETag \"1\", got %q", resp.Header.Get("ETag"))
+	}
+	// Несуществующая задача
+	if resp, _ := head("/todos/99"); resp.StatusCode != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+	// Список задач
+	resp, _ = head("/todos")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Total-Count") != "1" {
+		t.Errorf("expected 200 with X-Total-Count 1, got %d %q", resp.StatusCode, resp.Header.Get("X-Total-Count"))
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyKeyReused Ошибка: ключ идемпотентности повторно использован с другим телом запроса
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request body")
+
+// idempotencySweepInterval Как часто кэш целиком вычищается от истёкших записей (не чаще ttl)
+const idempotencySweepInterval = time.Minute
+
+// idempotencyEntry Результат запроса, сохранённый под ключом идемпотентности
+type idempotencyEntry struct {
+	bodyHash [sha256.Size]byte // хэш тела исходного запроса
+	task     Task              // созданная задача
+	expires  time.Time
+	done     chan struct{} // закрывается по окончании создания (nil - задача уже создана)
+}
+
+// IdempotencyCache Кэш результатов создания задач по заголовку Idempotency-Key (записи живут ttl)
+type IdempotencyCache struct {
+	mutex     sync.Mutex
+	ttl       time.Duration
+	entries   map[string]idempotencyEntry
+	nextSweep time.Time // когда в следующий раз вычищать истёкшие записи
+}
+
+// NewIdempotencyCache Создание кэша ключей идемпотентности
+func NewIdempotencyCache(ttl time.Duration) *IdempotencyCache {
+	return &IdempotencyCache{ttl: ttl, entries: make(map[string]idempotencyEntry)}
+}
+
+// Do Выполняет create один раз для ключа. Повтор с тем же телом возвращает сохранённую задачу (replayed = true),
+// повтор с другим телом - ErrIdempotencyKeyReused. Пока задача создаётся, повторы с тем же ключом ждут результата,
+// а запросы с другими ключами не блокируются. Неудачные попытки не сохраняются
+func (c *IdempotencyCache) Do(key string, body []byte, create func() (Task, error)) (task Task, replayed bool, err error) {
+	hash := sha256.Sum256(body)
+	for {
+		now := time.Now()
+		c.mutex.Lock()
+		c.sweep(now)
+		entry, ok := c.entries[key]
+		if ok && entry.done == nil && !now.Before(entry.expires) { // запись истекла
+			delete(c.entries, key)
+			ok = false
+		}
+		if !ok {
+			break // ключ свободен, блокировка остаётся за нами
+		}
+		c.mutex.Unlock()
+		if entry.bodyHash != hash {
+			return Task{}, false, ErrIdempotencyKeyReused
+		}
+		if entry.done == nil {
+			return entry.task, true, nil
+		}
+		<-entry.done // ждём параллельную попытку с тем же ключом и проверяем ключ заново
+	}
+	done := make(chan struct{})
+	c.entries[key] = idempotencyEntry{bodyHash: hash, done: done}
+	c.mutex.Unlock()
+
+	stored := false
+	defer func() { // выполняется и при панике в create, чтобы ожидающие повторы не зависли
+		c.mutex.Lock()
+		if stored {
+			c.entries[key] = idempotencyEntry{bodyHash: hash, task: task, expires: time.Now().Add(c.ttl)}
+		} else {
+			delete(c.entries, key)
+		}
+		c.mutex.Unlock()
+		close(done)
+	}()
+	task, err = create()
+	if err != nil {
+		return Task{}, false, err
+	}
+	stored = true
+	return task, false, nil
+}
+
+// sweep Удаляет истёкшие записи не чаще раза в idempotencySweepInterval (вызывается под блокировкой)
+func (c *IdempotencyCache) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+	for k, entry := range c.entries {
+		if entry.done == nil && !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.nextSweep = now.Add(min(c.ttl, idempotencySweepInterval))
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Проверка ключей идемпотентности при создании задачи
+// Сценарий:
+// 1. Создать задачу с Idempotency-Key - ожидаем успех (201 Created) и задачу с ID 1.
+// 2. Повторить запрос с тем же ключом и телом - ожидаем тот же ответ 201 с ID 1 и заголовок Idempotent-Replayed.
+// 3. Отправить другое тело с тем же ключом - ожидаем ошибку (422 Unprocessable Entity).
+// 4. Получить список задач - ожидаем ровно одну задачу.
+func TestIdempotencyKey(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	post := func(title string) (*http.Response, Task) {
+		body, _ := json.Marshal(Task{Title: title, Status: StatusNotStarted})
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/todos", bytes.NewBuffer(body))
+		req.Header.Set("Idempotency-Key", "retry-1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var task Task
+		if resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, task
+	}
+	// Первый запрос и повтор
+	resp, first := post("Once")
+	if resp.StatusCode != http.StatusCreated || first.ID != 1 { // задача НЕ создана
+		t.Fatalf("expected 201 with id 1, got %d with id %d", resp.StatusCode, first.ID)
+	}
+	resp, replay := post("Once")
+	if resp.StatusCode != http.StatusCreated || replay.ID != first.ID { // повтор создал новую задачу
+		t.Errorf("expected replayed 201 with id %d, got %d with id %d", first.ID, resp.StatusCode, replay.ID)
+	}
+	if resp.Header.Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected Idempotent-Replayed header on retry")
+	}
+	// Тот же ключ с другим телом
+	if resp, _ = post("Other"); resp.StatusCode != http.StatusUnprocessableEntity { // получили НЕ 422
+		t.Errorf("expected 422 for reused key, got %d", resp.StatusCode)
+	}
+	// В хранилище одна задача
+	resp, err := http.Get(ts.URL + "/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var list []Task
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(list) != 1 {
+		t.Errorf("expected 1 task, got %d", len(list))
+	}
+}
+
+// Проверка параллельных запросов к кэшу идемпотентности
+// Сценарий:
+// 1. Начать создание задачи по ключу A и задержать его.
+// 2. Выполнить запрос по ключу B - ожидаем, что он не ждёт создания по ключу A.
+// 3. Повторить запрос по ключу A с другим телом - ожидаем ErrIdempotencyKeyReused сразу.
+// 4. Повторить запрос по ключу A с тем же телом, затем отпустить создание - ожидаем ту же задачу и один вызов create.
+func TestIdempotencyCacheInFlight(t *testing.T) {
+	cache := NewIdempotencyCache(time.Hour)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var calls atomic.Int32
+	slow := func() (Task, error) {
+		calls.Add(1)
+		close(started)
+		<-release
+		return Task{ID: 1, Title: "Slow"}, nil
+	}
+	first := make(chan Task)
+	go func() {
+		task, _, err := cache.Do("A", []byte("body"), slow)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		first <- task
+	}()
+	<-started
+	// Другой ключ не блокируется созданием по ключу A
+	if task, _, err := cache.Do("B", []byte("body"), func() (Task, error) { return Task{ID: 2}, nil }); err != nil || task.ID != 2 {
+		t.Errorf("expected task 2 for another key, got %+v, %v", task, err)
+	}
+	// Другое тело с тем же ключом отклоняется, не дожидаясь создания
+	if _, _, err := cache.Do("A", []byte("other"), slow); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+	// Повтор с тем же телом дожидается исходного результата
+	replay := make(chan Task)
+	go func() {
+		task, replayed, err := cache.Do("A", []byte("body"), slow)
+		if err != nil || !replayed {
+			t.Errorf("expected replayed result, got replayed=%v, %v", replayed, err)
+		}
+		replay <- task
+	}()
+	close(release)
+	if a, b := <-first, <-replay; a.ID != 1 || b.ID != 1 { // повтор получил НЕ исходную задачу
+		t.Errorf("expected task 1 twice, got %d and %d", a.ID, b.ID)
+	}
+	if n := calls.Load(); n != 1 { // задача создана больше одного раза
+		t.Errorf("expected create to run once, got %d", n)
+	}
+}
+
+// Проверка истечения записей кэша идемпотентности
+// Сценарий:
+// 1. Сохранить задачу по ключу и состарить запись - ожидаем, что повтор создаёт задачу заново.
+// 2. Состарить запись другого ключа и дождаться очистки - ожидаем, что запись удалена без обращения к её ключу.
+func TestIdempotencyCacheExpiry(t *testing.T) {
+	cache := NewIdempotencyCache(time.Hour)
+	create := func(id int) func() (Task, error) {
+		return func() (Task, error) { return Task{ID: id}, nil }
+	}
+	if _, _, err := cache.Do("A", []byte("body"), create(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expire := func(key string) {
+		cache.mutex.Lock()
+		entry := cache.entries[key]
+		entry.expires = time.Now().Add(-time.Second)
+		cache.entries[key] = entry
+		cache.mutex.Unlock()
+	}
+	// Истёкший ключ освобождается при обращении к нему
+	expire("A")
+	task, replayed, err := cache.Do("A", []byte("body"), create(2))
+	if err != nil || replayed || task.ID != 2 { // истёкшая запись НЕ освободила ключ
+		t.Errorf("expected a new task 2, got %+v (replayed=%v), %v", task, replayed, err)
+	}
+	// Истёкшие записи других ключей удаляются периодической очисткой
+	expire("A")
+	cache.mutex.Lock()
+	cache.nextSweep = time.Time{}
+	cache.mutex.Unlock()
+	if _, _, err := cache.Do("B", []byte("body"), create(3)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache.mutex.Lock()
+	_, ok := cache.entries["A"]
+	cache.mutex.Unlock()
+	if ok { // очистка НЕ удалила истёкшую запись
+		t.Error("expected expired entry to be swept")
+	}
+}
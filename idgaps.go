@@ -0,0 +1,76 @@
+package main
+
+import "slices"
+
+// idRange Диапазон свободных ID задач (границы включительно)
+type idRange struct {
+	from, to int
+}
+
+// newID ID для новой задачи: наименьший свободный при заполнении пропусков, иначе следующий за последним выданным
+// (вызывается под блокировкой, ID занимается при вставке задачи)
+func (ds *TaskStore) newID() int {
+	if ds.config.FillIDGaps && len(ds.freeIDs) > 0 {
+		return ds.freeIDs[0].from
+	}
+	return ds.nextID + 1
+}
+
+// occupyID Исключает ID вставляемой задачи из свободных (вызывается под блокировкой до обновления nextID).
+// Явно заданный ID больше nextID оставляет свободным диапазон между ними
+func (ds *TaskStore) occupyID(id int) {
+	if !ds.config.FillIDGaps {
+		return
+	}
+	if id > ds.nextID {
+		if id > ds.nextID+1 {
+			ds.freeIDs = append(ds.freeIDs, idRange{from: ds.nextID + 1, to: id - 1})
+		}
+		return
+	}
+	i, found := slices.BinarySearchFunc(ds.freeIDs, id, func(r idRange, id int) int {
+		switch {
+		case r.to < id:
+			return -1
+		case r.from > id:
+			return 1
+		}
+		return 0
+	})
+	if !found { // ID уже не свободен
+		return
+	}
+	switch r := ds.freeIDs[i]; {
+	case r.from == r.to:
+		ds.freeIDs = slices.Delete(ds.freeIDs, i, i+1)
+	case id == r.from:
+		ds.freeIDs[i].from++
+	case id == r.to:
+		ds.freeIDs[i].to--
+	default: // ID в середине диапазона делит его на два
+		ds.freeIDs[i].to = id - 1
+		ds.freeIDs = slices.Insert(ds.freeIDs, i+1, idRange{from: id + 1, to: r.to})
+	}
+}
+
+// releaseID Возвращает ID окончательно удалённой задачи в свободные, объединяя соседние диапазоны
+// (вызывается под блокировкой)
+func (ds *TaskStore) releaseID(id int) {
+	if !ds.config.FillIDGaps {
+		return
+	}
+	i, _ := slices.BinarySearchFunc(ds.freeIDs, id, func(r idRange, id int) int { return r.from - id })
+	left := i > 0 && ds.freeIDs[i-1].to == id-1
+	right := i < len(ds.freeIDs) && ds.freeIDs[i].from == id+1
+	switch {
+	case left && right:
+		ds.freeIDs[i-1].to = ds.freeIDs[i].to
+		ds.freeIDs = slices.Delete(ds.freeIDs, i, i+1)
+	case left:
+		ds.freeIDs[i-1].to = id
+	case right:
+		ds.freeIDs[i].from = id
+	default:
+		ds.freeIDs = slices.Insert(ds.freeIDs, i, idRange{from: id, to: id})
+	}
+}
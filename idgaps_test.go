@@ -0,0 +1,156 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// Проверка заполнения пропусков в ID
+// Сценарий:
+// 1. Включить заполнение пропусков, создать задачи 1-3 и удалить задачу 2 в корзину - ожидаем у новой задачи ID 4
+// (ID задачи в корзине занят: её можно восстановить).
+// 2. Очистить корзину - ожидаем, что следующая задача получит освободившийся ID 2.
+// 3. Создать задачу с явным ID 10, удалить и очистить задачу 3, создать пакет из трёх задач - ожидаем ID 3, 5 и 6.
+// 4. Создать задачу с явным ID 8 - ожидаем, что следующие задачи получат 7, 9 и 11.
+// 5. Без заполнения пропусков после удаления и очистки - ожидаем следующий ID после последнего выданного.
+func TestFillIDGaps(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.FillIDGaps = true
+	config.TrashRetention = time.Hour
+	ds := NewTaskStoreWithConfig(config)
+
+	add := func() int {
+		task, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted})
+		if err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+		return task.ID
+	}
+	purge := func(id int) {
+		if err := ds.DeleteTask(id, 0); err != nil {
+			t.Fatalf("failed to delete task: %v", err)
+		}
+		ds.PurgeTrash(time.Now().Add(2 * time.Hour))
+	}
+
+	for range 3 {
+		add()
+	}
+	if err := ds.DeleteTask(2, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if id := add(); id != 4 { // занят ID задачи из корзины
+		t.Fatalf("expected id 4 while task 2 is in trash, got %d", id)
+	}
+	ds.PurgeTrash(time.Now().Add(2 * time.Hour))
+	if id := add(); id != 2 { // освободившийся ID НЕ выдан
+		t.Fatalf("expected freed id 2, got %d", id)
+	}
+
+	if err := ds.CreateTask(Task{ID: 10, Title: "Explicit", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	purge(3)
+	created, err := ds.AddTasks([]Task{{Title: "A", Status: StatusNotStarted}, {Title: "B", Status: StatusNotStarted}, {Title: "C", Status: StatusNotStarted}})
+	if err != nil {
+		t.Fatalf("failed to add tasks: %v", err)
+	}
+	var ids []int
+	for _, task := range created {
+		ids = append(ids, task.ID)
+	}
+	if !slices.Equal(ids, []int{3, 5, 6}) { // пакет НЕ заполнил пропуски
+		t.Fatalf("expected ids [3 5 6], got %v", ids)
+	}
+
+	if err := ds.CreateTask(Task{ID: 8, Title: "Explicit", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	ids = nil
+	for range 3 {
+		ids = append(ids, add())
+	}
+	if !slices.Equal(ids, []int{7, 9, 11}) { // явный ID внутри пропуска учтён неверно
+		t.Fatalf("expected ids [7 9 11], got %v", ids)
+	}
+
+	config.FillIDGaps = false
+	ds = NewTaskStoreWithConfig(config)
+	for range 3 {
+		add()
+	}
+	purge(2)
+	if id := add(); id != 4 { // без опции ID переиспользован
+		t.Errorf("expected id 4 without gap filling, got %d", id)
+	}
+}
+
+// Проверка, что вытеснение и очистка корзины не отдают новой задаче ID родителя с подзадачами
+// Сценарий:
+// 1. С лимитом в 3 задачи и вытеснением завершённых создать завершённого родителя 1, его завершённую подзадачу 2
+// и задачу 3, затем создать ещё задачу - ожидаем, что вытеснена подзадача 2, а не более старый родитель 1.
+// 2. Создать ещё задачу - ожидаем, что теперь вытеснен родитель 1, а новая задача с его ID без подзадач и удаляется.
+// 3. Удалить в корзину дерево из родителя и подзадачи и очистить корзину - ожидаем, что удалены обе задачи
+// и новая задача с ID родителя без подзадач.
+func TestFillIDGapsAfterParentEviction(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.FillIDGaps = true
+	config.MaxTasks = 3
+	config.EvictionPolicy = EvictionCompleted
+	config.TrashRetention = time.Hour
+	ds := NewTaskStoreWithConfig(config)
+
+	parentID := 1
+	add := func(task Task) Task {
+		created, err := ds.AddTask(task)
+		if err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+		return created
+	}
+	update := func(id int, task Task) {
+		if _, err := ds.UpdateTask(id, task); err != nil {
+			t.Fatalf("failed to update task %d: %v", id, err)
+		}
+	}
+	checkFresh := func(task Task) {
+		if list, _ := ds.Subtasks(task.ID); len(list) != 0 { // новая задача унаследовала подзадачи
+			t.Errorf("expected task %d to have no subtasks, got %+v", task.ID, list)
+		}
+		if err := ds.DeleteTask(task.ID, 0); err != nil {
+			t.Errorf("expected task %d to be deletable, got %v", task.ID, err)
+		}
+	}
+
+	add(Task{Title: "Parent", Status: StatusInProgress})
+	add(Task{Title: "Child", Status: StatusInProgress, ParentID: &parentID})
+	update(2, Task{Title: "Child", Status: StatusCompleted, ParentID: &parentID})
+	update(1, Task{Title: "Parent", Status: StatusCompleted})
+	add(Task{Title: "Other", Status: StatusNotStarted})
+	// Родитель старше, но вытесняется подзадача
+	if task := add(Task{Title: "New", Status: StatusNotStarted}); task.ID != 2 {
+		t.Errorf("expected the evicted subtask id 2 to be reused, got %d", task.ID)
+	}
+	if _, err := ds.GetTask(1); err != nil { // родитель вытеснен раньше подзадачи
+		t.Errorf("expected parent to survive while it has subtasks: %v", err)
+	}
+	// Без подзадач родителя можно вытеснить
+	task := add(Task{Title: "Newer", Status: StatusNotStarted})
+	if task.ID != 1 {
+		t.Fatalf("expected the evicted parent id 1 to be reused, got %d", task.ID)
+	}
+	checkFresh(task)
+
+	// Очистка корзины удаляет дерево снизу вверх
+	ds = NewTaskStoreWithConfig(config)
+	add(Task{Title: "Parent", Status: StatusInProgress})
+	add(Task{Title: "Child", Status: StatusInProgress, ParentID: &parentID})
+	if err := ds.DeleteTaskTree(1, 0); err != nil {
+		t.Fatalf("failed to delete tree: %v", err)
+	}
+	if purged := ds.PurgeTrash(time.Now().Add(2 * time.Hour)); purged != 2 { // дерево очищено НЕ целиком
+		t.Errorf("expected 2 purged tasks, got %d", purged)
+	}
+	checkFresh(add(Task{Title: "New", Status: StatusNotStarted}))
+}
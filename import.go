@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportRowError Ошибка импорта строки CSV (номер строки считается с 1, строка заголовков - первая)
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportSummary Итог импорта задач из CSV
+type ImportSummary struct {
+	Created int              `json:"created"`
+	Skipped int              `json:"skipped"` // Строки с уже занятым ID
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// parseCSVTask Разбор строки CSV в задачу по колонкам заголовка (неизвестные колонки и created_at игнорируются)
+func parseCSVTask(columns map[string]int, record []string, validation ValidationConfig) (Task, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	task := Task{Title: field("title"), Description: field("description"), Status: TaskStatus(field("status"))}
+	if id := strings.TrimSpace(field("id")); id != "" {
+		var err error
+		if task.ID, err = strconv.Atoi(id); err != nil {
+			return Task{}, fmt.Errorf("invalid id %q", id)
+		}
+	}
+	if dueAt := strings.TrimSpace(field("due_at")); dueAt != "" {
+		parsed, err := time.Parse(time.RFC3339, dueAt)
+		if err != nil {
+			return Task{}, fmt.Errorf("due_at must be an RFC3339 timestamp")
+		}
+		task.DueAt = &parsed
+	}
+	task.Preprocess()
+	return task, task.Validate(validation)
+}
+
+// ImportCSV Импорт задач из CSV с заголовком. Строки с ID создаются с этим ID (если он занят, строка пропускается),
+// строки без ID получают ID автоматически. Ошибочные строки не прерывают импорт
+func (ds *TaskStore) ImportCSV(r io.Reader) (ImportSummary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // число полей проверяется по колонкам заголовка
+	header, err := reader.Read()
+	if err != nil {
+		return ImportSummary{}, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return ImportSummary{}, fmt.Errorf("CSV header must contain a title column")
+	}
+	summary := ImportSummary{Errors: []ImportRowError{}}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) { // ошибка чтения тела, а не формата строки
+				return summary, err
+			}
+			summary.Errors = append(summary.Errors, ImportRowError{Row: row, Error: err.Error()})
+			continue
+		}
+		task, err := parseCSVTask(columns, record, ds.config.Validation)
+		if err == nil {
+			if task.ID > 0 {
+				err = ds.CreateTask(task)
+			} else {
+				_, err = ds.AddTask(task)
+			}
+		}
+		switch {
+		case errors.Is(err, ErrTaskExists):
+			summary.Skipped++
+		case err != nil:
+			summary.Errors = append(summary.Errors, ImportRowError{Row: row, Error: err.Error()})
+		default:
+			summary.Created++
+		}
+	}
+	return summary, nil
+}
+
+// importHandler Обработчик эндпоинта /todos/import: CSV в теле запроса или файлом (поле file) в multipart/form-data
+func importHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			logRequest(r, slog.LevelWarn, "[importHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		body := r.Body
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[importHandler] Reading upload", err)
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeDecodeError(w, err)
+					return
+				}
+				writeJSONError(w, http.StatusBadRequest, "multipart form must contain a file field")
+				return
+			}
+			defer func() { _ = file.Close() }()
+			body = file
+		}
+		summary, err := ts.ImportCSV(body)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[importHandler] Importing CSV", err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeDecodeError(w, err)
+				return
+			}
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(summary.Errors) > 0 {
+			logRequest(r, slog.LevelWarn, "[importHandler] Rows rejected", nil, "rejected", len(summary.Errors))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(summary); err != nil {
+			logRequest(r, slog.LevelError, "[importHandler] Encoding summary", err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Проверка импорта задач из CSV
+// Сценарий:
+// 1. Создать задачу с ID 1.
+// 2. Импортировать CSV: строка с занятым ID 1, строка без заголовка, строка без ID и строка с ID 10 -
+// ожидаем created=2, skipped=1 и ошибку в строке 3.
+// 3. Импортировать исправленный CSV файлом через multipart/form-data - ожидаем, что все строки с ID пропущены
+// (ID 2 уже получила строка без ID), а строка без ID создана.
+// 4. Импортировать CSV без колонки title - ожидаем ошибку (400 Bad Request).
+func TestImportCSV(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	body, _ := json.Marshal(Task{Title: "Existing", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	data := "id,title,description,status,created_at,due_at\n" +
+		"1,Duplicate,,not started,,\n" +
+		"2, ,,not started,,\n" +
+		",\"Купить \"\"молоко\"\", хлеб\",,in progress,,\n" +
+		"10,Imported,,completed,,2030-01-01T00:00:00Z\n"
+	importCSV := func(contentType string, body *bytes.Buffer) (ImportSummary, int) {
+		resp, err := http.Post(ts.URL+"/todos/import", contentType, body)
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var summary ImportSummary
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return summary, resp.StatusCode
+	}
+	// Импорт CSV из тела запроса
+	summary, _ := importCSV("text/csv", bytes.NewBufferString(data))
+	if summary.Created != 2 || summary.Skipped != 1 { // неверный итог импорта
+		t.Errorf("expected created=2 skipped=1, got %+v", summary)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Row != 3 { // ошибочная строка НЕ указана
+		t.Errorf("expected an error in row 3, got %+v", summary.Errors)
+	}
+	// Импорт файлом: все ID уже заняты
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, _ := writer.CreateFormFile("file", "tasks.csv")
+	_, _ = part.Write([]byte(strings.Replace(data, "2, ,", "2,Fixed,", 1)))
+	_ = writer.Close()
+	summary, _ = importCSV(writer.FormDataContentType(), &form)
+	if summary.Created != 1 || summary.Skipped != 3 || len(summary.Errors) != 0 { // создаётся только строка без ID
+		t.Errorf("expected created=1 skipped=3 without errors, got %+v", summary)
+	}
+	// CSV без колонки title
+	if _, status := importCSV("text/csv", bytes.NewBufferString("id,name\n1,x\n")); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without title column, got %d", status)
+	}
+}
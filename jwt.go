@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrTokenExpired Ошибка проверки токена с истёкшим сроком действия
+var ErrTokenExpired = errors.New("token is expired")
+
+// publicPaths Пути, доступные без аутентификации (проверки здоровья и метрики)
+var publicPaths = slices.Concat(probePaths, []string{"/metrics"})
+
+// Identity Аутентифицированный клиент
+type Identity struct {
+	Subject string   // Идентификатор клиента (claim sub для JWT)
+	Roles   []string // Роли клиента (claims role и roles для JWT)
+}
+
+// identityKey Ключ аутентифицированного клиента в контексте
+type identityKey struct{}
+
+// IdentityFromContext Возвращает аутентифицированного клиента из контекста (ok = false, если аутентификации не было)
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// jwtClaims Проверяемые поля JWT
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Role      string   `json:"role"`
+	Roles     []string `json:"roles"`
+	ExpiresAt *float64 `json:"exp"` // NumericDate, секунды с начала эпохи
+	NotBefore *float64 `json:"nbf"`
+}
+
+// JWTVerifier Проверка подписи и срока действия JWT (HS256 с общим секретом или RS256 с открытым ключом)
+type JWTVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	now       func() time.Time
+}
+
+// NewHMACVerifier Создание проверки JWT, подписанных HS256 общим секретом
+func NewHMACVerifier(secret []byte) *JWTVerifier {
+	return &JWTVerifier{secret: secret, now: time.Now}
+}
+
+// NewRSAVerifier Создание проверки JWT, подписанных RS256, по открытому ключу
+func NewRSAVerifier(publicKey *rsa.PublicKey) *JWTVerifier {
+	return &JWTVerifier{publicKey: publicKey, now: time.Now}
+}
+
+// newJWTVerifier Проверка JWT по флагам запуска (nil, если аутентификация по JWT не настроена)
+func newJWTVerifier(secret, publicKeyFile string) (*JWTVerifier, error) {
+	switch {
+	case secret != "" && publicKeyFile != "":
+		return nil, errors.New("-jwt-secret and -jwt-public-key cannot be combined")
+	case secret != "":
+		return NewHMACVerifier([]byte(secret)), nil
+	case publicKeyFile != "":
+		key, err := loadRSAPublicKey(publicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewRSAVerifier(key), nil
+	}
+	return nil, nil
+}
+
+// loadRSAPublicKey Загрузка открытого RSA-ключа из PEM-файла (PKIX или PKCS #1)
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an RSA public key", path)
+	}
+	return rsaKey, nil
+}
+
+// algorithm Алгоритм подписи, который принимает проверка (токены с другим alg, в том числе none, отклоняются)
+func (v *JWTVerifier) algorithm() string {
+	if v.publicKey != nil {
+		return "RS256"
+	}
+	return "HS256"
+}
+
+// Verify Проверка токена: формат, алгоритм, подпись и сроки exp/nbf
+func (v *JWTVerifier) Verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed token header: %w", err)
+	}
+	if header.Alg != v.algorithm() {
+		return jwtClaims{}, fmt.Errorf("unexpected signing algorithm %q", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return jwtClaims{}, errors.New("malformed token signature")
+	}
+	signed := parts[0] + "." + parts[1]
+	if v.publicKey != nil {
+		digest := sha256.Sum256([]byte(signed))
+		err = rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature)
+	} else {
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			err = errors.New("signature mismatch")
+		}
+	}
+	if err != nil {
+		return jwtClaims{}, fmt.Errorf("invalid token signature: %w", err)
+	}
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, fmt.Errorf("malformed token claims: %w", err)
+	}
+	now := float64(v.now().Unix())
+	if claims.ExpiresAt != nil && now >= *claims.ExpiresAt {
+		return jwtClaims{}, ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now < *claims.NotBefore {
+		return jwtClaims{}, errors.New("token is not valid yet")
+	}
+	if claims.Subject == "" {
+		return jwtClaims{}, errors.New("token has no subject")
+	}
+	return claims, nil
+}
+
+// decodeSegment Декодирование JSON-сегмента токена в base64url без выравнивания
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeUnauthorized Ответ 401 с заголовком WWW-Authenticate
+func writeUnauthorized(w http.ResponseWriter, challenge, message string) {
+	w.Header().Set("WWW-Authenticate", challenge)
+	writeJSONError(w, http.StatusUnauthorized, message)
+}
+
+// jwtMiddleware Middleware, требующее валидный JWT в заголовке Authorization: Bearer
+// (кроме publicPaths) и кладущее subject в контекст запроса
+func jwtMiddleware(v *JWTVerifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(publicPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			logRequest(r, slog.LevelWarn, "[jwtMiddleware] Missing bearer token", nil)
+			writeUnauthorized(w, "Bearer", "missing bearer token")
+			return
+		}
+		claims, err := v.Verify(strings.TrimSpace(token))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[jwtMiddleware] Rejecting token", err)
+			writeUnauthorized(w, `Bearer error="invalid_token"`, err.Error())
+			return
+		}
+		identity := Identity{Subject: claims.Subject, Roles: claims.Roles}
+		if claims.Role != "" {
+			identity.Roles = append(identity.Roles, claims.Role)
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// signToken Подпись тестового JWT: HS256 секретом или RS256 закрытым ключом
+func signToken(t *testing.T, alg string, key any, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var signature []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case "RS256":
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Проверка аутентификации по JWT
+// Сценарий:
+// 1. Запросить /todos без токена - ожидаем 401 Unauthorized.
+// 2. Запросить с токеном, подписанным другим секретом, и с истёкшим токеном - ожидаем 401 с понятным сообщением.
+// 3. Запросить с валидным токеном - ожидаем, что обработчик получит subject из контекста.
+// 4. Запросить /healthz и /metrics без токена - ожидаем, что они доступны.
+func TestJWTMiddleware(t *testing.T) {
+	secret := []byte("test-secret")
+	var subject string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity, ok := IdentityFromContext(r.Context()); ok {
+			subject = identity.Subject
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := jwtMiddleware(NewHMACVerifier(secret), next)
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	tests := []struct {
+		name    string
+		path    string
+		token   string
+		status  int
+		message string
+	}{
+		{"missing token", "/todos", "", http.StatusUnauthorized, "missing bearer token"},
+		{"wrong secret", "/todos", signToken(t, "HS256", []byte("other"), map[string]any{"sub": "alice", "exp": exp}),
+			http.StatusUnauthorized, "invalid token signature: signature mismatch"},
+		{"expired", "/todos", signToken(t, "HS256", secret, map[string]any{"sub": "alice", "exp": 1}),
+			http.StatusUnauthorized, "token is expired"},
+		{"valid", "/todos", signToken(t, "HS256", secret, map[string]any{"sub": "alice", "exp": exp}), http.StatusOK, ""},
+		{"healthz", "/healthz", "", http.StatusOK, ""},
+		{"metrics", "/metrics", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status { // неожиданный статус
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, rec.Code)
+		}
+		if tt.message != "" {
+			var errResp ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.Error != tt.message {
+				t.Errorf("%s: expected %q, got %q", tt.name, tt.message, errResp.Error)
+			}
+		}
+	}
+	if subject != "alice" { // subject НЕ попал в контекст
+		t.Errorf("expected subject alice, got %q", subject)
+	}
+}
+
+// Проверка JWT, подписанных RS256, и отказа от токенов с другим алгоритмом
+func TestJWTVerifierRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	verifier := NewRSAVerifier(&key.PublicKey)
+	claims, err := verifier.Verify(signToken(t, "RS256", key, map[string]any{"sub": "bob"}))
+	if err != nil || claims.Subject != "bob" {
+		t.Errorf("expected subject bob, got %+v (%v)", claims, err)
+	}
+	// токен HS256 нельзя подписать открытым ключом и выдать за RS256
+	if _, err := verifier.Verify(signToken(t, "HS256", []byte("x"), map[string]any{"sub": "bob"})); err == nil {
+		t.Errorf("expected HS256 token to be rejected")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// newLogger Создание JSON-логгера с заданным уровнем (debug, info, warn, error).
+// В записи, сделанные в контексте запроса, добавляется его ID
+func newLogger(w io.Writer, level string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: must be debug, info, warn or error", level)
+	}
+	return slog.New(requestIDHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})}), nil
+}
+
+// logRequest Запись события обработки запроса: к полям добавляются метод, путь и ошибка (если есть)
+func logRequest(r *http.Request, level slog.Level, msg string, err error, attrs ...any) {
+	attrs = append(attrs, "method", r.Method, "path", r.URL.Path)
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	slog.Log(r.Context(), level, msg, attrs...)
+}
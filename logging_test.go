@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+)
+
+// Проверка структурированного журнала
+// Сценарий:
+// 1. Создать логгер с уровнем warn и записать событие запроса уровня warn - ожидаем JSON с msg, method, path, task_id и error.
+// 2. Записать событие уровня info - ожидаем, что оно отфильтровано.
+// 3. Создать логгер с неизвестным уровнем - ожидаем ошибку.
+func TestLogRequest(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "warn")
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	// Пишем предупреждение
+	r := httptest.NewRequest("GET", "/todos/3", nil)
+	logRequest(r, slog.LevelWarn, "[todoHandler] Getting task", errors.New("task with id 3 not found"), "task_id", 3)
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log entry is not JSON: %v (%q)", err, buf.String())
+	}
+	if entry["msg"] != "[todoHandler] Getting task" || entry["method"] != "GET" || entry["path"] != "/todos/3" ||
+		entry["task_id"] != float64(3) || entry["error"] != "task with id 3 not found" { // поля НЕ записаны
+		t.Errorf("unexpected log entry %v", entry)
+	}
+	// Пишем событие ниже порога
+	buf.Reset()
+	logRequest(r, slog.LevelInfo, "[todoHandler] Ignored", nil)
+	if buf.Len() != 0 { // уровень НЕ учтён
+		t.Errorf("expected info entry to be filtered, got %q", buf.String())
+	}
+	// Неизвестный уровень
+	if _, err := newLogger(&buf, "verbose"); err == nil {
+		t.Errorf("expected error for unknown log level")
+	}
+}
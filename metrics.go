@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	mediaPrometheus  = "text/plain"                   // Текстовый формат Prometheus 0.0.4
+	mediaOpenMetrics = "application/openmetrics-text" // Формат OpenMetrics 1.0.0
+)
+
+// metricsMedia Форматы /metrics в порядке предпочтения при равном весе
+var metricsMedia = []string{mediaPrometheus, mediaOpenMetrics}
+
+// durationBuckets Границы корзин гистограммы длительности запросов, в секундах
+var durationBuckets = [...]float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// methodCode Метки счётчика запросов
+type methodCode struct {
+	method string
+	code   int
+}
+
+// requestMetrics Метрики запросов для /metrics (счётчики по методу и коду, гистограмма длительности).
+// Все значения - атомарные счётчики, поэтому учёт запроса не берёт общих блокировок
+type requestMetrics struct {
+	byMethodCode  sync.Map                           // methodCode -> *atomic.Int64
+	bucketCounts  [len(durationBuckets)]atomic.Int64 // число запросов в каждой корзине durationBuckets (не накопительно)
+	durationNanos atomic.Int64                       // суммарная длительность запросов
+	durationCount atomic.Int64
+}
+
+// observe Учёт запроса в метриках
+func (m *requestMetrics) observe(method string, status int, duration time.Duration) {
+	key := methodCode{method: method, code: status}
+	counter, ok := m.byMethodCode.Load(key)
+	if !ok { // новая пара метода и кода встречается редко, дальше счётчик только читается из карты
+		counter, _ = m.byMethodCode.LoadOrStore(key, new(atomic.Int64))
+	}
+	counter.(*atomic.Int64).Add(1)
+	// общий счётчик растёт раньше корзины, а writeTo читает корзины раньше него,
+	// поэтому в выводе корзины никогда не превышают _count
+	m.durationCount.Add(1)
+	m.durationNanos.Add(int64(duration))
+	if i, _ := slices.BinarySearch(durationBuckets[:], duration.Seconds()); i < len(durationBuckets) {
+		m.bucketCounts[i].Add(1)
+	}
+}
+
+// formatBound Граница корзины гистограммы. OpenMetrics требует канонического вида числа с плавающей точкой ("1.0", а не "1")
+func formatBound(bound float64, openMetrics bool) string {
+	s := strconv.FormatFloat(bound, 'g', -1, 64)
+	if openMetrics && !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// writeTo Вывод метрик запросов в текстовом формате Prometheus или OpenMetrics.
+// В OpenMetrics семейство счётчика называется без суффикса _total, который остаётся только у значения
+func (m *requestMetrics) writeTo(w io.Writer, openMetrics bool) {
+	counts := make(map[methodCode]int64)
+	var keys []methodCode
+	m.byMethodCode.Range(func(key, counter any) bool {
+		keys = append(keys, key.(methodCode))
+		counts[key.(methodCode)] = counter.(*atomic.Int64).Load()
+		return true
+	})
+	slices.SortFunc(keys, func(a, b methodCode) int {
+		if a.method != b.method {
+			if a.method < b.method {
+				return -1
+			}
+			return 1
+		}
+		return a.code - b.code
+	})
+	family := "todo_http_requests_total"
+	if openMetrics {
+		family = "todo_http_requests"
+	}
+	fmt.Fprintf(w, "# HELP %s Total number of HTTP requests by method and status code.\n", family)
+	fmt.Fprintf(w, "# TYPE %s counter\n", family)
+	for _, key := range keys {
+		fmt.Fprintf(w, "todo_http_requests_total{method=%q,code=\"%d\"} %d\n", key.method, key.code, counts[key])
+	}
+	fmt.Fprintln(w, "# HELP todo_http_request_duration_seconds Duration of HTTP requests in seconds.")
+	fmt.Fprintln(w, "# TYPE todo_http_request_duration_seconds histogram")
+	var cumulative int64
+	for i, bound := range durationBuckets {
+		cumulative += m.bucketCounts[i].Load()
+		fmt.Fprintf(w, "todo_http_request_duration_seconds_bucket{le=%q} %d\n", formatBound(bound, openMetrics), cumulative)
+	}
+	count := m.durationCount.Load()
+	sum := time.Duration(m.durationNanos.Load()).Seconds()
+	fmt.Fprintf(w, "todo_http_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", count)
+	fmt.Fprintf(w, "todo_http_request_duration_seconds_sum %s\n", strconv.FormatFloat(sum, 'g', -1, 64))
+	fmt.Fprintf(w, "todo_http_request_duration_seconds_count %d\n", count)
+}
+
+// metricsHandler Обработчик эндпоинта /metrics: текстовый формат Prometheus или, если клиент предпочитает
+// его по Accept, OpenMetrics. Если ни один формат не подходит, отдаётся формат Prometheus, как принято для /metrics
+func metricsHandler(s *Stats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[metricsHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		media, _ := negotiate(r, metricsMedia)
+		openMetrics := media == mediaOpenMetrics
+		if openMetrics {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		}
+		w.Header().Add("Vary", "Accept")
+		w.Header().Set("Cache-Control", "no-store")
+		s.metrics.writeTo(w, openMetrics)
+		fmt.Fprintln(w, "# HELP todo_tasks Current number of tasks in all stores (trashed tasks excluded).")
+		fmt.Fprintln(w, "# TYPE todo_tasks gauge")
+		fmt.Fprintf(w, "todo_tasks %d\n", s.tasks.Load())
+		if openMetrics {
+			fmt.Fprintln(w, "# EOF")
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Проверка эндпоинта /metrics
+// Сценарий:
+// 1. Создать две задачи и удалить одну из них.
+// 2. Запросить /metrics - ожидаем текстовый формат Prometheus со счётчиком POST-запросов с кодом 201,
+// гистограммой длительности запросов и текущим числом задач 1.
+func TestMetrics(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём и удаляем задачи
+	for range 2 {
+		body, _ := json.Marshal(Task{Title: "Measured", Status: StatusNotStarted})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make DELETE: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	// Запрашиваем метрики
+	resp, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") { // формат НЕ текстовый
+		t.Errorf("expected text/plain, got %q", resp.Header.Get("Content-Type"))
+	}
+	for _, line := range []string{
+		`todo_http_requests_total{method="POST",code="201"} 2`,
+		`todo_http_requests_total{method="DELETE",code="204"} 1`,
+		`todo_http_request_duration_seconds_bucket{le="+Inf"} 3`,
+		"todo_http_request_duration_seconds_count 3",
+		"todo_tasks 1",
+	} {
+		if !strings.Contains(string(data), line+"\n") { // метрики НЕТ в ответе
+			t.Errorf("expected line %q in metrics:\n%s", line, data)
+		}
+	}
+}
+
+// Проверка /metrics в формате OpenMetrics
+// Сценарий:
+// 1. Создать задачу и запросить /metrics с Accept, который присылает Prometheus - ожидаем Content-Type OpenMetrics.
+// 2. Разобрать ответ по правилам OpenMetrics - ожидаем корректный документ с # EOF в конце и значения счётчиков.
+// 3. Запросить /metrics без Accept и с Accept: application/json - ожидаем прежний текстовый формат без # EOF.
+func TestMetricsOpenMetrics(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	body, _ := json.Marshal(Task{Title: "Measured", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	get := func(accept string) (string, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/metrics", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.Header.Get("Content-Type"), string(data)
+	}
+
+	contentType, data := get("application/openmetrics-text;version=1.0.0,application/openmetrics-text;version=0.0.1;q=0.75," +
+		"text/plain;version=0.0.4;q=0.5,*/*;q=0.1")
+	if contentType != "application/openmetrics-text; version=1.0.0; charset=utf-8" { // формат НЕ OpenMetrics
+		t.Fatalf("expected OpenMetrics content type, got %q", contentType)
+	}
+	samples := parseOpenMetrics(t, data)
+	for sample, want := range map[string]float64{
+		`todo_http_requests_total{method="POST",code="201"}`:   1,
+		`todo_http_request_duration_seconds_bucket{le="+Inf"}`: 1,
+		"todo_http_request_duration_seconds_count":             1,
+		"todo_tasks": 1,
+	} {
+		if got, ok := samples[sample]; !ok || got != want { // значения НЕТ или оно неверное
+			t.Errorf("expected %s %v, got %v (present: %v)", sample, want, got, ok)
+		}
+	}
+
+	for _, accept := range []string{"", "application/json"} {
+		contentType, data := get(accept)
+		if !strings.HasPrefix(contentType, "text/plain; version=0.0.4") || strings.Contains(data, "# EOF") { // формат изменился
+			t.Errorf("Accept %q: expected Prometheus text format, got %q", accept, contentType)
+		}
+	}
+}
+
+// omSample Строка значения OpenMetrics: имя, метки в фигурных скобках (необязательно) и значение
+var omSample = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{(?:[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\.)*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\.)*")*)?\})? (\S+)$`)
+
+// omSuffixes Допустимые суффиксы значений семейства метрик по его типу
+var omSuffixes = map[string][]string{
+	"counter":   {"_total", "_created"},
+	"gauge":     {""},
+	"histogram": {"_bucket", "_sum", "_count", "_created"},
+}
+
+// parseOpenMetrics Разбор документа OpenMetrics со строгой проверкой: документ заканчивается "# EOF\n",
+// у каждого семейства один TYPE до его значений, значения семейства идут подряд и имеют допустимые суффиксы,
+// границы гистограмм записаны канонически, а корзины накопительны и заканчиваются +Inf, равной _count.
+// Возвращает значения по строке "имя{метки}"
+func parseOpenMetrics(t *testing.T, data string) map[string]float64 {
+	t.Helper()
+	if !strings.HasSuffix(data, "\n# EOF\n") { // документ НЕ завершён маркером
+		t.Fatalf("document must end with # EOF:\n%s", data)
+	}
+	lines := strings.Split(strings.TrimSuffix(data, "\n# EOF\n"), "\n")
+	samples := make(map[string]float64)
+	types := make(map[string]string)
+	var family string
+	var buckets []float64
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(line, " ", 4)
+			if len(fields) < 4 || (fields[1] != "HELP" && fields[1] != "TYPE" && fields[1] != "UNIT") {
+				t.Fatalf("line %d: malformed metadata %q", i+1, line)
+			}
+			if fields[2] != family { // новое семейство
+				if _, seen := types[fields[2]]; seen {
+					t.Fatalf("line %d: family %s is interleaved with others", i+1, fields[2])
+				}
+				family, buckets = fields[2], nil
+				types[family] = ""
+			}
+			if fields[1] == "TYPE" {
+				if _, ok := omSuffixes[fields[3]]; !ok || types[family] != "" {
+					t.Fatalf("line %d: invalid or repeated TYPE %q", i+1, line)
+				}
+				types[family] = fields[3]
+			}
+			continue
+		}
+		match := omSample.FindStringSubmatch(line)
+		if match == nil {
+			t.Fatalf("line %d: malformed sample %q", i+1, line)
+		}
+		name, labels, value := match[1], match[2], match[3]
+		kind := types[family]
+		if kind == "" || !slices.ContainsFunc(omSuffixes[kind], func(suffix string) bool { return name == family+suffix }) {
+			t.Fatalf("line %d: sample %s does not belong to family %s of type %q", i+1, name, family, kind)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			t.Fatalf("line %d: invalid value %q", i+1, value)
+		}
+		if name == family+"_bucket" {
+			le := strings.TrimSuffix(strings.TrimPrefix(labels, `{le="`), `"}`)
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil || (le != "+Inf" && !strings.ContainsAny(le, ".e")) { // граница НЕ в каноническом виде
+				t.Fatalf("line %d: non-canonical le %q", i+1, le)
+			}
+			if len(buckets) > 0 && v < buckets[len(buckets)-1] { // корзины НЕ накопительны
+				t.Fatalf("line %d: bucket counts must be cumulative", i+1)
+			}
+			if math.IsInf(bound, 1) {
+				samples[family+"_inf"] = v
+			}
+			buckets = append(buckets, v)
+		}
+		if name == family+"_count" && kind == "histogram" && samples[family+"_inf"] != v { // +Inf НЕ равна _count
+			t.Fatalf("line %d: +Inf bucket must equal _count", i+1)
+		}
+		samples[name+labels] = v
+	}
+	for name, kind := range types {
+		if kind == "" { // семейство без TYPE
+			t.Fatalf("family %s has no TYPE", name)
+		}
+		delete(samples, name+"_inf")
+	}
+	return samples
+}
+
+// Проверка параллельного учёта запросов в метриках
+// Сценарий:
+// 1. Учесть 1000 запросов из 8 горутин параллельно с выводом метрик - ожидаем 8000 запросов в счётчике и гистограмме.
+func TestRequestMetricsConcurrent(t *testing.T) {
+	var m requestMetrics
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				m.observe(http.MethodGet, http.StatusOK, time.Duration(i)*time.Millisecond)
+			}
+		}()
+	}
+	for range 10 { // вывод во время учёта не должен гоняться с ним
+		m.writeTo(io.Discard, false)
+	}
+	wg.Wait()
+	var out bytes.Buffer
+	m.writeTo(&out, false)
+	for _, line := range []string{
+		`todo_http_requests_total{method="GET",code="200"} 8000`,
+		`todo_http_request_duration_seconds_bucket{le="+Inf"} 8000`,
+		`todo_http_request_duration_seconds_count 8000`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") { // запросы потеряны
+			t.Errorf("expected %q in output:\n%s", line, out.String())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// statusRecorder Обёртка над http.ResponseWriter, запоминающая код ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader Запоминает код ответа и передаёт его дальше
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write Передаёт тело ответа дальше (неявный WriteHeader означает 200)
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap Доступ к исходному http.ResponseWriter (для http.ResponseController)
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// recoverMiddleware Middleware, перехватывающее панику обработчика: стек пишется в лог (с ID запроса),
+// а клиент получает 500 с JSON-ошибкой вместо оборванного соединения
+func recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) { // намеренный обрыв ответа
+				panic(v)
+			}
+			logRequest(r, slog.LevelError, "[recoverMiddleware] Handler panicked", fmt.Errorf("%v", v), "stack", string(debug.Stack()))
+			if rec.wroteHeader { // заголовки уже отправлены, исправить ответ нельзя
+				return
+			}
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// defaultMaxBodyBytes Максимальный размер тела запроса по умолчанию (1 МБ)
+const defaultMaxBodyBytes = 1 << 20
+
+// maxBodyMiddleware Middleware, ограничивающее размер тела запроса (limit <= 0 - без ограничения)
+func maxBodyMiddleware(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// defaultRequestTimeout Время обработки запроса по умолчанию
+const defaultRequestTimeout = 15 * time.Second
+
+// timeoutBody Тело ответа 503 при превышении времени обработки запроса
+const timeoutBody = `{"error":"request timed out"}` + "\n"
+
+// timeoutResponseWriter Обёртка над http.ResponseWriter, помечающая ответ http.TimeoutHandler как JSON
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader Выставляет Content-Type и Retry-After ответу 503 без них (так отвечает http.TimeoutHandler)
+func (tw timeoutResponseWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && tw.Header().Get("Content-Type") == "" {
+		tw.Header().Set("Content-Type", "application/json")
+	}
+	if status == http.StatusServiceUnavailable && tw.Header().Get("Retry-After") == "" {
+		setRetryAfter(tw, defaultRetryAfter)
+	}
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+// timeoutMiddleware Middleware, прерывающее обработку запроса дольше timeout ответом 503 (timeout <= 0 - без ограничения).
+// Контекст запроса получает дедлайн, поэтому Ctx-методы хранилища перестают работать после него.
+// Поток событий /todos/events живёт долго и не ограничивается
+func timeoutMiddleware(timeout time.Duration, next http.Handler) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	limited := http.TimeoutHandler(next, timeout, timeoutBody)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/todos/events") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limited.ServeHTTP(timeoutResponseWriter{w}, r)
+	})
+}
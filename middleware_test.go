@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Проверка ограничения размера тела запроса
+// Сценарий:
+// 1. Создать задачу с телом больше лимита - ожидаем ошибку (413 Request Entity Too Large).
+// 2. Обновить задачу с телом больше лимита - ожидаем ошибку (413 Request Entity Too Large).
+// 3. Отправить короткий некорректный JSON - ожидаем ошибку (400 Bad Request), а не 413.
+func TestMaxBodyBytes(t *testing.T) {
+	mux := newMux(NewTaskStore(), NewTenantRegistry(DefaultStoreConfig()), NewStats(), new(atomic.Bool))
+	ts := httptest.NewServer(maxBodyMiddleware(128, mux))
+	defer ts.Close()
+
+	large, _ := json.Marshal(Task{Title: strings.Repeat("x", 256), Status: StatusNotStarted})
+	for _, tc := range []struct {
+		method, path string
+		body         []byte
+		want         int
+	}{
+		{http.MethodPost, "/todos", large, http.StatusRequestEntityTooLarge},
+		{http.MethodPut, "/todos/1", large, http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/todos", []byte(`{"title":`), http.StatusBadRequest},
+	} {
+		req, _ := http.NewRequest(tc.method, ts.URL+tc.path, bytes.NewBuffer(tc.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", tc.method, err)
+		}
+		var errResp ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if resp.StatusCode != tc.want { // ошибка размера НЕ отличается от ошибки JSON
+			t.Errorf("%s %s: expected %d, got %d (%s)", tc.method, tc.path, tc.want, resp.StatusCode, errResp.Error)
+		}
+	}
+}
+
+// Проверка ограничения времени обработки запроса
+// Сценарий:
+// 1. Выполнить запрос к обработчику, работающему дольше лимита, - ожидаем 503 с JSON-ошибкой и Retry-After,
+// а Ctx-метод хранилища внутри обработчика после дедлайна возвращает ошибку контекста.
+// 2. Выполнить такой же запрос к /todos/events - ожидаем, что лимит не применяется (200 OK).
+func TestRequestTimeout(t *testing.T) {
+	store := NewTaskStore()
+	storeErr := make(chan error, 1)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done(): // дедлайн истёк - хранилище больше не вызывается
+			_, err := GetAllTasksCtx(r.Context(), store)
+			storeErr <- err
+		case <-time.After(200 * time.Millisecond): // поток событий не прерывается
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(timeoutMiddleware(50*time.Millisecond, slow))
+	defer ts.Close()
+
+	// Долгий запрос прерывается
+	resp, err := http.Get(ts.URL + "/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable { // получили НЕ 503
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" { // ответ НЕ JSON
+		t.Errorf("expected application/json, got %q", got)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "5" { // клиенту НЕ сказано, когда повторить
+		t.Errorf("expected Retry-After 5, got %q", got)
+	}
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := <-storeErr; !errors.Is(err, context.DeadlineExceeded) { // хранилище НЕ получило дедлайн
+		t.Errorf("expected context.DeadlineExceeded from store, got %v", err)
+	}
+
+	// Поток событий не ограничивается
+	resp, err = http.Get(ts.URL + "/v1/todos/events")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Errorf("expected 200 for event stream, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка перехвата паники в обработчике
+// Сценарий:
+// 1. Выполнить запрос к обработчику, который паникует, - ожидаем 500 с JSON-ошибкой без стека,
+// а не оборванное соединение.
+// 2. Выполнить запрос к обработчику, который паникует после начала ответа, - ожидаем, что уже отправленный
+// ответ не подменяется.
+func TestRecoverMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		var counts map[string]int
+		counts["boom"]++ // запись в nil map
+	})
+	mux.HandleFunc("/late-panic", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("after header")
+	})
+	ts := httptest.NewServer(requestIDMiddleware(recoverMiddleware(mux)))
+	defer ts.Close()
+
+	// Паника до ответа
+	resp, err := http.Get(ts.URL + "/panic")
+	if err != nil { // соединение оборвано
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError { // получили НЕ 500
+		t.Errorf("expected 500, got %d", resp.StatusCode)
+	}
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if errResp.Error != "internal server error" { // в ответ утекли подробности
+		t.Errorf("expected generic error, got %q", errResp.Error)
+	}
+
+	// Паника после начала ответа
+	resp, err = http.Get(ts.URL + "/late-panic")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted { // ответ подменён
+		t.Errorf("expected 202, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	mediaJSON = "application/json"
+	mediaXML  = "application/xml"
+)
+
+// supportedMedia Форматы представления задач в порядке предпочтения при равном весе
+var supportedMedia = []string{mediaJSON, mediaXML}
+
+// negotiateMedia Выбор формата представления задач по заголовку Accept (ok = false, если ни один формат не подходит)
+func negotiateMedia(r *http.Request) (media string, ok bool) {
+	return negotiate(r, supportedMedia)
+}
+
+// negotiate Выбор одного из форматов supported по заголовку Accept (ok = false, если ни один формат не подходит).
+// Без заголовка - первый из supported; вес формата берётся из самого точного подходящего диапазона (type/subtype, type/*, */*)
+func negotiate(r *http.Request, supported []string) (media string, ok bool) {
+	header := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(header) == "" {
+		return supported[0], true
+	}
+	bestQ := 0.0
+	for _, candidate := range supported {
+		q, specificity := 0.0, -1
+		for _, value := range strings.Split(header, ",") {
+			mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+			rangeSpecificity := -1
+			switch {
+			case mediaRange == candidate:
+				rangeSpecificity = 2
+			case mediaRange == strings.Split(candidate, "/")[0]+"/*":
+				rangeSpecificity = 1
+			case mediaRange == "*/*":
+				rangeSpecificity = 0
+			}
+			if rangeSpecificity <= specificity {
+				continue
+			}
+			specificity, q = rangeSpecificity, 1
+			if weight, found := params["q"]; found {
+				if q, err = strconv.ParseFloat(weight, 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if q > bestQ {
+			media, bestQ = candidate, q
+		}
+	}
+	return media, bestQ > 0
+}
+
+// negotiated Обёртка обработчика задач, отвечающая 406 Not Acceptable до обработки запроса,
+// если клиент не принимает ни JSON, ни XML (запрос не должен успеть ничего изменить)
+func negotiated(handler func(ts *TaskStore) http.HandlerFunc) func(ts *TaskStore) http.HandlerFunc {
+	return func(ts *TaskStore) http.HandlerFunc {
+		next := handler(ts)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := negotiateMedia(r); !ok {
+				logRequest(r, slog.LevelWarn, "[negotiated] Not acceptable", nil, "accept", r.Header.Get("Accept"))
+				writeJSONError(w, http.StatusNotAcceptable, "supported media types are "+strings.Join(supportedMedia, ", "))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// xmlTasks Корневой элемент XML-представления списка задач
+type xmlTasks struct {
+	XMLName xml.Name `xml:"tasks"`
+	Tasks   []Task   `xml:"task"`
+}
+
+// xmlMaskedTask XML-представление задачи, в котором оставлены только поля из маски X-Fields
+// (имена элементов совпадают с именами полей в JSON)
+type xmlMaskedTask struct {
+	task   Task
+	fields map[string]bool
+}
+
+// MarshalXML Сериализация задачи с пропуском элементов верхнего уровня, не вошедших в маску
+func (m xmlMaskedTask) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	data, err := xml.Marshal(m.task)
+	if err != nil {
+		return err
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth, keep := 0, false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			depth++
+			if depth == 2 { // поле задачи
+				keep = m.fields[element.Name.Local]
+			}
+		}
+		if depth >= 2 && keep {
+			if err := e.EncodeToken(xml.CopyToken(token)); err != nil {
+				return err
+			}
+		}
+		if _, ok := token.(xml.EndElement); ok {
+			depth--
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// xmlMaskedTasks Корневой элемент XML-представления списка задач с маской полей
+type xmlMaskedTasks struct {
+	XMLName xml.Name        `xml:"tasks"`
+	Tasks   []xmlMaskedTask `xml:"task"`
+}
+
+// encodeXML Сериализация задачи или списка задач в XML (fields - маска X-Fields, nil означает "все поля")
+func encodeXML(body *bytes.Buffer, v any, fields map[string]bool) error {
+	body.WriteString(xml.Header)
+	encoder := xml.NewEncoder(body)
+	switch v := v.(type) {
+	case []Task:
+		var root any = xmlTasks{Tasks: v}
+		if fields != nil {
+			masked := make([]xmlMaskedTask, len(v))
+			for i, task := range v {
+				masked[i] = xmlMaskedTask{task: task, fields: fields}
+			}
+			root = xmlMaskedTasks{Tasks: masked}
+		}
+		if err := encoder.Encode(root); err != nil {
+			return err
+		}
+	case Task:
+		var element any = v
+		if fields != nil {
+			element = xmlMaskedTask{task: v, fields: fields}
+		}
+		if err := encoder.EncodeElement(element, xml.StartElement{Name: xml.Name{Local: "task"}}); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as XML", v)
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	body.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Проверка выбора формата ответа по заголовку Accept
+// Сценарий:
+// 1. Разобрать разные значения Accept - ожидаем JSON по умолчанию и при равных весах,
+// XML при явном предпочтении и отказ, если не подходит ни один формат.
+func TestNegotiateMedia(t *testing.T) {
+	tests := []struct {
+		accept string
+		media  string
+		ok     bool
+	}{
+		{"", mediaJSON, true},
+		{"*/*", mediaJSON, true},
+		{"application/xml", mediaXML, true},
+		{"application/json;q=0.5, application/xml", mediaXML, true},
+		{"application/xml;q=0.5, application/json", mediaJSON, true},
+		{"application/*;q=0.2, application/xml;q=0", mediaJSON, true},
+		{"text/html", "", false},
+		{"application/json;q=0, */*;q=0.1", mediaXML, true},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "/todos", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		media, ok := negotiateMedia(r)
+		if media != tt.media || ok != tt.ok { // выбран НЕ тот формат
+			t.Errorf("Accept %q: expected %q (%v), got %q (%v)", tt.accept, tt.media, tt.ok, media, ok)
+		}
+	}
+}
+
+// Проверка XML-представления задач
+// Сценарий:
+// 1. Создать задачу и получить её и список с Accept: application/xml - ожидаем XML с той же задачей.
+// 2. Создать задачу с Accept: text/html - ожидаем ошибку (406 Not Acceptable), и задача не создаётся.
+func TestXMLResponses(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	do := func(method, path, accept string, v any) (*http.Response, []byte) {
+		var body []byte
+		if v != nil {
+			body, _ = json.Marshal(v)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, data
+	}
+
+	// Создаём задачу и получаем её в XML
+	do(http.MethodPost, "/todos", "application/json", Task{Title: "Legacy <xml>", Status: StatusNotStarted})
+	resp, data := do(http.MethodGet, "/todos/1", "application/xml", nil)
+	if got := resp.Header.Get("Content-Type"); got != mediaXML { // ответ НЕ в XML
+		t.Fatalf("expected %s, got %q", mediaXML, got)
+	}
+	var task Task
+	if err := xml.Unmarshal(data, &task); err != nil {
+		t.Fatalf("failed to decode XML: %v", err)
+	}
+	if task.ID != 1 || task.Title != "Legacy <xml>" || task.Status != StatusNotStarted { // задача НЕ та
+		t.Errorf("expected task 1, got %+v", task)
+	}
+	resp, data = do(http.MethodGet, "/todos", "application/xml", nil)
+	var list xmlTasks
+	if err := xml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("failed to decode XML: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(list.Tasks) != 1 || list.Tasks[0].ID != 1 { // список НЕ тот
+		t.Errorf("expected list with task 1, got %d %+v", resp.StatusCode, list.Tasks)
+	}
+
+	// Неподдерживаемый формат
+	resp, _ = do(http.MethodPost, "/todos", "text/html", Task{Title: "Rejected", Status: StatusNotStarted})
+	if resp.StatusCode != http.StatusNotAcceptable { // получили НЕ 406
+		t.Errorf("expected 406, got %d", resp.StatusCode)
+	}
+	if tasks := ds.GetAllTasks(); len(tasks) != 1 { // задача создана несмотря на 406
+		t.Errorf("expected 1 task, got %d", len(tasks))
+	}
+}
+
+// Проверка маски X-Fields в XML-ответах
+// Сценарий:
+// 1. Создать задачу с чек-листом.
+// 2. Получить задачу и список с Accept: application/xml и X-Fields: id,checklist - ожидаем только элементы
+// id и checklist (с вложенными пунктами), без title и description.
+func TestXMLFieldsMask(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	if _, err := ds.AddTask(Task{Title: "Secret", Description: "Hidden", Status: StatusNotStarted,
+		Checklist: []ChecklistItem{{Text: "Step"}}}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	for _, path := range []string{"/todos/1", "/todos"} {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		req.Header.Set("Accept", "application/xml")
+		req.Header.Set("X-Fields", "id,checklist")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read response body: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		body := string(data)
+		if strings.Contains(body, "Secret") || strings.Contains(body, "Hidden") || strings.Contains(body, "<status>") { // маска НЕ применена
+			t.Errorf("%s: expected masked XML, got %s", path, body)
+		}
+		if !strings.Contains(body, "<id>1</id>") || !strings.Contains(body, "<checklist><item><text>Step</text>") { // потеряны запрошенные поля
+			t.Errorf("%s: expected id and checklist, got %s", path, body)
+		}
+		var decoded struct {
+			Tasks []Task `xml:"task"`
+		}
+		if path == "/todos" {
+			if err := xml.Unmarshal(data, &decoded); err != nil || len(decoded.Tasks) != 1 { // XML некорректен
+				t.Errorf("expected valid XML list, got %v %s", err, body)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+// statusOrder Порядок вхождения задачи в статус: по времени, при совпадении - по ID
+func statusOrder(a, b Task) int {
+	if c := a.statusSince.Compare(b.statusSince); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// statusNumber Номер задачи в её статусе (вызывается под блокировкой, O(n))
+func (ds *TaskStore) statusNumber(task Task) int {
+	number := 1
+	for _, other := range ds.tasks {
+		if other.Status == task.Status && other.DeletedAt == nil && statusOrder(other, task) < 0 {
+			number++
+		}
+	}
+	return number
+}
+
+// statusNumbers Номера всех задач в их статусах (вызывается под блокировкой, O(n log n))
+func (ds *TaskStore) statusNumbers() map[int]int {
+	byStatus := make(map[TaskStatus][]Task)
+	for _, task := range ds.tasks {
+		if task.DeletedAt == nil {
+			byStatus[task.Status] = append(byStatus[task.Status], task)
+		}
+	}
+	numbers := make(map[int]int, len(ds.tasks))
+	for _, group := range byStatus {
+		slices.SortFunc(group, statusOrder)
+		for i, task := range group {
+			numbers[task.ID] = i + 1
+		}
+	}
+	return numbers
+}
+
+// numberTask Заполняет номер задачи в статусе, если нумерация включена (вызывается под блокировкой)
+func (ds *TaskStore) numberTask(task Task) Task {
+	if ds.config.StatusNumbering {
+		task.StatusNumber = ds.statusNumber(task)
+	}
+	return task
+}
+
+// numberTasks Заполняет номера задач списка в их статусах, если нумерация включена (вызывается под блокировкой)
+func (ds *TaskStore) numberTasks(list []Task) {
+	if !ds.config.StatusNumbering || len(list) == 0 {
+		return
+	}
+	numbers := ds.statusNumbers()
+	for i := range list {
+		list[i].StatusNumber = numbers[list[i].ID]
+	}
+}
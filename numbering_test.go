@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+// Проверка нумерации задач внутри статуса
+// Сценарий:
+// 1. Создать три задачи в статусе not started - ожидаем номера 1, 2, 3.
+// 2. Перевести первую задачу в in progress - ожидаем, что она станет #1 в новом статусе,
+// а оставшиеся задачи сдвинутся на номера 1 и 2.
+func TestStatusNumbering(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.StatusNumbering = true
+	ds := NewTaskStoreWithConfig(config)
+
+	// Создаём задачи
+	for id := 1; id <= 3; id++ {
+		if err := ds.CreateTask(Task{ID: id, Title: "Task", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	for id := 1; id <= 3; id++ {
+		task, err := ds.GetTask(id)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+		if task.StatusNumber != id { // номер НЕ соответствует порядку создания
+			t.Errorf("expected task %d to be #%d, got #%d", id, id, task.StatusNumber)
+		}
+	}
+	// Переводим первую задачу в другой статус
+	moved, err := ds.UpdateTask(1, Task{Title: "Task", Status: StatusInProgress})
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if moved.StatusNumber != 1 { // номер в новом статусе НЕ корректен
+		t.Errorf("expected moved task to be #1, got #%d", moved.StatusNumber)
+	}
+	// Проверяем сдвиг номеров оставшихся задач
+	want := map[int]int{1: 1, 2: 1, 3: 2}
+	for _, task := range ds.GetAllTasks() {
+		if task.StatusNumber != want[task.ID] {
+			t.Errorf("expected task %d to be #%d, got #%d", task.ID, want[task.ID], task.StatusNumber)
+		}
+	}
+}
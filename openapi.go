@@ -0,0 +1,25 @@
+package main
+
+import (
+	_ "embed"
+	"log/slog"
+	"net/http"
+)
+
+// openAPISpec Спецификация API в формате OpenAPI 3.0 (openapi.json в корне репозитория)
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// openAPIHandler Обработчик эндпоинта /openapi.json
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		logRequest(r, slog.LevelWarn, "[openAPIHandler] Invalid method", nil)
+		writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openAPISpec); err != nil {
+		logRequest(r, slog.LevelError, "[openAPIHandler] Writing spec", err)
+	}
+}
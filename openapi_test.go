@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка спецификации OpenAPI
+// Сценарий:
+// 1. Запросить /openapi.json - ожидаем успех (200 OK) и документ OpenAPI 3.
+// 2. Сверить пути документа с маршрутами задач - ожидаем, что описан каждый маршрут из taskRoutes.
+// 3. Сверить перечисление статусов со статусами задачи - ожидаем совпадение.
+func TestOpenAPI(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var spec struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas struct {
+				TaskStatus struct {
+					Enum []TaskStatus `json:"enum"`
+				} `json:"TaskStatus"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if spec.OpenAPI != "3.0.3" { // документ НЕ OpenAPI 3
+		t.Errorf("expected openapi 3.0.3, got %q", spec.OpenAPI)
+	}
+	// Каждый маршрут задач описан в спецификации
+	for _, route := range taskRoutes {
+		if _, ok := spec.Paths[route.pattern]; !ok {
+			t.Errorf("route %s is missing from the spec", route.pattern)
+		}
+	}
+	// Перечисление статусов совпадает с допустимыми статусами
+	statuses := spec.Components.Schemas.TaskStatus.Enum
+	if len(statuses) != len(allowedTransitions) {
+		t.Errorf("expected %d statuses, got %v", len(allowedTransitions), statuses)
+	}
+	for _, status := range statuses {
+		if !status.IsValid() { // в спецификации статус, которого нет в коде
+			t.Errorf("unknown status %q in spec", status)
+		}
+	}
+}
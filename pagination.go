@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 50  // Размер страницы по умолчанию
+	maxPageLimit     = 500 // Максимальный размер страницы
+)
+
+// parsePage Разбор параметров ?limit= и ?offset=
+func parsePage(r *http.Request) (offset, limit int, err error) {
+	limit = defaultPageLimit
+	if r.URL.Query().Has("limit") {
+		limit, err = strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return 0, 0, fmt.Errorf("limit must be an integer between 1 and %d", maxPageLimit)
+		}
+	}
+	if r.URL.Query().Has("offset") {
+		offset, err = strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return offset, limit, nil
+}
+
+// paginate Возвращает страницу списка задач (пустую, если offset за концом списка)
+func paginate(tasks []Task, offset, limit int) []Task {
+	if offset >= len(tasks) {
+		return []Task{}
+	}
+	return tasks[offset:min(offset+limit, len(tasks))]
+}
+
+// encodeCursor Непрозрачный курсор страницы: base64 от ID последней отданной задачи
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// parseCursor Разбор параметра ?cursor= (ok = false, если параметр не передан)
+func parseCursor(r *http.Request) (after int, ok bool, err error) {
+	if !r.URL.Query().Has("cursor") {
+		return 0, false, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(r.URL.Query().Get("cursor"))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid cursor")
+	}
+	after, err = strconv.Atoi(string(raw))
+	if err != nil || after < 0 {
+		return 0, false, fmt.Errorf("invalid cursor")
+	}
+	return after, true, nil
+}
+
+// afterCursor Задачи, идущие после задачи с ID after в списке, отсортированном по ID.
+// Опирается на сами ID, а не на позицию, поэтому вставка и удаление задач не сдвигают следующие страницы
+func afterCursor(tasks []Task, after int, desc bool) []Task {
+	return filterTasks(tasks, func(t Task) bool {
+		if desc {
+			return t.ID < after
+		}
+		return t.ID > after
+	})
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// Проверка постраничной выдачи списка задач
+// Сценарий:
+// 1. Создать 5 задач.
+// 2. Получить страницу ?limit=2&offset=2 - ожидаем задачи 3 и 4 и X-Total-Count: 5.
+// 3. Получить страницу за концом списка - ожидаем пустой массив.
+// 4. Передать отрицательный offset и нечисловой limit - ожидаем ошибку (400 Bad Request).
+func TestPagination(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+
+	// Создаём задачи
+	for range 5 {
+		if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	// Проверяем страницы
+	for query, want := range map[string][]int{
+		"?limit=2&offset=2": {3, 4},
+		"?offset=10":        {},
+	} {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if got := resp.Header.Get("X-Total-Count"); got != "5" { // общее число НЕ передано
+			t.Errorf("query %q: expected X-Total-Count 5, got %q", query, got)
+		}
+		var tasks []Task
+		if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if tasks == nil || len(tasks) != len(want) { // страница НЕ та
+			t.Fatalf("query %q: expected %v, got %+v", query, want, tasks)
+		}
+		for i, id := range want {
+			if tasks[i].ID != id {
+				t.Errorf("query %q: expected id %d at %d, got %d", query, id, i, tasks[i].ID)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Передаём некорректные параметры
+	for _, query := range []string{"?offset=-1", "?limit=abc", "?limit=501"} {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+			t.Errorf("query %q: expected 400, got %d", query, resp.StatusCode)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
+
+// Проверка постраничной выдачи по курсору
+// Сценарий:
+// 1. Создать 5 задач и получить первую страницу ?limit=2 - ожидаем задачи 1 и 2 и X-Next-Cursor.
+// 2. Удалить задачу 2 и создать задачу 6, затем продолжить обход по курсору - ожидаем задачи 3, 4, 5, 6
+// без пропусков и повторов, а у последней страницы нет X-Next-Cursor.
+// 3. Передать некорректный курсор, курсор вместе с offset и курсор с sort=title - ожидаем ошибку (400 Bad Request).
+func TestCursorPagination(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	get := func(query string) (*http.Response, []Task) {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, tasks
+	}
+
+	// Создаём задачи и получаем первую страницу
+	for range 5 {
+		if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	resp, page := get("?limit=2")
+	var seen []int
+	for _, task := range page {
+		seen = append(seen, task.ID)
+	}
+	cursor := resp.Header.Get("X-Next-Cursor")
+	if cursor == "" { // курсор следующей страницы НЕ выдан
+		t.Fatal("expected X-Next-Cursor on first page")
+	}
+	// Меняем набор задач посреди обхода
+	if err := ds.DeleteTask(2, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	// Продолжаем обход по курсору
+	for cursor != "" {
+		resp, page = get("?limit=2&cursor=" + cursor)
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		for _, task := range page {
+			seen = append(seen, task.ID)
+		}
+		cursor = resp.Header.Get("X-Next-Cursor")
+	}
+	if !slices.Equal(seen, []int{1, 2, 3, 4, 5, 6}) { // обход с пропусками или повторами
+		t.Errorf("expected ids [1 2 3 4 5 6], got %v", seen)
+	}
+
+	// Некорректные запросы
+	for _, query := range []string{"?cursor=!!!", "?cursor=" + encodeCursor(2) + "&offset=1", "?cursor=" + encodeCursor(2) + "&sort=title"} {
+		if resp, _ := get(query); resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+			t.Errorf("query %q: expected 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// defaultAdminAddr Адрес служебного listener'а по умолчанию: только локальные подключения
+const defaultAdminAddr = "localhost:6060"
+
+// newPprofMux Маршруты net/http/pprof под /debug/pprof/. Обслуживаются отдельным служебным listener'ом
+// (-enable-pprof, -admin-addr), чтобы профили не были доступны снаружи вместе с API
+func newPprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index) // в том числе именованные профили: goroutine, heap, allocs...
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// newAdminMux Маршруты служебного listener'а: профили pprof (с -enable-pprof)
+// и перечитывание списка исполнителей (если список задан)
+func newAdminMux(enablePprof bool, users *Users) *http.ServeMux {
+	mux := http.NewServeMux()
+	if enablePprof {
+		mux.Handle("/debug/pprof/", newPprofMux())
+	}
+	if users != nil {
+		mux.HandleFunc("/admin/users/reload", reloadUsersHandler(users))
+	}
+	return mux
+}
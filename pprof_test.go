@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Проверка эндпоинтов pprof
+// Сценарий:
+// 1. Запросить /debug/pprof/ и /debug/pprof/goroutine?debug=1 у служебного обработчика - ожидаем успех (200 OK)
+// и дамп горутин во втором ответе.
+// 2. Запросить /debug/pprof/ у основного сервера - ожидаем ошибку (404 Not Found): профили не публикуются вместе с API.
+func TestPprof(t *testing.T) {
+	admin := httptest.NewServer(newPprofMux())
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1"} {
+		resp, err := http.Get(admin.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Errorf("%s: expected 200, got %d", path, resp.StatusCode)
+		}
+		if strings.Contains(path, "goroutine") && !strings.Contains(string(body), "goroutine") { // НЕ дамп горутин
+			t.Errorf("expected goroutine dump, got %q", body)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	admin.Close()
+
+	ts := startTestServer()
+	resp, err := http.Get(ts.URL + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound { // профили доступны через API
+		t.Errorf("expected 404 from API server, got %d", resp.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// preferredReturn Разбор заголовка Prefer: "minimal", "representation" или "" (предпочтение не указано)
+func preferredReturn(r *http.Request) string {
+	for _, value := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(value, ",") {
+			preference, _, _ = strings.Cut(preference, ";") // параметры предпочтения не поддерживаются
+			name, token, _ := strings.Cut(preference, "=")
+			if !strings.EqualFold(strings.TrimSpace(name), "return") {
+				continue
+			}
+			switch token = strings.Trim(strings.TrimSpace(token), `"`); token {
+			case "minimal", "representation":
+				return token
+			}
+		}
+	}
+	return ""
+}
+
+// writeMutationResult Ответ на POST/PUT/PATCH с учётом Prefer: return=minimal|representation.
+// withBody определяет, отдаётся ли задача в теле, если клиент ничего не предпочёл
+func writeMutationResult(w http.ResponseWriter, r *http.Request, status int, task Task, location string, withBody bool) error {
+	w.Header().Set("ETag", taskETag(task))
+	switch preferredReturn(r) {
+	case "minimal":
+		w.Header().Set("Preference-Applied", "return=minimal")
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	case "representation":
+		w.Header().Set("Preference-Applied", "return=representation")
+	default:
+		if !withBody {
+			w.WriteHeader(status)
+			return nil
+		}
+	}
+	return writeTaskJSON(w, r, status, task)
+}
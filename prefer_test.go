@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// Проверка обработки заголовка Prefer
+// Сценарий:
+// 1. Создать задачу с Prefer: return=representation - ожидаем 201 и задачу в теле.
+// 2. Обновить задачу с Prefer: return=minimal - ожидаем 204 без тела, Location и Preference-Applied.
+// 3. Обновить задачу без Prefer - ожидаем 200 и задачу в теле, без Preference-Applied.
+func TestPreferReturn(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{Title: "Preferred", Status: StatusNotStarted})
+	// Создаём задачу с запросом полного представления
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+"/todos", bytes.NewBuffer(body))
+	req.Header.Set("Prefer", "return=representation")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	var created Task
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated || created.ID != 1 { // представление НЕ отдано
+		t.Errorf("expected 201 with task, got %d %+v", resp.StatusCode, created)
+	}
+	if got := resp.Header.Get("Preference-Applied"); got != "return=representation" {
+		t.Errorf("unexpected Preference-Applied %q", got)
+	}
+	// Обновляем задачу с минимальным ответом
+	req, _ = http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	req.Header.Set("Prefer", "return=minimal")
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	data, _ := io.ReadAll(resp2.Body)
+	if resp2.StatusCode != http.StatusNoContent || len(data) != 0 { // ответ НЕ минимальный
+		t.Errorf("expected empty 204, got %d with %q", resp2.StatusCode, data)
+	}
+	if resp2.Header.Get("Location") != "/todos/1" || resp2.Header.Get("Preference-Applied") != "return=minimal" {
+		t.Errorf("unexpected headers %v", resp2.Header)
+	}
+	// Обновляем задачу без предпочтений
+	req, _ = http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp3, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	if resp3.StatusCode != http.StatusOK || resp3.Header.Get("Preference-Applied") != "" { // поведение по умолчанию изменилось
+		t.Errorf("expected plain 200, got %d %v", resp3.StatusCode, resp3.Header)
+	}
+	for _, r := range []*http.Response{resp, resp2, resp3} {
+		if err := r.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
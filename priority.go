@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TaskPriority Приоритет задачи
+type TaskPriority string
+
+const (
+	PriorityLow    TaskPriority = "low"
+	PriorityMedium TaskPriority = "medium"
+	PriorityHigh   TaskPriority = "high"
+)
+
+// IsValid Проверка валидности приоритета задачи (что он один из предопределённых)
+func (p TaskPriority) IsValid() bool {
+	return p == PriorityLow || p == PriorityMedium || p == PriorityHigh
+}
+
+// priorityRank Порядок приоритетов при сортировке (по возрастанию важности)
+var priorityRank = map[TaskPriority]int{PriorityLow: 0, PriorityMedium: 1, PriorityHigh: 2}
+
+// parsePriorityFilter Разбор параметра ?priority= (ok = false, если параметр не передан)
+func parsePriorityFilter(r *http.Request) (priority TaskPriority, ok bool, err error) {
+	if !r.URL.Query().Has("priority") {
+		return "", false, nil
+	}
+	priority = TaskPriority(r.URL.Query().Get("priority"))
+	if !priority.IsValid() {
+		return "", false, fmt.Errorf("invalid priority %q: must be one of %q, %q, %q",
+			priority, PriorityLow, PriorityMedium, PriorityHigh)
+	}
+	return priority, true, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка приоритета задач
+// Сценарий:
+// 1. Создать задачи с приоритетом high, без приоритета и с приоритетом low - ожидаем приоритет medium по умолчанию.
+// 2. Создать задачу с неизвестным приоритетом - ожидаем ошибку (422 Unprocessable Entity).
+// 3. Получить список с ?sort=priority&order=desc - ожидаем порядок high, medium, low.
+// 4. Получить список с ?priority=low - ожидаем одну задачу, с ?priority=urgent - ошибку (400 Bad Request).
+func TestPriority(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём задачи
+	for _, priority := range []TaskPriority{PriorityHigh, "", PriorityLow, "urgent"} {
+		body, _ := json.Marshal(Task{Title: "Task", Status: StatusNotStarted, Priority: priority})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var created Task
+		if resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		switch priority {
+		case "urgent":
+			if resp.StatusCode != http.StatusUnprocessableEntity { // получили НЕ 422
+				t.Errorf("expected 422 for unknown priority, got %d", resp.StatusCode)
+			}
+		case "":
+			if created.Priority != PriorityMedium { // приоритет по умолчанию НЕ medium
+				t.Errorf("expected default priority %q, got %q", PriorityMedium, created.Priority)
+			}
+		}
+	}
+	// Проверяем сортировку и фильтр
+	get := func(query string) ([]Task, int) {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		_ = json.NewDecoder(resp.Body).Decode(&tasks)
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return tasks, resp.StatusCode
+	}
+	tasks, _ := get("?sort=priority&order=desc")
+	want := []TaskPriority{PriorityHigh, PriorityMedium, PriorityLow}
+	if len(tasks) != len(want) {
+		t.Fatalf("expected %d tasks, got %d", len(want), len(tasks))
+	}
+	for i, task := range tasks {
+		if task.Priority != want[i] { // порядок НЕ по убыванию приоритета
+			t.Errorf("position %d: expected %q, got %q", i, want[i], task.Priority)
+		}
+	}
+	if tasks, _ := get("?priority=low"); len(tasks) != 1 || tasks[0].Priority != PriorityLow {
+		t.Errorf("expected one low priority task, got %+v", tasks)
+	}
+	if _, status := get("?priority=urgent"); status != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for unknown priority filter, got %d", status)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// readyzPingTimeout Сколько /readyz ждёт ответа хранилища
+const readyzPingTimeout = 2 * time.Second
+
+// Pinger Хранилище, умеющее проверить свою доступность (например, соединение с базой данных)
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping Хранилище в памяти доступно всегда, пока жив процесс
+func (ds *TaskStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// probePaths Пути проверок здоровья (их ответы не сжимаются)
+var probePaths = []string{"/healthz", "/livez", "/readyz"}
+
+// livezHandler Обработчик эндпоинта /livez (процесс жив, пока отвечает)
+func livezHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// readyzHandler Обработчик эндпоинта /readyz: 200, когда сервер готов принимать трафик, иначе 503
+// (до окончания инициализации, во время остановки и когда хранилище не отвечает на Ping)
+func readyzHandler(ready *atomic.Bool, s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if !ready.Load() {
+			logRequest(r, slog.LevelDebug, "[readyzHandler] Not ready", nil)
+			setRetryAfter(w, defaultRetryAfter)
+			writeJSONError(w, http.StatusServiceUnavailable, "not ready")
+			return
+		}
+		if pinger, ok := s.(Pinger); ok {
+			ctx, cancel := context.WithTimeout(r.Context(), readyzPingTimeout)
+			defer cancel()
+			if err := pinger.Ping(ctx); err != nil { // хранилище недоступно, трафик сюда направлять нельзя
+				logRequest(r, slog.LevelWarn, "[readyzHandler] Store ping failed", err)
+				setRetryAfter(w, defaultRetryAfter)
+				writeErrorResponse(w, ErrorResponse{
+					Error:      "store is unavailable: " + err.Error(),
+					Status:     http.StatusServiceUnavailable,
+					Dependency: "store",
+				})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// Проверка проб живости и готовности
+// Сценарий:
+// 1. До окончания инициализации запросить /livez и /readyz - ожидаем 200 и 503 с Retry-After.
+// 2. Отметить сервер готовым - ожидаем /readyz 200.
+// 3. Снять готовность (как при остановке) - ожидаем /readyz 503, /livez по-прежнему 200.
+func TestProbes(t *testing.T) {
+	ready := new(atomic.Bool)
+	ts := httptest.NewServer(newMux(NewTaskStore(), NewTenantRegistry(DefaultStoreConfig()), NewStats(), ready))
+	defer ts.Close()
+
+	check := func(path string, want int) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if resp.StatusCode != want { // статус пробы НЕ соответствует состоянию сервера
+			t.Errorf("GET %s (ready=%v): expected %d, got %d", path, ready.Load(), want, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Retry-After"); (want == http.StatusServiceUnavailable) != (got == "5") { // Retry-After НЕ соответствует ответу
+			t.Errorf("GET %s (ready=%v): unexpected Retry-After %q for %d", path, ready.Load(), got, resp.StatusCode)
+		}
+	}
+	// Инициализация
+	check("/livez", http.StatusOK)
+	check("/readyz", http.StatusServiceUnavailable)
+	// Сервер готов
+	ready.Store(true)
+	check("/readyz", http.StatusOK)
+	// Остановка
+	ready.Store(false)
+	check("/readyz", http.StatusServiceUnavailable)
+	check("/livez", http.StatusOK)
+}
+
+// unreachableStore Хранилище, не отвечающее на Ping (как база данных, до которой нет соединения)
+type unreachableStore struct {
+	*TaskStore
+}
+
+// Ping Проверка доступности всегда завершается ошибкой
+func (unreachableStore) Ping(context.Context) error {
+	return errors.New("connection refused")
+}
+
+// Проверка /readyz с проверкой хранилища
+// Сценарий:
+// 1. Запросить /readyz у готового сервера с хранилищем в памяти - ожидаем успех (200 OK).
+// 2. Запросить /readyz у готового сервера с недоступным хранилищем - ожидаем ошибку (503 Service Unavailable)
+// с dependency "store" в теле и Retry-After.
+func TestReadyzStorePing(t *testing.T) {
+	ready := new(atomic.Bool)
+	ready.Store(true)
+	for _, c := range []struct {
+		name       string
+		store      Store
+		want       int
+		dependency string
+	}{
+		{"memory", NewTaskStore(), http.StatusOK, ""},
+		{"unreachable", unreachableStore{NewTaskStore()}, http.StatusServiceUnavailable, "store"},
+	} {
+		ts := httptest.NewServer(readyzHandler(ready, c.store))
+		resp, err := http.Get(ts.URL)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if resp.StatusCode != c.want { // готовность НЕ учитывает хранилище
+			t.Errorf("%s: expected %d, got %d", c.name, c.want, resp.StatusCode)
+		}
+		if c.dependency != "" {
+			var body ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode error body: %v", err)
+			}
+			if body.Dependency != c.dependency { // в ответе НЕ названа упавшая зависимость
+				t.Errorf("%s: expected dependency %q, got %+v", c.name, c.dependency, body)
+			}
+			if got := resp.Header.Get("Retry-After"); got != "5" { // клиенту НЕ сказано, когда повторить
+				t.Errorf("%s: expected Retry-After 5, got %q", c.name, got)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProgressUpdate Тело запроса PATCH /todos/{id}/progress
+type ProgressUpdate struct {
+	Progress *int `json:"progress"`
+}
+
+// validateProgress Проверка, что прогресс лежит в диапазоне 0-100
+func validateProgress(progress int) error {
+	if progress < 0 || progress > 100 {
+		return fmt.Errorf("progress must be between 0 and 100")
+	}
+	return nil
+}
+
+// coupleProgress Согласует прогресс с переходом статуса: завершённая задача - 100, переоткрытая - 0
+// (вызывается под блокировкой, если связь статуса и прогресса включена)
+func (ds *TaskStore) coupleProgress(from TaskStatus, task *Task) {
+	if !ds.config.ProgressFollowsStatus {
+		return
+	}
+	switch {
+	case task.Status == StatusCompleted:
+		task.Progress = 100
+	case from == StatusCompleted: // задачу переоткрыли
+		task.Progress = 0
+	}
+}
+
+// SetProgress Обновляет прогресс задачи по ID (version, если не 0, - ожидаемая версия задачи)
+func (ds *TaskStore) SetProgress(id int, progress int, version int) (Task, error) {
+	ds.mutex.Lock()
+	task, ok := ds.liveTask(id)
+	if !ok { // задача с таким ID не найдена
+		ds.mutex.Unlock()
+		err := fmt.Errorf("task with id %d not found", id)
+		slog.Warn("[SetProgress] Task not found", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if err := checkVersion(task, version); err != nil { // задачу успели изменить
+		ds.mutex.Unlock()
+		slog.Warn("[SetProgress] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	before := task
+	task.Progress = progress
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	ds.tasks[id] = task
+	task = ds.numberTask(task)
+	ds.record(TaskEventUpdated, &before, &task)
+	ds.mutex.Unlock()
+	return task, nil
+}
+
+// parseMinProgress Разбор параметра ?min_progress= (ok = false, если параметр не передан)
+func parseMinProgress(r *http.Request) (minProgress int, ok bool, err error) {
+	if !r.URL.Query().Has("min_progress") {
+		return 0, false, nil
+	}
+	minProgress, err = strconv.Atoi(r.URL.Query().Get("min_progress"))
+	if err != nil {
+		return 0, false, fmt.Errorf("min_progress must be an integer")
+	}
+	if err := validateProgress(minProgress); err != nil {
+		return 0, false, fmt.Errorf("min_%w", err)
+	}
+	return minProgress, true, nil
+}
+
+// progressHandler Обработчик эндпоинта /todos/{id}/progress
+func progressHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			logRequest(r, slog.LevelWarn, "[progressHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[progressHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var update ProgressUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			logRequest(r, slog.LevelWarn, "[progressHandler] Decoding", err, "task_id", id)
+			writeDecodeError(w, err)
+			return
+		}
+		if update.Progress == nil {
+			logRequest(r, slog.LevelWarn, "[progressHandler] Missing progress", nil, "task_id", id)
+			writeJSONError(w, http.StatusBadRequest, "progress is required")
+			return
+		}
+		if err := validateProgress(*update.Progress); err != nil {
+			logRequest(r, slog.LevelWarn, "[progressHandler] Validation", err, "task_id", id)
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		task, err := ts.SetProgress(id, *update.Progress, ifMatchVersion(r))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[progressHandler] Updating progress", err, "task_id", id)
+			writeJSONError(w, updateErrorStatus(err), err.Error())
+			return
+		}
+		location := strings.TrimSuffix(r.URL.Path, "/progress")
+		if err := writeMutationResult(w, r, http.StatusOK, task, location, true); err != nil {
+			logRequest(r, slog.LevelError, "[progressHandler] Encoding task", err, "task_id", id)
+			return
+		}
+	}
+}
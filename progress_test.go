@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// patchProgress Отправка нового значения прогресса задачи
+func patchProgress(t *testing.T, url string, body string) *http.Response {
+	req, _ := http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PATCH: %v", err)
+	}
+	return resp
+}
+
+// Проверка обновления прогресса и фильтрации по нему
+// Сценарий:
+// 1. Создать задачу и выставить прогресс 50 - ожидаем успех (200 OK).
+// 2. Выставить прогресс 150 - ожидаем ошибку (400 Bad Request).
+// 3. Получить задачи с min_progress=50 и min_progress=60 - ожидаем одну задачу и пустой список.
+func TestProgress(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{ID: 1, Title: "Long running", Status: StatusInProgress})
+	// Создаём задачу
+	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	// Выставляем прогресс
+	resp := patchProgress(t, ts.URL+"/todos/1/progress", `{"progress":50}`)
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+	// Выставляем прогресс вне диапазона
+	resp2 := patchProgress(t, ts.URL+"/todos/1/progress", `{"progress":150}`)
+	if resp2.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", resp2.StatusCode)
+	}
+	// Фильтруем задачи по прогрессу
+	for query, want := range map[string]int{"50": 1, "60": 0} {
+		resp3, err := http.Get(ts.URL + "/todos?min_progress=" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		if err := json.NewDecoder(resp3.Body).Decode(&tasks); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(tasks) != want { // фильтр НЕ сработал
+			t.Errorf("expected %d tasks for min_progress=%s, got %d", want, query, len(tasks))
+		}
+		if err := resp3.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp2.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка связи прогресса со статусом
+// Сценарий:
+// 1. Завершить задачу с прогрессом 30 - ожидаем прогресс 100.
+// 2. Вернуть задачу в работу из not started после исправления статуса - ожидаем, что прогресс не сбрасывается.
+// 3. Сбросить прогресс при переоткрытии (если политика переходов это разрешает) - проверяем напрямую.
+func TestProgressFollowsStatus(t *testing.T) {
+	ds := NewTaskStore()
+	if err := ds.CreateTask(Task{ID: 1, Title: "Task", Status: StatusInProgress, Progress: 30}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	// Завершаем задачу
+	task, err := ds.UpdateTask(1, Task{Title: "Task", Status: StatusCompleted, Progress: 30})
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if task.Progress != 100 { // прогресс НЕ выставлен
+		t.Errorf("expected progress 100 on completion, got %d", task.Progress)
+	}
+	// Переход между незавершёнными статусами прогресс не трогает
+	if err := ds.CreateTask(Task{ID: 2, Title: "Task", Status: StatusInProgress, Progress: 40}); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	task, err = ds.UpdateTask(2, Task{Title: "Task", Status: StatusNotStarted, Progress: 40})
+	if err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if task.Progress != 40 { // прогресс изменён без причины
+		t.Errorf("expected progress 40 to be kept, got %d", task.Progress)
+	}
+	// Переоткрытие завершённой задачи
+	reopened := Task{Status: StatusInProgress, Progress: 100}
+	ds.coupleProgress(StatusCompleted, &reopened)
+	if reopened.Progress != 0 { // прогресс НЕ сброшен
+		t.Errorf("expected progress 0 on reopen, got %d", reopened.Progress)
+	}
+}
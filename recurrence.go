@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// Recurrence Периодичность задачи
+type Recurrence string
+
+const (
+	RecurrenceNone    Recurrence = "none"
+	RecurrenceDaily   Recurrence = "daily"
+	RecurrenceWeekly  Recurrence = "weekly"
+	RecurrenceMonthly Recurrence = "monthly"
+)
+
+// IsValid Проверка валидности периодичности задачи (что она одна из предопределённых)
+func (r Recurrence) IsValid() bool {
+	return r == RecurrenceNone || r == RecurrenceDaily || r == RecurrenceWeekly || r == RecurrenceMonthly
+}
+
+// next Следующий момент повторения
+func (r Recurrence) next(t time.Time) time.Time {
+	switch r {
+	case RecurrenceDaily:
+		return t.AddDate(0, 0, 1)
+	case RecurrenceWeekly:
+		return t.AddDate(0, 0, 7)
+	case RecurrenceMonthly:
+		return t.AddDate(0, 1, 0)
+	}
+	return t
+}
+
+// nextOccurrence Следующий экземпляр повторяющейся задачи: те же поля, статус и чек-лист сброшены, срок сдвинут.
+// Внешний идентификатор не копируется, ID и служебные поля назначаются при вставке
+func nextOccurrence(task Task) Task {
+	next := Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      StatusNotStarted,
+		Priority:    task.Priority,
+		Recurrence:  task.Recurrence,
+		ParentID:    task.ParentID,
+		Assignee:    task.Assignee,
+		Watchers:    slices.Clone(task.Watchers),
+	}
+	if task.DueAt != nil {
+		dueAt := task.Recurrence.next(*task.DueAt)
+		next.DueAt = &dueAt
+	}
+	for _, item := range task.Checklist {
+		next.Checklist = append(next.Checklist, ChecklistItem{Text: item.Text})
+	}
+	return next
+}
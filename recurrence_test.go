@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Проверка порождения следующего экземпляра повторяющейся задачи
+// Сценарий:
+// 1. Создать еженедельную задачу со сроком и чек-листом и обычную задачу.
+// 2. Завершить еженедельную задачу - ожидаем новую задачу с новым ID, статусом not started,
+// сброшенным чек-листом и сроком на неделю позже.
+// 3. Завершить обычную задачу - ожидаем, что новых задач не появилось.
+func TestRecurrence(t *testing.T) {
+	ds := NewTaskStore()
+	due := time.Date(2030, 1, 31, 9, 0, 0, 0, time.UTC)
+	weekly, err := ds.AddTask(Task{Title: "Chores", Status: StatusInProgress, Recurrence: RecurrenceWeekly, DueAt: &due,
+		Checklist: []ChecklistItem{{Text: "Dishes", Done: true}}})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	plain, err := ds.AddTask(Task{Title: "Once", Status: StatusInProgress, Recurrence: RecurrenceNone})
+	if err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+
+	// Завершаем повторяющуюся задачу
+	completed := weekly
+	completed.Status = StatusCompleted
+	completed.Version = 0
+	if _, err := ds.UpdateTask(weekly.ID, completed); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	next, err := ds.GetTask(3)
+	if err != nil { // следующий экземпляр НЕ создан
+		t.Fatalf("expected next occurrence with id 3: %v", err)
+	}
+	if next.Status != StatusNotStarted || next.Recurrence != RecurrenceWeekly || next.Title != "Chores" {
+		t.Errorf("unexpected next occurrence %+v", next)
+	}
+	if next.DueAt == nil || !next.DueAt.Equal(due.AddDate(0, 0, 7)) { // срок НЕ сдвинут на неделю
+		t.Errorf("expected due_at %v, got %v", due.AddDate(0, 0, 7), next.DueAt)
+	}
+	if len(next.Checklist) != 1 || next.Checklist[0].Done || next.ChecklistDone != 0 { // чек-лист НЕ сброшен
+		t.Errorf("expected reset checklist, got %+v", next.Checklist)
+	}
+	// Завершаем обычную задачу
+	plain.Status = StatusCompleted
+	plain.Version = 0
+	if _, err := ds.UpdateTask(plain.ID, plain); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if got := len(ds.GetAllTasks()); got != 3 { // появилась лишняя задача
+		t.Errorf("expected 3 tasks, got %d", got)
+	}
+}
+
+// Проверка сдвига срока для разных периодичностей
+func TestRecurrenceNext(t *testing.T) {
+	from := time.Date(2030, 1, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		recurrence Recurrence
+		want       time.Time
+	}{
+		{RecurrenceDaily, time.Date(2030, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{RecurrenceWeekly, time.Date(2030, 1, 22, 9, 0, 0, 0, time.UTC)},
+		{RecurrenceMonthly, time.Date(2030, 2, 15, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.recurrence.next(from); !got.Equal(tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.recurrence, tt.want, got)
+		}
+	}
+}
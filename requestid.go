@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+)
+
+// requestIDPattern Допустимый формат входящего X-Request-ID (иначе генерируется новый, чтобы не засорять журнал)
+var requestIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// requestIDKey Ключ ID запроса в контексте
+type requestIDKey struct{}
+
+// newRequestID Генерация случайного UUID версии 4
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])  // crypto/rand.Read не возвращает ошибок
+	b[6] = b[6]&0x0f | 0x40 // версия 4
+	b[8] = b[8]&0x3f | 0x80 // вариант RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// RequestIDFromContext Возвращает ID запроса из контекста (пустая строка, если его нет)
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDMiddleware Middleware, назначающее запросу ID (из заголовка X-Request-ID или новый)
+// и возвращающее его в ответе
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// requestIDHandler Обёртка над slog.Handler, добавляющая в записи ID запроса из контекста
+type requestIDHandler struct {
+	slog.Handler
+}
+
+// Handle Добавляет поле request_id, если запись сделана в контексте запроса
+func (h requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs Сохраняет обёртку при добавлении полей
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup Сохраняет обёртку при создании группы
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+// Проверка назначения ID запроса
+// Сценарий:
+// 1. Отправить запрос без X-Request-ID - ожидаем сгенерированный UUID в ответе.
+// 2. Отправить запрос с X-Request-ID - ожидаем тот же ID в ответе и в записи журнала.
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "debug")
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+	ts := startTestServer()
+
+	// Запрос без ID
+	resp, err := http.Get(ts.URL + "/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := resp.Header.Get("X-Request-ID"); !uuid.MatchString(id) { // ID НЕ сгенерирован
+		t.Errorf("expected generated UUID, got %q", id)
+	}
+	// Запрос с ID, который приводит к записи в журнал
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/todos/42", nil)
+	req.Header.Set("X-Request-ID", "trace-123")
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if id := resp2.Header.Get("X-Request-ID"); id != "trace-123" { // ID НЕ возвращён
+		t.Errorf("expected echoed request id, got %q", id)
+	}
+	// Ищем запись обработчика с этим ID
+	found := false
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err == nil && entry["request_id"] == "trace-123" {
+			found = true
+		}
+	}
+	if !found { // ID НЕ попал в журнал
+		t.Errorf("expected log entry with request_id, got %s", buf.String())
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp2.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// RoleAdmin Роль, которой разрешено удалять задачи
+const RoleAdmin = "admin"
+
+// HasRole Проверка, что у клиента есть роль
+func (i Identity) HasRole(role string) bool {
+	return slices.Contains(i.Roles, role)
+}
+
+// requireAdminForDelete Middleware, пропускающее DELETE-запросы только клиентов с ролью admin (иначе 403).
+// Ставится внутри middleware аутентификации; остальные методы доступны всем аутентифицированным клиентам
+func requireAdminForDelete(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if identity, ok := IdentityFromContext(r.Context()); !ok || !identity.HasRole(RoleAdmin) {
+			logRequest(r, slog.LevelWarn, "[requireAdminForDelete] Forbidden", nil, "subject", identity.Subject)
+			writeErrorResponse(w, ErrorResponse{
+				Error:        "deleting tasks requires the admin role",
+				Status:       http.StatusForbidden,
+				RequiredRole: RoleAdmin,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Проверка ограничения удаления ролью admin
+// Сценарий:
+// 1. Настроить ключи API: reader без роли и ops с ролью admin.
+// 2. Выполнить GET и POST с ключом reader - ожидаем, что запросы проходят.
+// 3. Выполнить DELETE с ключом reader - ожидаем 403 Forbidden с требуемой ролью в теле.
+// 4. Выполнить DELETE с ключом ops - ожидаем, что запрос проходит.
+func TestRequireAdminForDelete(t *testing.T) {
+	keys, err := loadAPIKeys("reader:r-secret,ops:o-secret:admin", "")
+	if err != nil {
+		t.Fatalf("failed to load keys: %v", err)
+	}
+	handler := apiKeyMiddleware(keys, requireAdminForDelete(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	tests := []struct {
+		method string
+		key    string
+		status int
+	}{
+		{http.MethodGet, "r-secret", http.StatusNoContent},
+		{http.MethodPost, "r-secret", http.StatusNoContent},
+		{http.MethodDelete, "r-secret", http.StatusForbidden},
+		{http.MethodDelete, "o-secret", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/todos/1", nil)
+		req.Header.Set("X-API-Key", tt.key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status { // неожиданный статус
+			t.Errorf("%s with %s: expected %d, got %d", tt.method, tt.key, tt.status, rec.Code)
+		}
+		if rec.Code == http.StatusForbidden {
+			var errResp ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.RequiredRole != RoleAdmin { // требуемая роль НЕ указана
+				t.Errorf("expected required_role %q, got %q", RoleAdmin, errResp.RequiredRole)
+			}
+		}
+	}
+}
+
+// Проверка ролей из claims role и roles в JWT
+func TestJWTRoles(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier := NewHMACVerifier(secret)
+	var identity Identity
+	handler := jwtMiddleware(verifier, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = IdentityFromContext(r.Context())
+	}))
+	for _, claims := range []map[string]any{{"sub": "a", "role": "admin"}, {"sub": "b", "roles": []string{"viewer", "admin"}}} {
+		req := httptest.NewRequest(http.MethodDelete, "/todos/1", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, "HS256", secret, claims))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if !identity.HasRole(RoleAdmin) { // роль НЕ прочитана из токена
+			t.Errorf("%v: expected admin role, got %v", claims, identity.Roles)
+		}
+	}
+}
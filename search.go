@@ -0,0 +1,24 @@
+package main
+
+import "strings"
+
+// matchesQuery Проверка, что заголовок или описание задачи содержат строку (query уже в нижнем регистре)
+func matchesQuery(task Task, query string) bool {
+	return strings.Contains(strings.ToLower(task.Title), query) ||
+		strings.Contains(strings.ToLower(task.Description), query)
+}
+
+// Search Возвращает задачи, в заголовке или описании которых есть строка query (без учёта регистра)
+func (ds *TaskStore) Search(query string) []Task {
+	query = strings.ToLower(query)
+	ds.mutex.RLock()
+	list := make([]Task, 0)
+	for _, t := range ds.tasks {
+		if t.DeletedAt == nil && matchesQuery(t, query) {
+			list = append(list, t)
+		}
+	}
+	ds.numberTasks(list)
+	ds.mutex.RUnlock()
+	return list
+}
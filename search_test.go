@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// Проверка поиска задач по тексту
+// Сценарий:
+// 1. Создать три задачи, две из которых упоминают отчёт в заголовке или описании.
+// 2. Найти задачи по ?q=ОТЧЁТ - ожидаем две задачи (поиск без учёта регистра по обоим полям).
+// 3. Найти задачи по ?q= вместе с ?status=in progress - ожидаем одну задачу.
+// 4. Передать пустой ?q= - ожидаем полный список.
+func TestSearch(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём задачи
+	for _, task := range []Task{
+		{Title: "Квартальный отчёт", Status: StatusNotStarted},
+		{Title: "Письмо", Description: "Приложить отчёт", Status: StatusInProgress},
+		{Title: "Созвон", Status: StatusNotStarted},
+	} {
+		body, _ := json.Marshal(task)
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Проверяем выдачу
+	for query, want := range map[string]int{
+		"?q=" + url.QueryEscape("ОТЧЁТ"):                                               2,
+		"?q=" + url.QueryEscape("отчёт") + "&status=" + url.QueryEscape("in progress"): 1,
+		"?q=": 3,
+	} {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if len(tasks) != want { // поиск вернул НЕ те задачи
+			t.Errorf("GET %s: expected %d tasks, got %d", query, want, len(tasks))
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "fmt"
+
+// runSelfTest Проверка работоспособности хранилища при запуске: создание, чтение, обновление и удаление задачи
+// во временном хранилище с заданными настройками. Хранилище не пишет в журнал изменений, статистику
+// и уведомления, поэтому проверка не оставляет следов
+func runSelfTest(config StoreConfig) error {
+	config.AuditLog = nil
+	config.Stats = nil
+	config.Notify = nil
+	ds := NewTaskStoreWithConfig(config)
+
+	task, err := ds.AddTask(Task{Title: "self-test", Description: "startup self-test", Status: StatusNotStarted})
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	got, err := ds.GetTask(task.ID)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	if got.Title != task.Title || got.Status != task.Status {
+		return fmt.Errorf("get: unexpected task %+v", got)
+	}
+	task.Status = StatusInProgress
+	if _, err := ds.UpdateTask(task.ID, task); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	if got, err = ds.GetTask(task.ID); err != nil || got.Status != StatusInProgress {
+		return fmt.Errorf("get after update: task %+v, error %v", got, err)
+	}
+	if err := ds.DeleteTask(task.ID, 0); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	if _, err := ds.GetTask(task.ID); err == nil {
+		return fmt.Errorf("get after delete: task still exists")
+	}
+	return nil
+}
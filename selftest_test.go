@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Проверка самотестирования хранилища
+// Сценарий:
+// 1. Запустить самотест с журналом изменений, статистикой и каналом уведомлений - ожидаем успех.
+// 2. Проверить, что журнал пуст, счётчики задач не изменились и уведомлений не было.
+func TestSelfTest(t *testing.T) {
+	var audit bytes.Buffer
+	notify := make(chan TaskEvent, 16)
+	config := DefaultStoreConfig()
+	config.AuditLog = &audit
+	config.Stats = NewStats()
+	config.Notify = notify
+
+	if err := runSelfTest(config); err != nil {
+		t.Fatalf("self-test failed: %v", err)
+	}
+	// Проверяем, что после самотеста не осталось данных
+	if audit.Len() != 0 { // самотест попал в журнал изменений
+		t.Errorf("expected empty audit log, got %q", audit.String())
+	}
+	if snapshot := config.Stats.Snapshot(); snapshot.TasksCreated != 0 || snapshot.TasksDeleted != 0 { // самотест попал в статистику
+		t.Errorf("expected no task counters, got %+v", snapshot)
+	}
+	if len(notify) != 0 { // самотест разослал уведомления
+		t.Errorf("expected no notifications, got %d", len(notify))
+	}
+}
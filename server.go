@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unicode/utf8"
 )
 
 // TaskStatus Статус задачи
@@ -24,66 +36,477 @@ func (s TaskStatus) IsValid() bool {
 	return s == StatusNotStarted || s == StatusInProgress || s == StatusCompleted
 }
 
+// allowedTransitions Допустимые переходы между статусами (переход в тот же статус разрешён всегда)
+var allowedTransitions = map[TaskStatus][]TaskStatus{
+	StatusNotStarted: {StatusInProgress},
+	StatusInProgress: {StatusCompleted, StatusNotStarted}, // возврат в not started - для исправления ошибок
+	StatusCompleted:  {},                                  // завершённую задачу менять нельзя
+}
+
+// CanTransition Проверка, разрешён ли переход задачи из одного статуса в другой
+func CanTransition(from, to TaskStatus) bool {
+	return from == to || slices.Contains(allowedTransitions[from], to)
+}
+
+// parseStatusFilter Разбор параметра ?status= (ok = false, если параметр не передан)
+func parseStatusFilter(r *http.Request) (status TaskStatus, ok bool, err error) {
+	if !r.URL.Query().Has("status") {
+		return "", false, nil
+	}
+	status = TaskStatus(r.URL.Query().Get("status"))
+	if !status.IsValid() {
+		return "", false, fmt.Errorf("invalid status %q: must be one of %q, %q, %q",
+			status, StatusNotStarted, StatusInProgress, StatusCompleted)
+	}
+	return status, true, nil
+}
+
 // Task Структура задачи
 type Task struct {
-	ID          int        `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Status      TaskStatus `json:"status"`
+	ID          int          `json:"id" xml:"id"`
+	Title       string       `json:"title" xml:"title"`
+	Description string       `json:"description" xml:"description"`
+	Status      TaskStatus   `json:"status" xml:"status"`
+	Priority    TaskPriority `json:"priority" xml:"priority"`                             // Приоритет (по умолчанию medium)
+	ExternalID  string       `json:"external_id,omitempty" xml:"external_id,omitempty"`   // Идентификатор задачи во внешней системе
+	Progress    int          `json:"progress" xml:"progress"`                             // Прогресс выполнения в процентах (0-100)
+	DueAt       *time.Time   `json:"due_at,omitempty" xml:"due_at,omitempty"`             // Срок выполнения
+	Recurrence  Recurrence   `json:"recurrence" xml:"recurrence"`                         // Периодичность (по умолчанию none)
+	ParentID    *int         `json:"parent_id,omitempty" xml:"parent_id,omitempty"`       // ID родительской задачи (для подзадач)
+	Assignee    string       `json:"assignee,omitempty" xml:"assignee,omitempty"`         // Исполнитель (пустой - задача не назначена)
+	CreatedAt   time.Time    `json:"created_at" xml:"created_at"`                         // Время создания (назначается сервером)
+	UpdatedAt   time.Time    `json:"updated_at" xml:"updated_at"`                         // Время последнего изменения (назначается сервером)
+	CompletedAt *time.Time   `json:"completed_at,omitempty" xml:"completed_at,omitempty"` // Время завершения (назначается сервером)
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`     // Время удаления в корзину (назначается сервером)
+	Version     int          `json:"version" xml:"version"`                               // Версия задачи, растёт с каждым изменением (назначается сервером)
+
+	Checklist     []ChecklistItem `json:"checklist,omitempty" xml:"checklist>item,omitempty"`
+	ChecklistDone int             `json:"checklist_done" xml:"checklist_done"`                 // Число выполненных пунктов чек-листа (вычисляется сервером)
+	Watchers      []string        `json:"watchers,omitempty" xml:"watchers>watcher,omitempty"` // Наблюдатели: получают события задачи
+
+	// StatusNumber Номер задачи среди задач того же статуса в порядке перехода в него (вычисляется при чтении,
+	// если включена нумерация; номера сдвигаются, когда задачи переходят между статусами)
+	StatusNumber int       `json:"status_number,omitempty" xml:"status_number,omitempty"`
+	statusSince  time.Time // Момент перехода задачи в текущий статус
 }
 
 // Preprocess Препроцессинг данных задачи (обрезка trailing & leading spaces)
 func (t *Task) Preprocess() {
-	t.Title = strings.TrimSpace(t.Title)
+	t.Title = normalizeTitle(t.Title)
 	t.Description = strings.TrimSpace(t.Description)
+	t.ExternalID = strings.TrimSpace(t.ExternalID)
+	t.Assignee = normalizeAssignee(t.Assignee)
+	t.Watchers = normalizeWatchers(t.Watchers)
+	if t.Priority == "" { // приоритет не указан
+		t.Priority = PriorityMedium
+	}
+	if t.Recurrence == "" { // задача не повторяется
+		t.Recurrence = RecurrenceNone
+	}
+	if t.DueAt != nil {
+		dueAt := t.DueAt.UTC()
+		t.DueAt = &dueAt
+	}
+	for i := range t.Checklist {
+		t.Checklist[i].Text = strings.TrimSpace(t.Checklist[i].Text)
+	}
+	t.countChecklistDone()
+	t.StatusNumber = 0
 }
 
-// Validate Валидация корректности данных задачи
-func (t *Task) Validate() error {
-	if t.ID <= 0 {
-		return fmt.Errorf("id must be a positive integer")
+// Validate Валидация корректности данных задачи с учётом настраиваемых правил.
+// Возвращает ValidationErrors со всеми найденными проблемами
+func (t *Task) Validate(config ValidationConfig) error {
+	var problems ValidationErrors
+	if t.ID < 0 { // нулевой ID допустим: при создании его назначает хранилище
+		problems.add("id", "id cannot be negative")
+	}
+	switch {
+	case !utf8.ValidString(t.Title):
+		problems.add("title", "title must be valid UTF-8")
+	case t.Title == "":
+		problems.add("title", "title cannot be empty")
 	}
-	if t.Title == "" {
-		return fmt.Errorf("title cannot be empty")
+	if !utf8.ValidString(t.Description) {
+		problems.add("description", "description must be valid UTF-8")
 	}
 	if !t.Status.IsValid() {
-		return fmt.Errorf("invalid status")
+		problems.add("status", "invalid status")
+	}
+	if !t.Priority.IsValid() {
+		problems.add("priority", "invalid priority")
+	}
+	if !t.Recurrence.IsValid() {
+		problems.add("recurrence", "invalid recurrence")
+	}
+	if t.ParentID != nil && *t.ParentID <= 0 {
+		problems.add("parent_id", "parent_id must be a positive integer")
+	}
+	if err := validateProgress(t.Progress); err != nil {
+		problems.add("progress", err.Error())
+	}
+	for i, item := range t.Checklist {
+		if item.Text == "" {
+			problems.add(fmt.Sprintf("checklist[%d].text", i), fmt.Sprintf("checklist item %d text cannot be empty", i))
+		}
+	}
+	if len(t.Watchers) > maxWatchers {
+		problems.add("watchers", fmt.Sprintf("a task can have at most %d watchers", maxWatchers))
+	}
+	for i, watcher := range t.Watchers {
+		if err := config.checkWatcher(watcher); err != nil {
+			problems.add(fmt.Sprintf("watchers[%d]", i), err.Error())
+		}
+	}
+	config.validate(t, &problems)
+	if len(problems) > 0 {
+		return problems
 	}
 	return nil
 }
 
+// ErrExternalIDConflict Ошибка нарушения уникальности внешнего идентификатора
+var ErrExternalIDConflict = errors.New("external id already exists")
+
+// ErrTaskExists Ошибка создания задачи с уже занятым ID
+var ErrTaskExists = errors.New("task already exists")
+
+// ErrInvalidID Ошибка создания задачи с ID вне допустимого диапазона
+var ErrInvalidID = errors.New("invalid task id")
+
+// MaxTaskID Наибольший ID, который может задать клиент. С запасом меньше math.MaxInt, чтобы счётчик автоматических ID
+// не переполнился, и точно представим в JSON-числе для клиентов на JavaScript
+const MaxTaskID = 1<<53 - 1
+
+// ErrForbiddenTransition Ошибка недопустимого перехода между статусами
+var ErrForbiddenTransition = errors.New("forbidden status transition")
+
+// StoreConfig Настройки хранилища задач
+type StoreConfig struct {
+	UniqueExternalIDs     bool             // Запрещать задачи с одинаковым внешним идентификатором
+	StatusNumbering       bool             // Отдавать номер задачи в её статусе (status_number)
+	CompactionRatio       float64          // Доля удалённых записей, при которой карта задач пересоздаётся (0 - не сжимать)
+	TrashRetention        time.Duration    // Сколько задача хранится в корзине до окончательного удаления (0 - бессрочно)
+	ProgressFollowsStatus bool             // Выставлять прогресс 100 при завершении задачи и 0 при её переоткрытии
+	InferParentStatus     bool             // Выводить статус задачи с подзадачами из их статусов
+	FillIDGaps            bool             // Выдавать новой задаче наименьший свободный ID вместо следующего за последним
+	IdempotencyTTL        time.Duration    // Сколько помнить ключи Idempotency-Key (0 - заголовок не поддерживается)
+	MaxTasks              int              // Максимальное число задач в хранилище (0 - без ограничения)
+	MaxTenants            int              // Максимальное число хранилищ тенантов в реестре (0 - без ограничения)
+	HistoryLimit          int              // Сколько последних записей журнала хранить в памяти на задачу (0 - без ограничения)
+	EvictionPolicy        EvictionPolicy   // Что делать при достижении MaxTasks
+	Validation            ValidationConfig // Дополнительные правила валидации задач
+	AssigneeLimits        AssigneeLimits   // Лимит незавершённых задач на исполнителя
+	AuditLog              io.Writer        // Куда дописывать журнал изменений в JSON Lines (nil - только в памяти)
+	Stats                 *Stats           // Счётчики статистики (nil - статистика не собирается)
+	Notify                chan<- TaskEvent // Общий канал событий всех хранилищ для уведомлений (nil - не отправляются)
+}
+
+// DefaultStoreConfig Настройки хранилища по умолчанию
+func DefaultStoreConfig() StoreConfig {
+	return StoreConfig{
+		UniqueExternalIDs:     true,
+		ProgressFollowsStatus: true,
+		IdempotencyTTL:        24 * time.Hour,
+		EvictionPolicy:        EvictionReject,
+		TrashRetention:        7 * 24 * time.Hour,
+		MaxTenants:            defaultMaxTenants,
+		HistoryLimit:          defaultHistoryLimit,
+	}
+}
+
 // TaskStore Хранилище данных
 type TaskStore struct {
-	mutex sync.RWMutex // Мьютекс для защиты от гонок данных
-	tasks map[int]Task
+	mutex        sync.RWMutex // Мьютекс для защиты от гонок данных
+	config       StoreConfig
+	tasks        map[int]Task
+	nextID       int                         // Последний выданный ID задачи
+	freeIDs      []idRange                   // Свободные ID ниже nextID по возрастанию (только при FillIDGaps)
+	byExternalID map[string]map[int]struct{} // Индекс ID задач по внешнему идентификатору
+	byAssignee   map[string]map[int]struct{} // Индекс ID незавершённых задач вне корзины по исполнителю (для лимита)
+	byParent     map[int]map[int]struct{}    // Индекс ID подзадач по ID родителя (вместе с удалёнными в корзину)
+
+	deletedSinceCompaction int // Число удалений с момента последнего пересоздания карты задач
+
+	idempotency *IdempotencyCache // Результаты POST по ключам Idempotency-Key (nil - заголовок игнорируется)
+
+	tenant  string               // Тенант, которому принадлежит хранилище (пусто - общее пространство)
+	history map[int][]AuditEntry // Журнал изменений по ID задачи
+	undo    map[int][]Task       // Прежние версии задач для отмены изменений (последние undoDepth)
+
+	subMutex    sync.Mutex                  // Мьютекс подписчиков (берётся под mutex при публикации)
+	subscribers map[chan TaskEvent]struct{} // Подписчики на изменения задач
 }
 
-// NewTaskStore Создание нового хранилища задач
+// NewTaskStore Создание нового хранилища задач с настройками по умолчанию
 func NewTaskStore() *TaskStore {
-	return &TaskStore{tasks: make(map[int]Task)}
+	return NewTaskStoreWithConfig(DefaultStoreConfig())
+}
+
+// NewTaskStoreWithConfig Создание нового хранилища задач с заданными настройками
+func NewTaskStoreWithConfig(config StoreConfig) *TaskStore {
+	ds := &TaskStore{
+		config:       config,
+		tasks:        make(map[int]Task),
+		byExternalID: make(map[string]map[int]struct{}),
+		byAssignee:   make(map[string]map[int]struct{}),
+		byParent:     make(map[int]map[int]struct{}),
+		history:      make(map[int][]AuditEntry),
+		undo:         make(map[int][]Task),
+		subscribers:  make(map[chan TaskEvent]struct{}),
+	}
+	if config.IdempotencyTTL > 0 {
+		ds.idempotency = NewIdempotencyCache(config.IdempotencyTTL)
+	}
+	return ds
+}
+
+// checkExternalID Проверяет, что внешний идентификатор не занят другой задачей (вызывается под блокировкой)
+func (ds *TaskStore) checkExternalID(externalID string, selfID int) error {
+	if !ds.config.UniqueExternalIDs || externalID == "" {
+		return nil
+	}
+	for id := range ds.byExternalID[externalID] {
+		if id != selfID {
+			return fmt.Errorf("%w: %q is used by task %d", ErrExternalIDConflict, externalID, id)
+		}
+	}
+	return nil
+}
+
+// indexExternalID Добавляет задачу в индекс внешних идентификаторов (вызывается под блокировкой)
+func (ds *TaskStore) indexExternalID(task Task) {
+	if task.ExternalID == "" {
+		return
+	}
+	ids, ok := ds.byExternalID[task.ExternalID]
+	if !ok {
+		ids = make(map[int]struct{})
+		ds.byExternalID[task.ExternalID] = ids
+	}
+	ids[task.ID] = struct{}{}
+}
+
+// unindexExternalID Удаляет задачу из индекса внешних идентификаторов (вызывается под блокировкой)
+func (ds *TaskStore) unindexExternalID(task Task) {
+	ids, ok := ds.byExternalID[task.ExternalID]
+	if !ok {
+		return
+	}
+	delete(ids, task.ID)
+	if len(ids) == 0 {
+		delete(ds.byExternalID, task.ExternalID)
+	}
+}
+
+// isEmpty Нет ли в хранилище ни одной задачи, включая удалённые в корзину
+func (ds *TaskStore) isEmpty() bool {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+	return len(ds.tasks) == 0
+}
+
+// liveTask Возвращает задачу по ID, если она есть и не удалена в корзину (вызывается под блокировкой)
+func (ds *TaskStore) liveTask(id int) (Task, bool) {
+	task, ok := ds.tasks[id]
+	return task, ok && task.DeletedAt == nil
+}
+
+// newTask Заполняет серверные поля новой задачи, не сохраняя её (вызывается под блокировкой)
+func (ds *TaskStore) newTask(task Task) Task {
+	now := time.Now().UTC()
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	task.statusSince = now
+	task.DeletedAt = nil
+	task.Version = 1
+	trackCompletion("", &task, now)
+	if task.Priority == "" { // задача добавлена в обход Preprocess
+		task.Priority = PriorityMedium
+	}
+	if task.Recurrence == "" {
+		task.Recurrence = RecurrenceNone
+	}
+	ds.coupleProgress(task.Status, &task)
+	return task
+}
+
+// insertTask Сохраняет новую задачу и обновляет индексы (вызывается под блокировкой)
+func (ds *TaskStore) insertTask(task Task) Task {
+	task = ds.newTask(task)
+	ds.tasks[task.ID] = task
+	ds.indexExternalID(task)
+	ds.indexAssignee(task)
+	ds.indexParent(task)
+	ds.occupyID(task.ID)
+	ds.nextID = max(ds.nextID, task.ID) // автоматические ID не должны пересекаться с явно заданными
+	return task
+}
+
+// AddTask Создает новую задачу с автоматически назначенным ID и возвращает её
+func (ds *TaskStore) AddTask(task Task) (Task, error) {
+	return ds.addTask(task, false)
+}
+
+// addTask Создание задачи (при dryRun - только проверки и задача, которая была бы создана)
+func (ds *TaskStore) addTask(task Task, dryRun bool) (Task, error) {
+	ds.mutex.Lock()
+	if err := ds.checkExternalID(task.ExternalID, 0); err != nil { // внешний ID уже занят
+		ds.mutex.Unlock()
+		slog.Warn("[AddTask] Rejecting task", "error", err)
+		return Task{}, err
+	}
+	if err := ds.checkParent(task.ParentID, 0, task.Status); err != nil {
+		ds.mutex.Unlock()
+		slog.Warn("[AddTask] Rejecting task", "error", err)
+		return Task{}, err
+	}
+	if countsTowardLimit(task) {
+		if err := ds.checkAssigneeLimit(task.Assignee, 1); err != nil { // у исполнителя нет места
+			ds.mutex.Unlock()
+			slog.Warn("[AddTask] Rejecting task", "error", err)
+			return Task{}, err
+		}
+	}
+	if dryRun {
+		_, err := ds.roomFor(1)
+		if err == nil {
+			task.ID = ds.newID()
+			task = ds.numberTask(ds.newTask(task))
+		}
+		ds.mutex.Unlock()
+		return task, err
+	}
+	if err := ds.makeRoom(1); err != nil { // хранилище заполнено
+		ds.mutex.Unlock()
+		slog.Warn("[AddTask] Rejecting task", "error", err)
+		return Task{}, err
+	}
+	// выдача ID и вставка под одной блокировкой, поэтому параллельные запросы не получат одинаковый ID
+	task.ID = ds.newID()
+	task = ds.numberTask(ds.insertTask(task))
+	ds.record(TaskEventCreated, nil, &task)
+	ds.mutex.Unlock()
+	ds.config.Stats.TaskCreated(task.Assignee)
+	return task, nil
 }
 
-// CreateTask Создает новую задачу в хранилище
+// CreateTask Создает новую задачу с заданным ID в хранилище
 func (ds *TaskStore) CreateTask(task Task) error {
+	_, err := ds.createTask(task, false)
+	return err
+}
+
+// createTask Создание задачи с заданным ID, возвращает созданную задачу
+// (при dryRun - только проверки и задача, которая была бы создана)
+func (ds *TaskStore) createTask(task Task, dryRun bool) (Task, error) {
+	if task.ID <= 0 || task.ID > MaxTaskID {
+		err := fmt.Errorf("%w: id must be a positive integer not greater than %d", ErrInvalidID, MaxTaskID)
+		slog.Warn("[CreateTask] Rejecting task", "task_id", task.ID, "error", err)
+		return Task{}, err
+	}
 	ds.mutex.Lock()
 	if _, exists := ds.tasks[task.ID]; exists { // задача с таким ID уже есть
 		ds.mutex.Unlock()
-		err := fmt.Errorf("task with id %d already exists", task.ID)
-		log.Printf("[CreateTask] error: %v", err)
-		return err
+		err := fmt.Errorf("%w: id %d", ErrTaskExists, task.ID)
+		slog.Warn("[CreateTask] Rejecting task", "task_id", task.ID, "error", err)
+		return Task{}, err
 	}
-	ds.tasks[task.ID] = task
+	if err := ds.checkExternalID(task.ExternalID, task.ID); err != nil { // внешний ID уже занят
+		ds.mutex.Unlock()
+		slog.Warn("[CreateTask] Rejecting task", "task_id", task.ID, "error", err)
+		return Task{}, err
+	}
+	if err := ds.checkParent(task.ParentID, task.ID, task.Status); err != nil {
+		ds.mutex.Unlock()
+		slog.Warn("[CreateTask] Rejecting task", "task_id", task.ID, "error", err)
+		return Task{}, err
+	}
+	if countsTowardLimit(task) {
+		if err := ds.checkAssigneeLimit(task.Assignee, 1); err != nil { // у исполнителя нет места
+			ds.mutex.Unlock()
+			slog.Warn("[CreateTask] Rejecting task", "task_id", task.ID, "error", err)
+			return Task{}, err
+		}
+	}
+	if dryRun {
+		_, err := ds.roomFor(1)
+		if err == nil {
+			task = ds.numberTask(ds.newTask(task))
+		}
+		ds.mutex.Unlock()
+		return task, err
+	}
+	if err := ds.makeRoom(1); err != nil { // хранилище заполнено
+		ds.mutex.Unlock()
+		slog.Warn("[CreateTask] Rejecting task", "task_id", task.ID, "error", err)
+		return Task{}, err
+	}
+	created := ds.numberTask(ds.insertTask(task))
+	ds.record(TaskEventCreated, nil, &created)
 	ds.mutex.Unlock()
-	return nil
+	ds.config.Stats.TaskCreated(created.Assignee)
+	return created, nil
 }
 
-// GetAllTasks Возвращает все задачи из хранилища
+// FindByExternalID Возвращает задачи с заданным внешним идентификатором
+func (ds *TaskStore) FindByExternalID(externalID string) []Task {
+	ds.mutex.RLock()
+	ids := ds.byExternalID[externalID]
+	list := make([]Task, 0, len(ids))
+	for id := range ids {
+		list = append(list, ds.tasks[id])
+	}
+	ds.numberTasks(list)
+	ds.mutex.RUnlock()
+	return list
+}
+
+// FilterByStatus Возвращает задачи с заданным статусом
+func (ds *TaskStore) FilterByStatus(status TaskStatus) []Task {
+	ds.mutex.RLock()
+	list := make([]Task, 0)
+	for _, t := range ds.tasks {
+		if t.Status == status && t.DeletedAt == nil {
+			list = append(list, t)
+		}
+	}
+	ds.numberTasks(list)
+	ds.mutex.RUnlock()
+	return list
+}
+
+// filterTasks Оставляет в списке задачи, удовлетворяющие условию
+func filterTasks(tasks []Task, keep func(t Task) bool) []Task {
+	filtered := tasks[:0]
+	for _, t := range tasks {
+		if keep(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// GetAllTasks Возвращает все задачи из хранилища, кроме удалённых в корзину
 func (ds *TaskStore) GetAllTasks() []Task {
+	return ds.listTasks(false)
+}
+
+// GetAllTasksIncludingDeleted Возвращает все задачи из хранилища вместе с удалёнными в корзину
+func (ds *TaskStore) GetAllTasksIncludingDeleted() []Task {
+	return ds.listTasks(true)
+}
+
+// listTasks Возвращает задачи из хранилища (удалённые в корзину - только если includeDeleted)
+func (ds *TaskStore) listTasks(includeDeleted bool) []Task {
 	ds.mutex.RLock()
 	list := make([]Task, 0, len(ds.tasks))
 	for _, t := range ds.tasks {
-		list = append(list, t)
+		if includeDeleted || t.DeletedAt == nil {
+			list = append(list, t)
+		}
 	}
+	ds.numberTasks(list)
 	ds.mutex.RUnlock()
 	return list
 }
@@ -91,174 +514,935 @@ func (ds *TaskStore) GetAllTasks() []Task {
 // GetTask Возвращает задачу из хранилища по ID
 func (ds *TaskStore) GetTask(id int) (Task, error) {
 	ds.mutex.RLock()
-	task, ok := ds.tasks[id]
+	task, ok := ds.liveTask(id)
+	if ok {
+		task = ds.numberTask(task)
+	}
 	ds.mutex.RUnlock()
 	if !ok { // задача с таким ID не найдена
 		err := fmt.Errorf("task with id %d not found", id)
-		log.Printf("[GetTask] error: %v", err)
+		slog.Warn("[GetTask] Task not found", "task_id", id, "error", err)
 		return Task{}, err
 	}
 	return task, nil
 }
 
-// UpdateTask Обновляет задачу в хранилище по ID
+// UpdateTask Обновляет задачу в хранилище по ID (updated.Version, если не 0, - ожидаемая версия задачи)
 func (ds *TaskStore) UpdateTask(id int, updated Task) (Task, error) {
+	return ds.updateTask(id, updated, false)
+}
+
+// updateTask Обновление задачи (при dryRun - только проверки и задача, какой она стала бы)
+func (ds *TaskStore) updateTask(id int, updated Task, dryRun bool) (Task, error) {
 	ds.mutex.Lock()
-	task, ok := ds.tasks[id]
+	task, ok := ds.liveTask(id)
 	if !ok { // задача с таким ID не найдена
 		ds.mutex.Unlock()
 		err := fmt.Errorf("task with id %d not found", id)
-		log.Printf("[UpdateTask] error: %v", err)
+		slog.Warn("[UpdateTask] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if err := checkVersion(task, updated.Version); err != nil { // задачу успели изменить
+		ds.mutex.Unlock()
+		slog.Warn("[UpdateTask] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if err := ds.checkChange(task, updated); err != nil {
+		ds.mutex.Unlock()
+		slog.Warn("[UpdateTask] Rejecting update", "task_id", id, "error", err)
 		return Task{}, err
 	}
+	// завершение повторяющейся задачи порождает её следующий экземпляр
+	spawn := task.Status != StatusCompleted && updated.Status == StatusCompleted &&
+		updated.Recurrence != "" && updated.Recurrence != RecurrenceNone
+	if spawn && dryRun {
+		if _, err := ds.roomFor(1); err != nil { // для следующего экземпляра не нашлось бы места
+			ds.mutex.Unlock()
+			return Task{}, err
+		}
+	} else if spawn {
+		if err := ds.makeRoom(1); err != nil { // для следующего экземпляра нет места
+			ds.mutex.Unlock()
+			slog.Warn("[UpdateTask] Rejecting update", "task_id", id, "error", err)
+			return Task{}, err
+		}
+	}
+	before := task
+	from := task.Status
+	now := time.Now().UTC()
+	if task.Status != updated.Status { // задача перешла в другой статус
+		task.statusSince = now
+	}
+	task.UpdatedAt = now
+	task.Version++
 	// обновляем поля задачи
 	task.Title = updated.Title
 	task.Description = updated.Description
 	task.Status = updated.Status
+	trackCompletion(from, &task, now)
+	task.Priority = updated.Priority
+	task.ExternalID = updated.ExternalID
+	task.Checklist = updated.Checklist
+	task.ChecklistDone = updated.ChecklistDone
+	task.Progress = updated.Progress
+	task.DueAt = updated.DueAt
+	task.Recurrence = updated.Recurrence
+	task.ParentID = updated.ParentID
+	task.Assignee = updated.Assignee
+	task.Watchers = updated.Watchers
+	ds.coupleProgress(from, &task)
+	if dryRun {
+		task = ds.numberTask(task)
+		ds.mutex.Unlock()
+		return task, nil
+	}
+	ds.unindexExternalID(before)
+	ds.unindexAssignee(before)
+	ds.unindexParent(before)
 	ds.tasks[id] = task
+	ds.indexExternalID(task)
+	ds.indexAssignee(task)
+	ds.indexParent(task)
+	task = ds.numberTask(task)
+	ds.record(TaskEventUpdated, &before, &task)
+	if spawn {
+		next := nextOccurrence(task)
+		next.ID = ds.newID()
+		next.countChecklistDone()
+		next = ds.numberTask(ds.insertTask(next))
+		ds.record(TaskEventCreated, nil, &next)
+		slog.Info("[UpdateTask] Spawned next occurrence", "task_id", id, "next_id", next.ID)
+	}
 	ds.mutex.Unlock()
+	if spawn {
+		ds.config.Stats.TaskCreated(task.Assignee)
+	}
 	return task, nil
 }
 
-// DeleteTask Удаляет задачу из хранилища по ID
-func (ds *TaskStore) DeleteTask(id int) error {
+// checkChange Проверяет, что задачу можно привести из состояния task в updated: переход статуса, выводимый статус,
+// внешний ID, родитель, лимит исполнителя и завершённость подзадач (вызывается под блокировкой)
+func (ds *TaskStore) checkChange(task, updated Task) error {
+	if !CanTransition(task.Status, updated.Status) {
+		return fmt.Errorf("%w from %q to %q", ErrForbiddenTransition, task.Status, updated.Status)
+	}
+	if ds.config.InferParentStatus && updated.Status != task.Status && len(ds.children(task.ID)) > 0 {
+		return fmt.Errorf("%w: task %d has subtasks", ErrInferredStatus, task.ID)
+	}
+	if err := ds.checkExternalID(updated.ExternalID, task.ID); err != nil { // внешний ID занят другой задачей
+		return err
+	}
+	if err := ds.checkParent(updated.ParentID, task.ID, updated.Status); err != nil {
+		return err
+	}
+	if err := ds.checkAssigneeSlot(task, updated); err != nil { // задачу передают исполнителю без свободного места
+		return err
+	}
+	if updated.Status == StatusCompleted && task.Status != StatusCompleted {
+		if err := ds.checkCompletable(task.ID); err != nil { // подзадачи ещё не завершены
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTask Удаляет задачу по ID в корзину (её можно восстановить через RestoreTask).
+// version, если не 0, - ожидаемая версия задачи
+func (ds *TaskStore) DeleteTask(id int, version int) error {
 	ds.mutex.Lock()
-	_, ok := ds.tasks[id]
+	task, ok := ds.liveTask(id)
 	if !ok { // задача с таким ID не найдена
 		ds.mutex.Unlock()
 		err := fmt.Errorf("task with id %d not found", id)
-		log.Printf("[DeleteTask] error: %v", err)
+		slog.Warn("[DeleteTask] Task not found", "task_id", id, "error", err)
+		return err
+	}
+	if err := checkVersion(task, version); err != nil { // задачу успели изменить
+		ds.mutex.Unlock()
+		slog.Warn("[DeleteTask] Rejecting delete", "task_id", id, "error", err)
+		return err
+	}
+	if children := ds.children(id); len(children) > 0 { // подзадачи остались бы без родителя
+		ds.mutex.Unlock()
+		err := fmt.Errorf("%w: %d subtasks, use cascade=true to delete them", ErrHasSubtasks, len(children))
+		slog.Warn("[DeleteTask] Rejecting delete", "task_id", id, "error", err)
 		return err
 	}
-	delete(ds.tasks, id)
+	ds.softDelete(task, time.Now().UTC())
 	ds.mutex.Unlock()
+	ds.config.Stats.TaskDeleted()
 	return nil
 }
 
+// softDelete Переносит задачу в корзину (вызывается под блокировкой)
+func (ds *TaskStore) softDelete(task Task, now time.Time) {
+	// внешний ID освобождается, чтобы задачу из внешней системы можно было создать заново
+	ds.unindexExternalID(task)
+	ds.unindexAssignee(task)
+	before := task
+	task.DeletedAt = &now
+	task.UpdatedAt = now
+	task.Version++
+	ds.tasks[task.ID] = task
+	ds.record(TaskEventDeleted, &before, &task)
+}
+
+// RestoreTask Восстанавливает удалённую в корзину задачу по ID
+func (ds *TaskStore) RestoreTask(id int) (Task, error) {
+	ds.mutex.Lock()
+	task, ok := ds.tasks[id]
+	if !ok || task.DeletedAt == nil { // удалённой задачи с таким ID нет
+		ds.mutex.Unlock()
+		err := fmt.Errorf("deleted task with id %d not found", id)
+		slog.Warn("[RestoreTask] Task not found", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if err := ds.checkExternalID(task.ExternalID, id); err != nil { // внешний ID заняли, пока задача была в корзине
+		ds.mutex.Unlock()
+		slog.Warn("[RestoreTask] Rejecting restore", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	// родителя могли удалить в корзину или завершить, пока задача была в корзине
+	if err := ds.checkParent(task.ParentID, id, task.Status); err != nil {
+		ds.mutex.Unlock()
+		slog.Warn("[RestoreTask] Rejecting restore", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	// задача в корзине уже учитывается в лимите хранилища, поэтому восстановление отклоняется, только если
+	// хранилище переполнено
+	if _, err := ds.roomFor(0); err != nil {
+		ds.mutex.Unlock()
+		slog.Warn("[RestoreTask] Rejecting restore", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	before := task
+	task.DeletedAt = nil
+	if err := ds.checkAssigneeSlot(before, task); err != nil { // место исполнителя заняли, пока задача была в корзине
+		ds.mutex.Unlock()
+		slog.Warn("[RestoreTask] Rejecting restore", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	ds.tasks[id] = task
+	ds.indexExternalID(task)
+	ds.indexAssignee(task)
+	task = ds.numberTask(task)
+	ds.record(TaskEventRestored, &before, &task)
+	ds.mutex.Unlock()
+	ds.config.Stats.TaskRestored()
+	return task, nil
+}
+
+// createErrorStatus Подбор HTTP статуса для ошибки создания задачи
+func createErrorStatus(err error) int {
+	if status, ok := contextErrorStatus(err); ok {
+		return status
+	}
+	if errors.Is(err, ErrExternalIDConflict) || errors.Is(err, ErrAssigneeLimit) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, ErrIdempotencyKeyReused) {
+		return http.StatusUnprocessableEntity
+	}
+	if errors.Is(err, ErrStoreFull) {
+		return http.StatusInsufficientStorage
+	}
+	return http.StatusBadRequest
+}
+
+// updateErrorStatus Подбор HTTP статуса для ошибки операции над существующей задачей (чтение, обновление, удаление)
+func updateErrorStatus(err error) int {
+	if status, ok := contextErrorStatus(err); ok {
+		return status
+	}
+	if errors.Is(err, ErrExternalIDConflict) || errors.Is(err, ErrForbiddenTransition) || errors.Is(err, ErrTaskExists) ||
+		errors.Is(err, ErrAssigneeLimit) || errors.Is(err, ErrInferredStatus) ||
+		errors.Is(err, ErrHasSubtasks) || errors.Is(err, ErrIncompleteSubtasks) || errors.Is(err, ErrNothingToUndo) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, ErrInvalidParent) || errors.Is(err, ErrInvalidID) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, ErrVersionMismatch) {
+		return http.StatusPreconditionFailed
+	}
+	if errors.Is(err, ErrStoreFull) {
+		return http.StatusInsufficientStorage
+	}
+	return http.StatusNotFound
+}
+
+// revertErrorStatus Подбор HTTP статуса для ошибки восстановления задачи или отмены изменения: недоступный родитель
+// и заполненное хранилище - конфликт с текущим состоянием, а не ошибка запроса
+func revertErrorStatus(err error) int {
+	if errors.Is(err, ErrInvalidParent) || errors.Is(err, ErrStoreFull) {
+		return http.StatusConflict
+	}
+	return updateErrorStatus(err)
+}
+
 // todosHandler Обработчик эндпоинта /todos
-func todosHandler(ts *TaskStore) http.HandlerFunc {
+func todosHandler(ts Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
-		case http.MethodPost: // POST /todos
+		case http.MethodPost: // POST /todos, POST /todos?dry_run=true
+			dryRun, err := parseBoolQuery(r, "dry_run")
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid dry_run", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			body, err := readBody(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Reading body", err)
+				writeDecodeError(w, err)
+				return
+			}
+			if !utf8.Valid(body) { // encoding/json молча заменил бы некорректные байты на U+FFFD
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid UTF-8 in body", nil)
+				writeJSONError(w, http.StatusBadRequest, "request body must be valid UTF-8")
+				return
+			}
+			if isJSONArray(body) && dryRun {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Dry run of a batch", nil)
+				writeJSONError(w, http.StatusBadRequest, "dry_run is not supported for batches")
+				return
+			}
+			if isJSONArray(body) { // POST /todos с массивом задач
+				createTasksBulk(w, r, ts, body)
+				return
+			}
 			var t Task
-			if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
-				log.Printf("[todosHandler] error: Decoding: %v", err)
-				http.Error(w, "invalid JSON", http.StatusBadRequest)
+			if err := json.Unmarshal(body, &t); err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Decoding", err)
+				writeJSONError(w, http.StatusBadRequest, "invalid JSON")
 				return
 			}
+			t.ID = 0 // ID назначает хранилище, присланный клиентом игнорируется
 			t.Preprocess()
-			if err := t.Validate(); err != nil {
-				log.Printf("[todosHandler] error: Validation: %v", err)
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			if err := t.Validate(validationConfig(ts)); err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Validation", err)
+				writeValidationError(w, validationConfig(ts), err)
+				return
+			}
+			if dryRun { // только проверки: задача не создаётся, ключ идемпотентности не расходуется
+				preview, err := ts.PreviewAddTask(t)
+				if err != nil {
+					logRequest(r, slog.LevelWarn, "[todosHandler] Dry run", err)
+					writeJSONError(w, createErrorStatus(err), err.Error())
+					return
+				}
+				if err := writeTaskJSON(w, r, http.StatusOK, preview); err != nil {
+					logRequest(r, slog.LevelError, "[todosHandler] Encoding task", err)
+				}
+				return
+			}
+			var created Task
+			cache := idempotencyCache(ts)
+			if key := strings.TrimSpace(r.Header.Get("Idempotency-Key")); key != "" && cache != nil {
+				var replayed bool
+				created, replayed, err = cache.Do(key, body, func() (Task, error) {
+					return AddTaskCtx(r.Context(), ts, t)
+				})
+				if replayed { // повтор запроса: отдаём исходный результат, задача не создаётся
+					w.Header().Set("Idempotent-Replayed", "true")
+				}
+			} else {
+				created, err = AddTaskCtx(r.Context(), ts, t)
+			}
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Creating task", err)
+				writeJSONError(w, createErrorStatus(err), err.Error())
 				return
 			}
-			if err := ts.CreateTask(t); err != nil {
-				log.Printf("[todosHandler] error: Creating task: %v", err)
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			location := strings.TrimSuffix(r.URL.Path, "/") + "/" + strconv.Itoa(created.ID)
+			w.Header().Set("Location", location)
+			if err := writeMutationResult(w, r, http.StatusCreated, created, location, true); err != nil {
+				logRequest(r, slog.LevelError, "[todosHandler] Encoding task", err)
 				return
 			}
-			w.WriteHeader(http.StatusCreated)
 
-		case http.MethodGet: // GET /todos
-			tasks := ts.GetAllTasks()
-			w.Header().Set("Content-Type", "application/json")
-			if err := json.NewEncoder(w).Encode(tasks); err != nil {
-				log.Printf("[todosHandler] error: Encoding tasks: %v", err)
+		case http.MethodGet, http.MethodHead: // GET /todos, HEAD /todos
+			status, filterStatus, err := parseStatusFilter(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid status filter", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			priority, filterPriority, err := parsePriorityFilter(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid priority filter", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			due, filterDue, err := parseDueFilter(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid due filter", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			minProgress, filterProgress, err := parseMinProgress(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid min_progress", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			assignee, filterAssignee, err := parseAssigneeFilter(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid assignee filter", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			includeDeleted, err := parseBoolQuery(r, "include_deleted")
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid include_deleted", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			sortKey, sortDesc, err := parseSort(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid sort", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			offset, limit, err := parsePage(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid page", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			after, byCursor, err := parseCursor(r)
+			if err == nil && byCursor && (r.URL.Query().Has("offset") || sortKey != "id") {
+				err = fmt.Errorf("cursor requires sort=id and cannot be combined with offset")
+			}
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid cursor", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			ids, byIDs, err := parseIDs(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Invalid ids", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			query := strings.TrimSpace(r.URL.Query().Get("q")) // пустой q - обычный список
+			var tasks []Task
+			switch {
+			case byIDs: // GET /todos?ids=1,2,3
+				var missing []int
+				if tasks, missing = ts.GetTasks(ids); len(missing) > 0 {
+					w.Header().Set("X-Missing-IDs", formatIDs(missing))
+				}
+			case r.URL.Query().Has("external_id"): // GET /todos?external_id=ABC
+				tasks = ts.FindByExternalID(strings.TrimSpace(r.URL.Query().Get("external_id")))
+			case includeDeleted: // GET /todos?include_deleted=true
+				tasks = ts.GetAllTasksIncludingDeleted()
+			case query != "": // GET /todos?q=report
+				tasks = ts.Search(query)
+			case filterStatus: // GET /todos?status=in%20progress
+				tasks = ts.FilterByStatus(status)
+			default:
+				if tasks, err = GetAllTasksCtx(r.Context(), ts); err != nil {
+					logRequest(r, slog.LevelWarn, "[todosHandler] Listing tasks", err)
+					writeJSONError(w, updateErrorStatus(err), err.Error())
+					return
+				}
+			}
+			if query != "" {
+				lower := strings.ToLower(query)
+				tasks = filterTasks(tasks, func(t Task) bool { return matchesQuery(t, lower) })
+			}
+			if filterStatus {
+				tasks = filterTasks(tasks, func(t Task) bool { return t.Status == status })
+			}
+			if filterPriority { // GET /todos?priority=high
+				tasks = filterTasks(tasks, func(t Task) bool { return t.Priority == priority })
+			}
+			if filterProgress { // GET /todos?min_progress=50
+				tasks = filterTasks(tasks, func(t Task) bool { return t.Progress >= minProgress })
+			}
+			if filterDue { // GET /todos?overdue=true
+				tasks = filterTasks(tasks, due.match)
+			}
+			if filterAssignee { // GET /todos?assignee=alice, GET /todos?unassigned=true
+				tasks = filterTasks(tasks, func(t Task) bool { return t.Assignee == assignee })
+			}
+			if err := SortTasks(tasks, sortKey, sortDesc); err != nil {
+				logRequest(r, slog.LevelWarn, "[todosHandler] Sorting tasks", err)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			total, modified := len(tasks), lastModified(tasks)
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			if byCursor { // GET /todos?cursor=...
+				tasks = afterCursor(tasks, after, sortDesc)
+			}
+			rest := len(tasks)
+			tasks = paginate(tasks, offset, limit)
+			if sortKey == "id" && len(tasks) > 0 && offset+len(tasks) < rest { // дальше есть ещё задачи
+				w.Header().Set("X-Next-Cursor", encodeCursor(tasks[len(tasks)-1].ID))
+			}
+			if notModified(w, r, collectionETag(tasks, total), modified) { // список не изменился с прошлого запроса
+				return
+			}
+			if err := writeTaskJSON(w, r, http.StatusOK, tasks); err != nil {
+				logRequest(r, slog.LevelError, "[todosHandler] Encoding tasks", err)
 				return
 			}
 
+		case http.MethodDelete: // DELETE /todos
+			deleteTasksBulk(w, r, ts)
+
 		default:
-			log.Printf("[todosHandler] error: Invalid method")
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			logRequest(r, slog.LevelWarn, "[todosHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
 		}
 	}
 }
 
 // todoHandler Обработчик эндпоинта /todos/{id}
-func todoHandler(ts *TaskStore) http.HandlerFunc {
+func todoHandler(ts Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		idStr := r.PathValue("id")
 		if idStr == "" {
-			log.Println("[todoHandler] error: Missing id")
-			http.Error(w, "missing id", http.StatusBadRequest)
+			logRequest(r, slog.LevelWarn, "[todoHandler] Missing id", nil)
+			writeJSONError(w, http.StatusBadRequest, "missing id")
 			return
 		}
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			log.Printf("[todoHandler] error: Invalid id: %v", err)
-			http.Error(w, "invalid id", http.StatusBadRequest)
+			logRequest(r, slog.LevelWarn, "[todoHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
 			return
 		}
 
 		switch r.Method {
-		case http.MethodGet: // GET /todos/{id}
-			task, err := ts.GetTask(id)
+		case http.MethodGet, http.MethodHead: // GET /todos/{id}, HEAD /todos/{id}
+			task, err := GetTaskCtx(r.Context(), ts, id)
 			if err != nil {
-				log.Printf("[todoHandler] error: Getting task: %v", err)
-				http.Error(w, err.Error(), http.StatusNotFound)
+				logRequest(r, slog.LevelWarn, "[todoHandler] Getting task", err, "task_id", id)
+				writeJSONError(w, updateErrorStatus(err), err.Error())
+				return
+			}
+			if notModified(w, r, taskETag(task), task.UpdatedAt) { // задача не изменилась с прошлого запроса
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			if err := json.NewEncoder(w).Encode(task); err != nil {
-				log.Printf("[todoHandler] error: Encoding task: %v", err)
+			if err := writeTaskJSON(w, r, http.StatusOK, task); err != nil {
+				logRequest(r, slog.LevelError, "[todoHandler] Encoding task", err, "task_id", id)
 				return
 			}
 
-		case http.MethodPut: // PUT /todos/{id}
+		case http.MethodPut: // PUT /todos/{id}, PUT /todos/{id}?dry_run=true
+			dryRun, err := parseBoolQuery(r, "dry_run")
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todoHandler] Invalid dry_run", err, "task_id", id)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			body, err := readBody(r)
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todoHandler] Reading body", err, "task_id", id)
+				writeDecodeError(w, err)
+				return
+			}
+			if !utf8.Valid(body) { // encoding/json молча заменил бы некорректные байты на U+FFFD
+				logRequest(r, slog.LevelWarn, "[todoHandler] Invalid UTF-8 in body", nil, "task_id", id)
+				writeJSONError(w, http.StatusBadRequest, "request body must be valid UTF-8")
+				return
+			}
 			var t Task
-			if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
-				log.Printf("[todoHandler] error: Decoding: %v", err)
-				http.Error(w, "invalid JSON", http.StatusBadRequest)
+			if err := json.Unmarshal(body, &t); err != nil {
+				logRequest(r, slog.LevelWarn, "[todoHandler] Decoding", err, "task_id", id)
+				writeDecodeError(w, err)
 				return
 			}
+			if t.ID != 0 && t.ID != id { // ID задачи менять нельзя, в теле он либо не указан, либо совпадает с путём
+				logRequest(r, slog.LevelWarn, "[todoHandler] Body id does not match path id", nil, "task_id", id, "body_id", t.ID)
+				writeJSONError(w, http.StatusBadRequest, "id in body does not match id in path")
+				return
+			}
+			t.ID = id
+			t.Version = ifMatchVersion(r) // версию задачи задаёт только If-Match
 			t.Preprocess()
-			if err := t.Validate(); err != nil {
-				log.Printf("[todoHandler] error: Validation: %v", err)
-				http.Error(w, err.Error(), http.StatusBadRequest)
+			if err := t.Validate(validationConfig(ts)); err != nil {
+				logRequest(r, slog.LevelWarn, "[todoHandler] Validation", err, "task_id", id)
+				writeValidationError(w, validationConfig(ts), err)
+				return
+			}
+			if dryRun { // только проверки: задача не меняется
+				preview, created, err := ts.PreviewUpsertTask(id, t)
+				if err != nil {
+					logRequest(r, slog.LevelWarn, "[todoHandler] Dry run", err, "task_id", id)
+					writeJSONError(w, updateErrorStatus(err), err.Error())
+					return
+				}
+				status := http.StatusOK
+				if created { // реальный PUT создал бы задачу
+					status = http.StatusCreated
+				}
+				if err := writeTaskJSON(w, r, status, preview); err != nil {
+					logRequest(r, slog.LevelError, "[todoHandler] Encoding task", err, "task_id", id)
+				}
 				return
 			}
-			updated, err := ts.UpdateTask(id, t)
+			updated, created, err := UpsertTaskCtx(r.Context(), ts, id, t)
 			if err != nil {
-				log.Printf("[todoHandler] error: Updating task: %v", err)
-				http.Error(w, err.Error(), http.StatusNotFound)
+				logRequest(r, slog.LevelWarn, "[todoHandler] Upserting task", err, "task_id", id)
+				writeJSONError(w, updateErrorStatus(err), err.Error())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			if err := json.NewEncoder(w).Encode(updated); err != nil {
-				log.Printf("[todoHandler] error: Encoding task: %v", err)
+			status := http.StatusOK
+			if created { // задачи с таким ID не было, PUT её создал
+				status = http.StatusCreated
+				w.Header().Set("Location", r.URL.Path)
+			}
+			if err := writeMutationResult(w, r, status, updated, r.URL.Path, true); err != nil {
+				logRequest(r, slog.LevelError, "[todoHandler] Encoding task", err, "task_id", id)
 				return
 			}
 
-		case http.MethodDelete: // DELETE /todos/{id}
-			if err := ts.DeleteTask(id); err != nil {
-				log.Printf("[todoHandler] error: Deleting task: %v", err)
-				http.Error(w, err.Error(), http.StatusNotFound)
+		case http.MethodDelete: // DELETE /todos/{id}, DELETE /todos/{id}?cascade=true
+			cascade, err := parseBoolQuery(r, "cascade")
+			if err != nil {
+				logRequest(r, slog.LevelWarn, "[todoHandler] Invalid cascade", err, "task_id", id)
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := DeleteTaskCtx(r.Context(), ts, id, ifMatchVersion(r), cascade); err != nil {
+				logRequest(r, slog.LevelWarn, "[todoHandler] Deleting task", err, "task_id", id)
+				writeJSONError(w, updateErrorStatus(err), err.Error())
 				return
 			}
 			w.WriteHeader(http.StatusNoContent)
 
 		default:
-			log.Println("[todoHandler] error: Invalid method")
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			logRequest(r, slog.LevelWarn, "[todoHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+// parseBoolQuery Разбор логического параметра запроса (false, если параметр не передан)
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	if !r.URL.Query().Has(name) {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(r.URL.Query().Get(name))
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return value, nil
+}
+
+// restoreHandler Обработчик эндпоинта /todos/{id}/restore
+func restoreHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			logRequest(r, slog.LevelWarn, "[restoreHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[restoreHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		task, err := ts.RestoreTask(id)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[restoreHandler] Restoring task", err, "task_id", id)
+			writeJSONError(w, revertErrorStatus(err), err.Error())
+			return
+		}
+		w.Header().Set("ETag", taskETag(task))
+		if err := writeTaskJSON(w, r, http.StatusOK, task); err != nil {
+			logRequest(r, slog.LevelError, "[restoreHandler] Encoding task", err, "task_id", id)
+			return
 		}
 	}
 }
 
 // healthzHandler Обработчик эндпоинта /healthz (проверка статуса сервера)
 func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
 	w.WriteHeader(http.StatusOK)
 }
 
-func main() {
-	ts := NewTaskStore()
+// taskRoute Эндпоинт работы с задачами
+type taskRoute struct {
+	pattern string
+	handler func(ts *TaskStore) http.HandlerFunc
+}
+
+// taskRoutes Эндпоинты работы с задачами (доступны и в общем пространстве, и под /t/{tenant})
+var taskRoutes = []taskRoute{
+	{"/todos", negotiated(storeHandler(todosHandler))},
+	{"/todos/count", countHandler},
+	{"/todos/summary", summaryHandler},
+	{"/todos/stats", taskStatsHandler},
+	{"/todos/export", exportHandler},
+	{"/todos/import", importHandler},
+	{"/todos/events", eventsHandler},
+	{"/todos/{id}", negotiated(storeHandler(todoHandler))},
+	{"/todos/{id}/checklist", negotiated(checklistHandler)},
+	{"/todos/{id}/watchers", negotiated(watchersHandler)},
+	{"/todos/{id}/progress", negotiated(progressHandler)},
+	{"/todos/{id}/restore", negotiated(restoreHandler)},
+	{"/todos/{id}/undo", negotiated(undoHandler)},
+	{"/todos/{id}/subtasks", negotiated(subtasksHandler)},
+	{"/todos/{id}/history", historyHandler},
+	{"/todos/{id}/diff", diffHandler},
+	{"/todos/{id}/duplicate", negotiated(storeHandler(duplicateHandler))},
+	// не /todos/assigned/{user}: такой шаблон конфликтует в ServeMux с /todos/{id}/checklist и соседними
+	{"/users/{user}/todos", negotiated(storeHandler(assignedHandler))},
+}
+
+// newMux Регистрация всех эндпоинтов сервера
+func newMux(ts *TaskStore, tr *TenantRegistry, stats *Stats, ready *atomic.Bool) *http.ServeMux {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/todos", todosHandler(ts))
-	mux.HandleFunc("/todos/{id}", todoHandler(ts))
+	for _, version := range apiVersions {
+		registerTaskRoutes(mux, version.prefix, version.routes, ts, tr, nil)
+	}
+	// пути без версии - на переходный период, отвечают как текущая версия с предупреждением
+	registerTaskRoutes(mux, "", apiVersions[len(apiVersions)-1].routes, ts, tr, deprecatedPath)
 	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/livez", livezHandler)
+	mux.HandleFunc("/readyz", readyzHandler(ready, ts))
+	mux.HandleFunc("/stats", statsHandler(stats))
+	mux.HandleFunc("/metrics", metricsHandler(stats))
+	mux.HandleFunc("/openapi.json", openAPIHandler)
+
+	return mux
+}
+
+// defaultAddr Адрес для прослушивания по умолчанию
+const defaultAddr = ":8080"
+
+// listenAddr Адрес для прослушивания: флаг -addr, затем переменная окружения ADDR, затем PORT, затем :8080
+func listenAddr(flagAddr string) string {
+	if flagAddr != "" {
+		return flagAddr
+	}
+	if addr := os.Getenv("ADDR"); addr != "" {
+		return addr
+	}
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return defaultAddr
+}
 
-	log.Println("[main] info: Starting listening on http://localhost:8080")
-	if err := http.ListenAndServe(":8080", mux); err != nil {
-		log.Printf("[main] error: Server error: %v", err)
+func main() {
+	config := DefaultStoreConfig()
+	config.Stats = NewStats()
+	flag.BoolVar(&config.UniqueExternalIDs, "unique-external-id", config.UniqueExternalIDs,
+		"reject tasks whose external_id is already used by another task")
+	flag.BoolVar(&config.StatusNumbering, "status-numbering", config.StatusNumbering,
+		"include status_number (position of a task within its status) in responses")
+	flag.Float64Var(&config.CompactionRatio, "compaction-ratio", config.CompactionRatio,
+		"rebuild the task map once this share of its entries was deleted (0 disables compaction)")
+	compactionInterval := flag.Duration("compaction-interval", time.Minute, "how often to purge expired trash and check whether compaction is needed")
+	flag.DurationVar(&config.TrashRetention, "trash-retention", config.TrashRetention,
+		"how long deleted tasks stay restorable before they are purged (0 keeps them forever)")
+	flag.BoolVar(&config.ProgressFollowsStatus, "progress-follows-status", config.ProgressFollowsStatus,
+		"set progress to 100 when a task is completed and to 0 when it is reopened")
+	flag.BoolVar(&config.FillIDGaps, "fill-id-gaps", false,
+		"give new tasks the lowest free ID instead of the next one after the highest issued ID")
+	flag.BoolVar(&config.InferParentStatus, "infer-parent-status", false,
+		"derive the status of a task with subtasks from their statuses and reject manual status changes on it")
+	logLevel := flag.String("log-level", "info", "log verbosity: debug, info, warn or error")
+	addrFlag := flag.String("addr", "", "listen address (default $ADDR, then :$PORT, then "+defaultAddr+")")
+	selfTest := flag.Bool("selftest", false, "check store operations on startup and exit with an error if they fail")
+	var cache CacheConfig
+	flag.DurationVar(&cache.MaxAge, "cache-max-age", 0, "max-age of Cache-Control on GET responses")
+	flag.DurationVar(&cache.StaleWhileRevalidate, "cache-stale-while-revalidate", 0,
+		"stale-while-revalidate of Cache-Control on GET responses")
+	requireIfMatch := flag.Bool("require-if-match", false, "reject PUT, PATCH and DELETE requests without an If-Match header")
+	shutdownDelay := flag.Duration("shutdown-delay", 0,
+		"how long to keep serving with /readyz reporting 503 before shutting down, so load balancers stop routing traffic")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 15*time.Second, "how long to wait for in-flight requests on shutdown")
+	maxBodyBytes := flag.Int64("max-body-bytes", defaultMaxBodyBytes, "maximum request body size in bytes (0 disables the limit)")
+	requestTimeout := flag.Duration("request-timeout", defaultRequestTimeout, "how long a request may run before it is aborted with 503 (0 disables the limit)")
+	flag.DurationVar(&config.IdempotencyTTL, "idempotency-ttl", config.IdempotencyTTL,
+		"how long to remember Idempotency-Key headers on POST /todos (0 disables idempotency keys)")
+	flag.IntVar(&config.MaxTasks, "max-tasks", 0, "maximum number of tasks per store, including deleted ones (0 means unlimited)")
+	flag.IntVar(&config.MaxTenants, "max-tenants", config.MaxTenants, "maximum number of tenant stores (0 means unlimited)")
+	flag.IntVar(&config.HistoryLimit, "history-limit", config.HistoryLimit,
+		"how many latest history entries to keep in memory per task (0 means unlimited)")
+	evictionPolicy := flag.String("eviction-policy", string(config.EvictionPolicy),
+		"what to do when -max-tasks is reached: reject or evict-completed")
+	flag.IntVar(&config.Validation.MaxTitleLen, "max-title-len", 0, "maximum task title length in characters (0 means unlimited)")
+	flag.IntVar(&config.Validation.MaxDescriptionLen, "max-desc-len", 0, "maximum task description length in characters (0 means unlimited)")
+	flag.BoolVar(&config.Validation.SingleError, "single-validation-error", false,
+		"report only the first validation problem with 400 instead of all problems with 422")
+	flag.BoolVar(&config.Validation.RequireDescription, "require-description", false, "reject tasks with an empty description")
+	auditFile := flag.String("audit-file", "", "append the audit log of task changes to this file as JSON lines")
+	jwtSecret := flag.String("jwt-secret", "", "require Bearer JWTs signed with this HS256 shared secret")
+	jwtPublicKey := flag.String("jwt-public-key", "", "require Bearer JWTs signed with RS256, verified by this PEM public key file")
+	apiKeysList := flag.String("api-keys", "", "comma-separated name:key[:role] entries; when set, requests need a matching X-API-Key header")
+	apiKeysFile := flag.String("api-keys-file", "", "file with name:key[:role] entries, one per line (# starts a comment)")
+	webhookURLs := flag.String("webhook-urls", "", "comma-separated URLs notified with a POST on every task change")
+	var webhooks WebhookConfig
+	flag.StringVar(&webhooks.Secret, "webhook-secret", "", "HMAC-SHA256 key for the X-Webhook-Signature header (empty disables signing)")
+	flag.IntVar(&webhooks.Retries, "webhook-retries", 3, "how many times to retry a failed webhook delivery")
+	flag.DurationVar(&webhooks.Backoff, "webhook-backoff", time.Second, "delay before the first webhook retry, doubled for each next one")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (serve HTTPS when set together with -tls-key)")
+	tlsKey := flag.String("tls-key", "", "TLS private key file (serve HTTPS when set together with -tls-cert)")
+	timeouts := DefaultServerTimeouts()
+	flag.DurationVar(&timeouts.ReadHeader, "read-header-timeout", timeouts.ReadHeader, "how long the server waits for request headers (0 disables the limit)")
+	flag.DurationVar(&timeouts.Read, "read-timeout", timeouts.Read, "how long the server waits for the whole request, including the body (0 disables the limit)")
+	flag.DurationVar(&timeouts.Write, "write-timeout", timeouts.Write, "how long the server may spend writing a response (0 disables the limit; /todos/events is exempt)")
+	flag.DurationVar(&timeouts.Idle, "idle-timeout", timeouts.Idle, "how long a keep-alive connection may stay idle (0 disables the limit)")
+	enablePprof := flag.Bool("enable-pprof", false, "serve net/http/pprof under /debug/pprof/ on the admin listener")
+	adminAddr := flag.String("admin-addr", defaultAdminAddr, "listen address of the admin listener (used with -enable-pprof or a user list)")
+	usersList := flag.String("users", os.Getenv("USERS"), "comma-separated names allowed as task assignees (default $USERS; empty allows any assignee)")
+	usersFile := flag.String("users-file", os.Getenv("USERS_FILE"), "file with names allowed as task assignees, one per line (default $USERS_FILE)")
+	flag.IntVar(&config.Stats.assignees.limit, "stats-max-assignees", defaultMaxTrackedAssignees,
+		"maximum number of assignees tracked in task creation stats, least recently seen are evicted first (0 disables tracking)")
+	flag.IntVar(&config.AssigneeLimits.Default, "assignee-limit", 0, "maximum number of active tasks per assignee (0 means unlimited)")
+	assigneeLimits := flag.String("assignee-limits", "", "comma-separated name:limit entries overriding -assignee-limit for single assignees")
+	flag.Parse()
+
+	logger, err := newLogger(os.Stderr, *logLevel)
+	if err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+	if config.EvictionPolicy, err = parseEvictionPolicy(*evictionPolicy); err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	useTLS, err := tlsEnabled(*tlsCert, *tlsKey)
+	if err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	if webhooks.URLs, err = parseWebhookURLs(*webhookURLs); err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	if config.AssigneeLimits.PerAssignee, err = parseAssigneeLimits(*assigneeLimits); err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	if config.Validation.Users, err = loadUsers(*usersList, *usersFile); err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+
+	if *auditFile != "" {
+		file, err := os.OpenFile(*auditFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+		if err != nil {
+			slog.Error("[main] Opening audit log", "error", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		config.AuditLog = file
+	}
+	if len(webhooks.URLs) > 0 { // все хранилища, включая хранилища тенантов, отправляют события в общий канал
+		notify := make(chan TaskEvent, webhookQueueSize)
+		config.Notify = notify
+		go runWebhooks(webhooks, notify)
+	}
+	ts := NewTaskStoreWithConfig(config)
+	tr := NewTenantRegistry(config)
+	if *selfTest {
+		if err := runSelfTest(config); err != nil {
+			slog.Error("[main] Self-test failed", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("[main] Self-test passed")
+	}
+	verifier, err := newJWTVerifier(*jwtSecret, *jwtPublicKey)
+	if err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	apiKeys, err := loadAPIKeys(*apiKeysList, *apiKeysFile)
+	if err != nil {
+		slog.Error("[main] Invalid configuration", "error", err)
+		os.Exit(2)
+	}
+	if verifier != nil && apiKeys != nil {
+		slog.Error("[main] Invalid configuration", "error", "JWT and API key authentication cannot be combined")
+		os.Exit(2)
+	}
+	ready := new(atomic.Bool) // готовность принимать трафик (/readyz)
+	var mux http.Handler = maxBodyMiddleware(*maxBodyBytes, timeoutMiddleware(*requestTimeout, newMux(ts, tr, config.Stats, ready)))
+	if *requireIfMatch {
+		mux = requireIfMatchMiddleware(mux)
+	}
+	if verifier != nil || apiKeys != nil {
+		mux = requireAdminForDelete(mux)
+	}
+	if verifier != nil {
+		mux = jwtMiddleware(verifier, mux)
+	}
+	if apiKeys != nil {
+		mux = apiKeyMiddleware(apiKeys, mux)
+	}
+	if config.CompactionRatio > 0 || config.TrashRetention > 0 {
+		go runCompaction(*compactionInterval, func() []*TaskStore {
+			return append(tr.Stores(), ts)
+		})
+	}
+	handler := requestIDMiddleware(statsMiddleware(config.Stats, recoverMiddleware(gzipMiddleware(cacheControlMiddleware(cache, mux)))))
+
+	addr := listenAddr(*addrFlag)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("[main] Server error", "error", err)
+		os.Exit(1)
+	}
+	server := &http.Server{Handler: handler, TLSConfig: newTLSConfig()}
+	timeouts.apply(server)
+	serveErr := make(chan error, 1)
+	go func() {
+		if useTLS {
+			serveErr <- server.ServeTLS(listener, *tlsCert, *tlsKey)
+			return
+		}
+		serveErr <- server.Serve(listener)
+	}()
+	slog.Info("[main] Starting listening", "addr", addr, "tls", useTLS,
+		"read_header_timeout", timeouts.ReadHeader.String(), "read_timeout", timeouts.Read.String(),
+		"write_timeout", timeouts.Write.String(), "idle_timeout", timeouts.Idle.String())
+	var admin *http.Server
+	if *enablePprof || config.Validation.Users != nil {
+		admin = &http.Server{Addr: *adminAddr, Handler: newAdminMux(*enablePprof, config.Validation.Users)}
+		go func() {
+			if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("[main] Admin server error", "error", err)
+			}
+		}()
+		slog.Info("[main] Serving admin listener", "addr", *adminAddr, "pprof", *enablePprof)
+	}
+	ready.Store(true)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serveErr:
+		slog.Error("[main] Server error", "error", err)
+		return
+	case <-ctx.Done():
+	}
+	// сначала снимаем готовность, чтобы балансировщик перестал присылать запросы, затем дожидаемся текущих
+	ready.Store(false)
+	slog.Info("[main] Shutting down", "delay", shutdownDelay.String(), "timeout", shutdownTimeout.String())
+	time.Sleep(*shutdownDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("[main] Shutdown error", "error", err)
+	}
+	if admin != nil {
+		if err := admin.Shutdown(shutdownCtx); err != nil {
+			slog.Error("[main] Admin shutdown error", "error", err)
+		}
 	}
 }
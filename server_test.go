@@ -6,24 +6,38 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // Запуск тестового сервера
 func startTestServer() *httptest.Server {
-	ts := NewTaskStore()
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/todos", todosHandler(ts))
-	mux.HandleFunc("/todos/{id}", todoHandler(ts))
+	config := DefaultStoreConfig()
+	config.Stats = NewStats()
+	return startTestServerWithStore(NewTaskStoreWithConfig(config))
+}
 
-	return httptest.NewServer(mux)
+// Запуск тестового сервера поверх заданного хранилища (для подготовки данных напрямую через хранилище)
+func startTestServerWithStore(ds *TaskStore) *httptest.Server {
+	stats := ds.config.Stats
+	if stats == nil {
+		stats = NewStats()
+	}
+	ready := new(atomic.Bool)
+	ready.Store(true)
+	mux := maxBodyMiddleware(defaultMaxBodyBytes, newMux(ds, NewTenantRegistry(ds.config), stats, ready))
+	return httptest.NewServer(requestIDMiddleware(statsMiddleware(stats, gzipMiddleware(cacheControlMiddleware(CacheConfig{}, mux)))))
 }
 
-// Проверка создания задачи и обработки дубликатов
+// Проверка создания задачи и назначения ID
 // Сценарий:
-// 1. Создать задачу с уникальным ID - ожидаем успех (201 Created).
-// 2. Попытаться создать задачу с тем же ID - ожидаем ошибку (400 Bad Request).
+// 1. Создать задачу - ожидаем успех (201 Created), созданную задачу с ID 1 в теле ответа и Location: /todos/1.
+// 2. Повторно отправить то же тело с явно указанным ID - ожидаем успех (201 Created) и новый ID 2,
+// присланный клиентом ID игнорируется.
 func TestCreateTask(t *testing.T) {
 	ts := startTestServer()
 
@@ -38,14 +52,30 @@ func TestCreateTask(t *testing.T) {
 	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
 		t.Errorf("expected 201, got %d", resp.StatusCode)
 	}
-	// Попробуем создать дубликат
+	var created Task
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID != 1 || created.Title != "Task 1" { // задача НЕ возвращена
+		t.Errorf("unexpected created task %+v", created)
+	}
+	if location := resp.Header.Get("Location"); location != "/todos/1" { // ссылка на задачу НЕ возвращена
+		t.Errorf("expected Location /todos/1, got %q", location)
+	}
+	// Отправляем то же тело повторно
 	resp2, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
 	if err != nil {
 		t.Fatalf("failed to make POST: %v", err)
 	}
-	// Ожидаем ошибку 400
-	if resp2.StatusCode != http.StatusBadRequest { // получили НЕ 400
-		t.Errorf("expected 400 for duplicate id, got %d", resp2.StatusCode)
+	// Ожидаем новую задачу со следующим ID
+	if resp2.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Errorf("expected 201 for repeated body, got %d", resp2.StatusCode)
+	}
+	if err := json.NewDecoder(resp2.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID != 2 { // клиентский ID НЕ проигнорирован
+		t.Errorf("expected server-assigned id 2, got %d", created.ID)
 	}
 	if err := resp.Body.Close(); err != nil {
 		t.Fatalf("failed to close response body: %v", err)
@@ -58,7 +88,8 @@ func TestCreateTask(t *testing.T) {
 
 // Проверка валидации при создании задачи
 // Сценарий:
-// 1. Попытаться создать задачу с некорректными данными (пустой заголовок, неверный статус) - ожидаем ошибку (400 Bad Request).
+// 1. Попытаться создать задачу с некорректными данными (пустой заголовок, неверный статус) - ожидаем ошибку
+// (422 Unprocessable Entity) со списком обеих проблем.
 func TestCreateTaskValidation(t *testing.T) {
 	ts := startTestServer()
 
@@ -69,9 +100,17 @@ func TestCreateTaskValidation(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to make POST: %v", err)
 	}
-	// Ожидаем ошибку 400
-	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
-		t.Errorf("expected 400, got %d", resp.StatusCode)
+	// Ожидаем ошибку 422 со всеми проблемами
+	if resp.StatusCode != http.StatusUnprocessableEntity { // получили НЕ 422
+		t.Errorf("expected 422, got %d", resp.StatusCode)
+	}
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []FieldError{{Field: "title", Message: "title cannot be empty"}, {Field: "status", Message: "invalid status"}}
+	if !slices.Equal(errResp.Errors, want) { // перечислены НЕ все проблемы
+		t.Errorf("expected errors %v, got %v", want, errResp.Errors)
 	}
 	if err := resp.Body.Close(); err != nil {
 		t.Fatalf("failed to close response body: %v", err)
@@ -86,7 +125,7 @@ func TestCreateTaskValidation(t *testing.T) {
 func TestGetTask(t *testing.T) {
 	ts := startTestServer()
 
-	task := Task{ID: 2, Title: "Read", Status: StatusNotStarted}
+	task := Task{Title: "Read", Status: StatusNotStarted}
 	body, _ := json.Marshal(task)
 	// Создаём задачу
 	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
@@ -94,7 +133,7 @@ func TestGetTask(t *testing.T) {
 		t.Fatalf("failed to make POST: %v", err)
 	}
 	// Получаем задачу по ID
-	resp, err := http.Get(ts.URL + "/todos/2")
+	resp, err := http.Get(ts.URL + "/todos/1")
 	if err != nil {
 		t.Fatalf("failed to make GET: %v", err)
 	}
@@ -107,7 +146,7 @@ func TestGetTask(t *testing.T) {
 		t.Fatalf("failed to decode response: %v", err)
 	}
 	// Проверяем корректность данных
-	if got.ID != 2 || got.Title != "Read" { // данные НЕ корректны
+	if got.ID != 1 || got.Title != "Read" { // данные НЕ корректны
 		t.Errorf("unexpected task %+v", got)
 	}
 	if err := resp.Body.Close(); err != nil {
@@ -119,11 +158,11 @@ func TestGetTask(t *testing.T) {
 // Проверка обновления задачи
 // Сценарий:
 // 1. Создать задачу.
-// 2. Обновить задачу по ID - ожидаем успех (200 OK) и обновлённые данные.
+// 2. Обновить задачу по ID (с переводом в работу) - ожидаем успех (200 OK) и обновлённые данные.
 func TestUpdateTask(t *testing.T) {
 	ts := startTestServer()
 
-	task := Task{ID: 10, Title: "Old", Status: StatusNotStarted}
+	task := Task{Title: "Old", Status: StatusNotStarted}
 	body, _ := json.Marshal(task)
 	// Создаём задачу
 	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
@@ -131,9 +170,9 @@ func TestUpdateTask(t *testing.T) {
 		t.Fatalf("failed to make POST: %v", err)
 	}
 	// Обновляем задачу
-	update := Task{ID: 10, Title: "New", Status: StatusCompleted}
+	update := Task{ID: 1, Title: "New", Status: StatusInProgress}
 	body, _ = json.Marshal(update)
-	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/10", bytes.NewBuffer(body))
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -149,7 +188,7 @@ func TestUpdateTask(t *testing.T) {
 		t.Fatalf("failed to decode response: %v", err)
 	}
 	// Проверяем обновлённые данные
-	if updated.Title != "New" || updated.Status != StatusCompleted { // данные НЕ обновлены
+	if updated.Title != "New" || updated.Status != StatusInProgress { // данные НЕ обновлены
 		t.Errorf("task not updated: %+v", updated)
 	}
 	if err := resp.Body.Close(); err != nil {
@@ -166,7 +205,7 @@ func TestUpdateTask(t *testing.T) {
 func TestDeleteTask(t *testing.T) {
 	ts := startTestServer()
 
-	task := Task{ID: 5, Title: "Del", Status: StatusNotStarted}
+	task := Task{Title: "Del", Status: StatusNotStarted}
 	body, _ := json.Marshal(task)
 	// Создаём задачу
 	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
@@ -174,7 +213,7 @@ func TestDeleteTask(t *testing.T) {
 		t.Fatalf("failed to make POST: %v", err)
 	}
 	// Удаляем задачу
-	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/5", nil)
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("failed to make DELETE: %v", err)
@@ -184,7 +223,7 @@ func TestDeleteTask(t *testing.T) {
 		t.Errorf("expected 204, got %d", resp.StatusCode)
 	}
 	// Пытаемся получить удалённую задачу
-	resp2, err := http.Get(ts.URL + "/todos/5")
+	resp2, err := http.Get(ts.URL + "/todos/1")
 	if err != nil {
 		t.Fatalf("failed to make GET: %v", err)
 	}
@@ -200,3 +239,662 @@ func TestDeleteTask(t *testing.T) {
 	}
 	ts.Close()
 }
+
+// Проверка восстановления подзадачи, родитель которой недоступен
+// Сценарий:
+// 1. Удалить в корзину дерево из родителя 1 и подзадачи 2 и восстановить подзадачу - ожидаем ошибку (409 Conflict).
+// 2. Восстановить родителя, затем подзадачу - ожидаем успех (200 OK).
+// 3. Удалить подзадачу, завершить родителя и восстановить подзадачу - ожидаем ошибку (409 Conflict).
+func TestRestoreSubtask(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	parentID := 1
+	for _, task := range []Task{
+		{Title: "Parent", Status: StatusInProgress},
+		{Title: "Child", Status: StatusInProgress, ParentID: &parentID},
+	} {
+		if _, err := ds.AddTask(task); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	restore := func(id int) int {
+		resp, err := http.Post(ts.URL+"/todos/"+strconv.Itoa(id)+"/restore", "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode
+	}
+	if err := ds.DeleteTaskTree(1, 0); err != nil {
+		t.Fatalf("failed to delete tree: %v", err)
+	}
+	// Родитель в корзине
+	if status := restore(2); status != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409 while the parent is deleted, got %d", status)
+	}
+	for _, id := range []int{1, 2} {
+		if status := restore(id); status != http.StatusOK { // получили НЕ 200
+			t.Errorf("expected 200 restoring task %d, got %d", id, status)
+		}
+	}
+	// Родитель завершён
+	if err := ds.DeleteTask(2, 0); err != nil {
+		t.Fatalf("failed to delete subtask: %v", err)
+	}
+	if _, err := ds.UpdateTask(1, Task{Title: "Parent", Status: StatusCompleted}); err != nil {
+		t.Fatalf("failed to complete parent: %v", err)
+	}
+	if status := restore(2); status != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409 while the parent is completed, got %d", status)
+	}
+}
+
+// Проверка удаления в корзину и восстановления задачи
+// Сценарий:
+// 1. Создать и удалить задачу - ожидаем, что GET /todos её не возвращает.
+// 2. Запросить список с ?include_deleted=true - ожидаем удалённую задачу с заполненным deleted_at.
+// 3. Восстановить задачу - ожидаем успех (200 OK), задача снова доступна через GET /todos/{id}.
+// 4. Повторно восстановить задачу и восстановить несуществующую - ожидаем ошибку (404 Not Found).
+func TestRestoreTask(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём и удаляем задачу
+	body, _ := json.Marshal(Task{Title: "Trash", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make DELETE: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	// Удалённой задачи нет в обычном списке
+	resp, err = http.Get(ts.URL + "/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var list []Task
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(list) != 0 { // удалённая задача попала в список
+		t.Errorf("expected empty list, got %d tasks", len(list))
+	}
+	// Удалённая задача есть в списке с include_deleted
+	resp, err = http.Get(ts.URL + "/todos?include_deleted=true")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	list = nil
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(list) != 1 || list[0].DeletedAt == nil { // удалённой задачи НЕТ в списке
+		t.Fatalf("expected one deleted task, got %+v", list)
+	}
+	// Восстанавливаем задачу
+	resp, err = http.Post(ts.URL+"/todos/1/restore", "application/json", nil)
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	var restored Task
+	if err := json.NewDecoder(resp.Body).Decode(&restored); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if restored.DeletedAt != nil { // задача всё ещё помечена удалённой
+		t.Errorf("expected deleted_at to be cleared, got %v", restored.DeletedAt)
+	}
+	resp, err = http.Get(ts.URL + "/todos/1")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Errorf("expected 200 after restore, got %d", resp.StatusCode)
+	}
+	// Повторное восстановление и восстановление несуществующей задачи
+	for _, path := range []string{"/todos/1/restore", "/todos/42/restore"} {
+		resp, err = http.Post(ts.URL+path, "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound { // получили НЕ 404
+			t.Errorf("POST %s: expected 404, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+// Проверка уникальности внешнего идентификатора и поиска по нему
+// Сценарий:
+// 1. Создать задачу с external_id - ожидаем успех (201 Created).
+// 2. Создать другую задачу с тем же external_id - ожидаем ошибку (409 Conflict).
+// 3. Найти задачу через GET /todos?external_id= - ожидаем ровно одну первую задачу.
+func TestExternalID(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{ID: 1, Title: "Synced", Status: StatusNotStarted, ExternalID: "JIRA-1"})
+	// Создаём задачу с внешним ID
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Errorf("expected 201, got %d", resp.StatusCode)
+	}
+	// Пытаемся создать задачу с тем же внешним ID
+	body, _ = json.Marshal(Task{ID: 2, Title: "Synced again", Status: StatusNotStarted, ExternalID: "JIRA-1"})
+	resp2, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	// Ожидаем ошибку 409
+	if resp2.StatusCode != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409 for duplicate external id, got %d", resp2.StatusCode)
+	}
+	// Ищем задачу по внешнему ID
+	resp3, err := http.Get(ts.URL + "/todos?external_id=JIRA-1")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var found []Task
+	if err := json.NewDecoder(resp3.Body).Decode(&found); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != 1 { // найдено НЕ то
+		t.Errorf("unexpected lookup result %+v", found)
+	}
+	for _, r := range []*http.Response{resp, resp2, resp3} {
+		if err := r.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
+
+// Проверка маскирования полей ответа через заголовок X-Fields
+// Сценарий:
+// 1. Создать задачу.
+// 2. Получить задачу с X-Fields: id,title,unknown - ожидаем только поля id и title.
+// 3. Получить список с пустым X-Fields - ожидаем все поля.
+func TestFieldsMask(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{Title: "Masked", Description: "secret", Status: StatusNotStarted})
+	// Создаём задачу
+	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	// Получаем задачу с маской полей
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/todos/1", nil)
+	req.Header.Set("X-Fields", "id, title, unknown")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	// Проверяем, что остались только запрошенные поля
+	if len(got) != 2 || got["title"] != "Masked" || got["id"] != float64(1) { // маска НЕ применена
+		t.Errorf("unexpected masked task %v", got)
+	}
+	// Получаем список с пустым заголовком
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/todos", nil)
+	req.Header.Set("X-Fields", "")
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var list []Task
+	if err := json.NewDecoder(resp2.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list) != 1 || list[0].Description != "secret" { // поля потерялись
+		t.Errorf("unexpected list %+v", list)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp2.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка неизменяемости ID при обновлении
+// Сценарий:
+// 1. Создать задачу.
+// 2. Обновить задачу, указав в теле другой ID - ожидаем ошибку (400 Bad Request).
+// 3. Обновить задачу без ID в теле - ожидаем успех (200 OK).
+func TestUpdateTaskIDMismatch(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{Title: "Immutable", Status: StatusNotStarted})
+	// Создаём задачу
+	_, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	// Обновляем задачу с чужим ID в теле
+	body, _ = json.Marshal(Task{ID: 2, Title: "Changed", Status: StatusNotStarted})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	// Ожидаем ошибку 400
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for id mismatch, got %d", resp.StatusCode)
+	}
+	// Обновляем задачу без ID в теле
+	body, _ = json.Marshal(Task{Title: "Changed", Status: StatusNotStarted})
+	req, _ = http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	// Ожидаем успех 200
+	if resp2.StatusCode != http.StatusOK { // получили НЕ 200
+		t.Errorf("expected 200 for omitted body id, got %d", resp2.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp2.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка уникальности автоматически назначаемых ID при параллельном создании
+// Сценарий:
+// 1. Параллельно создать 1000 задач - ожидаем, что все полученные ID различны и идут подряд с 1.
+// 2. Создать задачу с явным ID, уже выданным автоматически - ожидаем ошибку.
+func TestAddTaskConcurrentIDs(t *testing.T) {
+	ds := NewTaskStore()
+	const n = 1000
+
+	// Параллельно создаём задачи
+	ids := make(chan int, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			task, err := ds.AddTask(Task{Title: "Concurrent", Status: StatusNotStarted})
+			if err != nil {
+				t.Errorf("failed to add task: %v", err)
+				return
+			}
+			ids <- task.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	// Проверяем уникальность ID
+	seen := make(map[int]bool, n)
+	for id := range ids {
+		if seen[id] || id < 1 || id > n { // ID повторился или вышел за диапазон
+			t.Errorf("unexpected or duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != n {
+		t.Errorf("expected %d unique ids, got %d", n, len(seen))
+	}
+	// Явный ID не должен пересекаться с выданными
+	if err := ds.CreateTask(Task{ID: 1, Title: "Duplicate", Status: StatusNotStarted}); err == nil {
+		t.Errorf("expected error for duplicate explicit id")
+	}
+}
+
+// Нагрузочная проверка уникальности ID при параллельном создании через API
+// Сценарий:
+// 1. Параллельно отправить 400 POST /todos с одной задачей, 100 POST /todos с пакетом из 3 задач
+// и 100 PUT /todos/{id} с явными ID, сдвигающими счётчик автоматических ID.
+// 2. Ожидаем, что все созданные (201) задачи получили разные ID и все они есть в хранилище: POST не выдаёт
+// занятый ID, а PUT на ID, который успел выдать POST, обновляет задачу (200), а не создаёт вторую.
+func TestCreateConcurrentIDsStress(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	var (
+		mutex sync.Mutex
+		ids   []int
+		wg    sync.WaitGroup
+	)
+	send := func(method, path string, v any) {
+		defer wg.Done()
+		body, _ := json.Marshal(v)
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Errorf("failed to make %s: %v", method, err)
+			return
+		}
+		defer resp.Body.Close()
+		if method == http.MethodPut && resp.StatusCode == http.StatusOK { // ID уже успел выдать POST, PUT обновил задачу
+			return
+		}
+		if resp.StatusCode != http.StatusCreated { // задачу НЕ создали
+			t.Errorf("%s %s: expected 201, got %d", method, path, resp.StatusCode)
+			return
+		}
+		var created []Task
+		if _, batch := v.([]Task); batch {
+			err = json.NewDecoder(resp.Body).Decode(&created)
+		} else {
+			created = make([]Task, 1)
+			err = json.NewDecoder(resp.Body).Decode(&created[0])
+		}
+		if err != nil {
+			t.Errorf("failed to decode response: %v", err)
+			return
+		}
+		mutex.Lock()
+		for _, task := range created {
+			ids = append(ids, task.ID)
+		}
+		mutex.Unlock()
+	}
+	task := Task{Title: "Stress", Status: StatusNotStarted}
+	for i := range 400 {
+		wg.Add(1)
+		go send(http.MethodPost, "/todos", task)
+		if i%4 == 0 {
+			wg.Add(2)
+			go send(http.MethodPost, "/todos", []Task{task, task, task})
+			go send(http.MethodPut, "/todos/"+strconv.Itoa(1_000_000-i), task)
+		}
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] { // ID выдан дважды
+			t.Errorf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	if stored := len(ds.GetAllTasks()); len(seen) < 700 || stored != len(seen) { // задачи потерялись или перезаписались
+		t.Errorf("expected every created task stored once, got %d ids and %d stored", len(seen), stored)
+	}
+}
+
+// Проверка фильтрации списка задач по статусу
+// Сценарий:
+// 1. Создать задачи в статусах not started и in progress.
+// 2. Получить задачи с ?status=in progress - ожидаем только задачу в работе.
+// 3. Получить задачи с неизвестным статусом - ожидаем ошибку (400 Bad Request).
+func TestFilterByStatus(t *testing.T) {
+	ts := startTestServer()
+
+	// Создаём задачи
+	for _, task := range []Task{
+		{Title: "Todo", Status: StatusNotStarted},
+		{Title: "Doing", Status: StatusInProgress},
+	} {
+		body, _ := json.Marshal(task)
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Фильтруем по статусу
+	resp, err := http.Get(ts.URL + "/todos?status=in%20progress")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var tasks []Task
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Doing" { // фильтр НЕ сработал
+		t.Errorf("unexpected filtered tasks %+v", tasks)
+	}
+	// Фильтруем по неизвестному статусу
+	resp2, err := http.Get(ts.URL + "/todos?status=done")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	// Ожидаем ошибку 400
+	if resp2.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", resp2.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp2.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка серверных отметок времени создания и изменения
+// Сценарий:
+// 1. Создать задачу с подделанным created_at - ожидаем, что сервер выставит свои created_at = updated_at.
+// 2. Обновить задачу - ожидаем, что updated_at сдвинется, а created_at не изменится.
+func TestTaskTimestamps(t *testing.T) {
+	ts := startTestServer()
+
+	forged := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	body, _ := json.Marshal(Task{Title: "Timed", Status: StatusNotStarted, CreatedAt: forged, UpdatedAt: forged})
+	// Создаём задачу
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	var created Task
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.CreatedAt.Equal(forged) || !created.CreatedAt.Equal(created.UpdatedAt) { // время НЕ назначено сервером
+		t.Errorf("unexpected timestamps %v / %v", created.CreatedAt, created.UpdatedAt)
+	}
+	// Обновляем задачу
+	body, _ = json.Marshal(Task{Title: "Timed", Status: StatusInProgress, CreatedAt: forged})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	var updated Task
+	if err := json.NewDecoder(resp2.Body).Decode(&updated); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) || !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Errorf("unexpected timestamps after update %v / %v", updated.CreatedAt, updated.UpdatedAt)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if err := resp2.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка выбора адреса для прослушивания
+// Сценарий:
+// 1. Без флага и переменных окружения - ожидаем :8080.
+// 2. С PORT - ожидаем :PORT, с ADDR - ожидаем ADDR.
+// 3. С флагом - ожидаем значение флага, даже если заданы переменные окружения.
+func TestListenAddr(t *testing.T) {
+	t.Setenv("ADDR", "")
+	t.Setenv("PORT", "")
+	if got := listenAddr(""); got != ":8080" {
+		t.Errorf("expected default :8080, got %q", got)
+	}
+	t.Setenv("PORT", "9000")
+	if got := listenAddr(""); got != ":9000" {
+		t.Errorf("expected :9000 from PORT, got %q", got)
+	}
+	t.Setenv("ADDR", "127.0.0.1:7000")
+	if got := listenAddr(""); got != "127.0.0.1:7000" {
+		t.Errorf("expected ADDR to win over PORT, got %q", got)
+	}
+	if got := listenAddr(":6000"); got != ":6000" { // флаг НЕ в приоритете
+		t.Errorf("expected flag to win over env, got %q", got)
+	}
+}
+
+// Проверка формата ответов с ошибкой
+// Сценарий:
+// 1. Запросить несуществующую задачу - ожидаем 404 и JSON {"error":...,"status":404}.
+// 2. Отправить неподдерживаемый метод - ожидаем 405 и JSON {"error":...,"status":405}.
+func TestJSONErrors(t *testing.T) {
+	ts := startTestServer()
+
+	for _, c := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/todos/42", http.StatusNotFound},
+		{http.MethodPatch, "/todos", http.StatusMethodNotAllowed},
+	} {
+		req, _ := http.NewRequest(c.method, ts.URL+c.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" { // ошибка НЕ в JSON
+			t.Errorf("expected JSON error, got Content-Type %q", ct)
+		}
+		var body ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode error body: %v", err)
+		}
+		if resp.StatusCode != c.want || body.Status != c.want || body.Error == "" { // статус НЕ совпадает
+			t.Errorf("expected %d, got %d with body %+v", c.want, resp.StatusCode, body)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
+
+// Проверка ответа на пустое тело запроса
+// Сценарий:
+// 1. Создать задачу.
+// 2. Отправить POST /todos и PUT /todos/1 без тела и с телом из одних пробелов - ожидаем ошибку
+// (400 Bad Request) с сообщением "request body is required".
+// 3. Отправить POST /todos с битым JSON - ожидаем ошибку (400 Bad Request) с сообщением "invalid JSON".
+func TestEmptyBody(t *testing.T) {
+	ts := startTestServer()
+
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBufferString(`{"title":"Task"}`))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+
+	for _, c := range []struct {
+		method, path, body, want string
+	}{
+		{http.MethodPost, "/todos", "", "request body is required"},
+		{http.MethodPost, "/todos", " \n\t", "request body is required"},
+		{http.MethodPut, "/todos/1", "", "request body is required"},
+		{http.MethodPut, "/todos/1", " \n", "request body is required"},
+		{http.MethodPost, "/todos", `{"title":`, "invalid JSON"},
+	} {
+		req, _ := http.NewRequest(c.method, ts.URL+c.path, bytes.NewBufferString(c.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		var body ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode error body: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest || body.Error != c.want { // получили НЕ ожидаемую ошибку
+			t.Errorf("%s %s %q: expected 400 %q, got %d %q", c.method, c.path, c.body, c.want, resp.StatusCode, body.Error)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
+
+// Проверка политики переходов между статусами
+// Сценарий:
+// 1. Проверить таблицу переходов: вперёд по одному шагу и in progress -> not started разрешены,
+// пропуск шага и любые переходы из completed запрещены.
+// 2. Попытаться переоткрыть завершённую задачу через PUT - ожидаем ошибку (409 Conflict).
+func TestStatusTransitions(t *testing.T) {
+	for _, c := range []struct {
+		from, to TaskStatus
+		want     bool
+	}{
+		{StatusNotStarted, StatusInProgress, true},
+		{StatusInProgress, StatusCompleted, true},
+		{StatusInProgress, StatusNotStarted, true},
+		{StatusCompleted, StatusCompleted, true},
+		{StatusNotStarted, StatusCompleted, false},
+		{StatusCompleted, StatusInProgress, false},
+		{StatusCompleted, StatusNotStarted, false},
+	} {
+		if got := CanTransition(c.from, c.to); got != c.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	if _, err := ds.AddTask(Task{Title: "Done", Status: StatusCompleted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	// Пытаемся переоткрыть завершённую задачу
+	body, _ := json.Marshal(Task{Title: "Done", Status: StatusInProgress})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	// Ожидаем ошибку 409
+	if resp.StatusCode != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409, got %d", resp.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// statusRank Порядок статусов при сортировке (по жизненному циклу задачи)
+var statusRank = map[TaskStatus]int{StatusNotStarted: 0, StatusInProgress: 1, StatusCompleted: 2}
+
+// taskSortKeys Поддерживаемые ключи сортировки списка задач
+var taskSortKeys = map[string]func(a, b Task) int{
+	"id": func(a, b Task) int {
+		return cmp.Compare(a.ID, b.ID)
+	},
+	"title": func(a, b Task) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	},
+	"status": func(a, b Task) int {
+		return cmp.Compare(statusRank[a.Status], statusRank[b.Status])
+	},
+	"priority": func(a, b Task) int {
+		return cmp.Compare(priorityRank[a.Priority], priorityRank[b.Priority])
+	},
+}
+
+// SortTasks Сортирует задачи по ключу (id, title, status, priority). Задачи с равным ключом упорядочиваются по ID
+func SortTasks(tasks []Task, key string, desc bool) error {
+	compare, ok := taskSortKeys[key]
+	if !ok {
+		return fmt.Errorf("unknown sort key %q", key)
+	}
+	slices.SortFunc(tasks, func(a, b Task) int {
+		c := compare(a, b)
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if desc {
+			return -c
+		}
+		return c
+	})
+	return nil
+}
+
+// parseSort Разбор параметров ?sort= и ?order= (по умолчанию сортировка по ID по возрастанию)
+func parseSort(r *http.Request) (key string, desc bool, err error) {
+	key = r.URL.Query().Get("sort")
+	if key == "" {
+		key = "id"
+	}
+	if _, ok := taskSortKeys[key]; !ok {
+		return "", false, fmt.Errorf("unknown sort key %q", key)
+	}
+	switch order := r.URL.Query().Get("order"); order {
+	case "", "asc":
+		return key, false, nil
+	case "desc":
+		return key, true, nil
+	default:
+		return "", false, fmt.Errorf("unknown sort order %q: must be asc or desc", order)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка сортировки списка задач
+// Сценарий:
+// 1. Создать три задачи.
+// 2. Получить список без параметров - ожидаем порядок по ID.
+// 3. Получить список с ?sort=title&order=desc - ожидаем обратный алфавитный порядок.
+// 4. Получить список с неизвестным ключом сортировки - ожидаем ошибку (400 Bad Request).
+func TestSortTasks(t *testing.T) {
+	ts := startTestServer()
+
+	// Создаём задачи
+	for _, title := range []string{"banana", "Apple", "cherry"} {
+		body, _ := json.Marshal(Task{Title: title, Status: StatusNotStarted})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Проверяем порядок для разных параметров
+	for query, want := range map[string][]string{
+		"":                       {"banana", "Apple", "cherry"},
+		"?sort=title&order=desc": {"cherry", "banana", "Apple"},
+	} {
+		resp, err := http.Get(ts.URL + "/todos" + query)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var tasks []Task
+		if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(tasks) != len(want) {
+			t.Fatalf("expected %d tasks, got %d", len(want), len(tasks))
+		}
+		for i := range want {
+			if tasks[i].Title != want[i] { // порядок НЕ тот
+				t.Errorf("query %q: expected %q at %d, got %q", query, want[i], i, tasks[i].Title)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	// Запрашиваем неизвестный ключ сортировки
+	resp, err := http.Get(ts.URL + "/todos?sort=color")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	// Ожидаем ошибку 400
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
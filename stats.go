@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// statsMethods Методы, для которых ведётся отдельный счётчик (остальные попадают в OTHER)
+var statsMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions, "OTHER",
+}
+
+// Stats Счётчики работы сервера с момента запуска (безопасны для конкурентного доступа без блокировок)
+type Stats struct {
+	requests     atomic.Int64
+	byMethod     map[string]*atomic.Int64 // заполняется в конструкторе и далее только читается
+	clientErrors atomic.Int64             // ответы 4xx
+	serverErrors atomic.Int64             // ответы 5xx
+	tasksCreated atomic.Int64
+	tasksDeleted atomic.Int64
+	tasks        atomic.Int64 // текущее число задач (без удалённых в корзину)
+	metrics      requestMetrics
+	assignees    assigneeRates // созданные задачи по исполнителям за последний час и сутки
+
+	compactions    atomic.Int64
+	lastCompaction atomic.Int64 // время последнего сжатия хранилища (Unix, наносекунды)
+}
+
+// StatsSnapshot Снимок счётчиков для отдачи в /stats
+type StatsSnapshot struct {
+	Requests          int64                   `json:"requests_total"`
+	RequestsByMethod  map[string]int64        `json:"requests_by_method"`
+	ErrorsByClass     map[string]int64        `json:"errors_by_class"`
+	TasksCreated      int64                   `json:"tasks_created"`
+	TasksDeleted      int64                   `json:"tasks_deleted"`
+	CreatedByAssignee map[string]AssigneeRate `json:"created_by_assignee"`
+	Compactions       int64                   `json:"compactions"`
+	LastCompaction    *time.Time              `json:"last_compaction,omitempty"`
+}
+
+// NewStats Создание нового набора счётчиков
+func NewStats() *Stats {
+	s := &Stats{byMethod: make(map[string]*atomic.Int64, len(statsMethods))}
+	s.assignees.limit = defaultMaxTrackedAssignees
+	for _, method := range statsMethods {
+		s.byMethod[method] = new(atomic.Int64)
+	}
+	return s
+}
+
+// countRequest Учёт обработанного запроса
+func (s *Stats) countRequest(method string, status int, duration time.Duration) {
+	s.requests.Add(1)
+	counter, ok := s.byMethod[method]
+	if !ok {
+		method = "OTHER"
+		counter = s.byMethod[method]
+	}
+	counter.Add(1)
+	s.metrics.observe(method, status, duration)
+	switch {
+	case status >= 500:
+		s.serverErrors.Add(1)
+	case status >= 400:
+		s.clientErrors.Add(1)
+	}
+}
+
+// TaskCreated Учёт созданной задачи и её исполнителя (безопасно вызывать на nil)
+func (s *Stats) TaskCreated(assignee string) {
+	if s != nil {
+		s.tasksCreated.Add(1)
+		s.tasks.Add(1)
+		s.assignees.record(assignee, time.Now())
+	}
+}
+
+// TaskDeleted Учёт удалённой задачи (безопасно вызывать на nil)
+func (s *Stats) TaskDeleted() {
+	if s != nil {
+		s.tasksDeleted.Add(1)
+		s.tasks.Add(-1)
+	}
+}
+
+// TaskRestored Учёт восстановленной из корзины задачи (безопасно вызывать на nil)
+func (s *Stats) TaskRestored() {
+	if s != nil {
+		s.tasks.Add(1)
+	}
+}
+
+// Compacted Учёт сжатия хранилища (безопасно вызывать на nil)
+func (s *Stats) Compacted(at time.Time) {
+	if s != nil {
+		s.compactions.Add(1)
+		s.lastCompaction.Store(at.UnixNano())
+	}
+}
+
+// Snapshot Возвращает текущие значения счётчиков
+func (s *Stats) Snapshot() StatsSnapshot {
+	snapshot := StatsSnapshot{
+		Requests:         s.requests.Load(),
+		RequestsByMethod: make(map[string]int64, len(s.byMethod)),
+		ErrorsByClass: map[string]int64{
+			"4xx": s.clientErrors.Load(),
+			"5xx": s.serverErrors.Load(),
+		},
+		TasksCreated:      s.tasksCreated.Load(),
+		TasksDeleted:      s.tasksDeleted.Load(),
+		CreatedByAssignee: s.assignees.snapshot(time.Now()),
+		Compactions:       s.compactions.Load(),
+	}
+	if last := s.lastCompaction.Load(); last != 0 {
+		at := time.Unix(0, last).UTC()
+		snapshot.LastCompaction = &at
+	}
+	for method, counter := range s.byMethod {
+		snapshot.RequestsByMethod[method] = counter.Load()
+	}
+	return snapshot
+}
+
+// statsMiddleware Middleware для подсчёта запросов по методам, статусам и длительности
+func statsMiddleware(s *Stats, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.countRequest(r.Method, rec.status, time.Since(start))
+	})
+}
+
+// statsHandler Обработчик эндпоинта /stats
+func statsHandler(s *Stats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[statsHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := json.NewEncoder(w).Encode(s.Snapshot()); err != nil {
+			logRequest(r, slog.LevelError, "[statsHandler] Encoding stats", err)
+			return
+		}
+	}
+}
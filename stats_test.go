@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Проверка счётчиков /stats
+// Сценарий:
+// 1. Создать задачу, удалить её и запросить несуществующую - получаем 201, 204 и 404.
+// 2. Запросить /stats - ожидаем 1 созданную и 1 удалённую задачу, 1 ошибку 4xx и учтённые методы.
+func TestStats(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{ID: 1, Title: "Counted", Status: StatusNotStarted})
+	// Создаём и удаляем задачу
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/todos/1", nil)
+	resp2, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make DELETE: %v", err)
+	}
+	// Запрашиваем несуществующую задачу
+	resp3, err := http.Get(ts.URL + "/todos/1")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	// Получаем статистику
+	resp4, err := http.Get(ts.URL + "/stats")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var got StatsSnapshot
+	if err := json.NewDecoder(resp4.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	// Проверяем счётчики (сам запрос /stats учитывается после ответа)
+	if got.TasksCreated != 1 || got.TasksDeleted != 1 { // счётчики задач НЕ корректны
+		t.Errorf("unexpected task counters %+v", got)
+	}
+	if got.Requests != 3 || got.RequestsByMethod["POST"] != 1 || got.RequestsByMethod["DELETE"] != 1 {
+		t.Errorf("unexpected request counters %+v", got)
+	}
+	if got.ErrorsByClass["4xx"] != 1 || got.ErrorsByClass["5xx"] != 0 { // ошибки посчитаны НЕ корректно
+		t.Errorf("unexpected error counters %+v", got.ErrorsByClass)
+	}
+	for _, r := range []*http.Response{resp, resp2, resp3, resp4} {
+		if err := r.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
+
+// Проверка статистики создания задач по исполнителям
+// Сценарий:
+// 1. Учесть задачи Alice: две сейчас, одну 2 часа назад и одну 2 суток назад - ожидаем 2 за час и 3 за сутки.
+// 2. Учесть задачу Bob 30 минут назад - ожидаем 1 за час и 1 за сутки.
+// 3. При лимите в 2 исполнителя учесть задачу Carol - ожидаем вытеснение Bob, который дольше не получал задач.
+// 4. Создать задачу с исполнителем через API - ожидаем её в created_by_assignee ответа /stats.
+func TestAssigneeRates(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	rates := assigneeRates{limit: 2}
+	for _, at := range []time.Time{now.Add(-48 * time.Hour), now.Add(-2 * time.Hour), now.Add(-time.Minute), now} {
+		rates.record("Alice", at)
+	}
+	rates.record("Bob", now.Add(-30*time.Minute))
+	want := map[string]AssigneeRate{"Alice": {LastHour: 2, LastDay: 3}, "Bob": {LastHour: 1, LastDay: 1}}
+	if got := rates.snapshot(now); !reflect.DeepEqual(got, want) { // неверные окна
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	rates.record("Carol", now)
+	if got := rates.snapshot(now); len(got) != 2 || got["Bob"] != (AssigneeRate{}) || got["Carol"].LastHour != 1 { // Bob НЕ вытеснен
+		t.Fatalf("expected Bob to be evicted, got %v", got)
+	}
+
+	ts := startTestServer()
+	defer ts.Close()
+	body, _ := json.Marshal(Task{Title: "Task", Status: StatusNotStarted, Assignee: "Dave"})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	resp, err = http.Get(ts.URL + "/stats")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var got StatsSnapshot
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if got.CreatedByAssignee["Dave"] != (AssigneeRate{LastHour: 1, LastDay: 1}) { // задача НЕ учтена
+		t.Errorf("expected Dave to have 1 task created, got %v", got.CreatedByAssignee)
+	}
+}
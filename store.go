@@ -0,0 +1,43 @@
+package main
+
+import "net/http"
+
+// Store Хранилище задач, с которым работают обработчики /todos и /todos/{id}.
+// Реализация по умолчанию - *TaskStore; интерфейс позволяет подключать другие бэкенды и подменять хранилище в тестах
+type Store interface {
+	CreateTask(task Task) error                              // Создание задачи с заданным ID
+	AddTask(task Task) (Task, error)                         // Создание задачи с ID, назначенным хранилищем
+	AddTasks(tasks []Task) ([]Task, error)                   // Атомарное создание пакета задач
+	PreviewAddTask(task Task) (Task, error)                  // Задача, которую создал бы AddTask, без сохранения
+	GetTask(id int) (Task, error)                            // Задача по ID
+	GetTasks(ids []int) (tasks []Task, missing []int)        // Задачи по списку ID и ID, которых нет
+	GetAllTasks() []Task                                     // Все задачи, кроме удалённых
+	GetAllTasksIncludingDeleted() []Task                     // Все задачи вместе с удалёнными
+	FindByExternalID(externalID string) []Task               // Задачи с заданным внешним идентификатором
+	FilterByStatus(status TaskStatus) []Task                 // Задачи в заданном статусе
+	Search(query string) []Task                              // Задачи, содержащие подстроку в заголовке или описании
+	UpdateTask(id int, updated Task) (Task, error)           // Обновление задачи
+	UpsertTask(id int, task Task) (Task, bool, error)        // Создание задачи с заданным ID или её обновление
+	PreviewUpsertTask(id int, task Task) (Task, bool, error) // Результат UpsertTask без сохранения
+	DeleteTask(id int, version int) error                    // Удаление задачи
+	DeleteTaskTree(id int, version int) error                // Удаление задачи вместе с подзадачами
+	DeleteTasks(ids []int) (int, error)                      // Атомарное удаление задач по списку ID
+	DeleteMatching(match func(Task) bool) (int, error)       // Атомарное удаление задач, подходящих под условие
+}
+
+var _ Store = (*TaskStore)(nil)
+
+// idempotencyCache Кэш ключей идемпотентности хранилища (nil, если хранилище его не поддерживает)
+func idempotencyCache(s Store) *IdempotencyCache {
+	if ts, ok := s.(*TaskStore); ok {
+		return ts.idempotency
+	}
+	return nil
+}
+
+// storeHandler Адаптер обработчика, работающего с интерфейсом Store, к таблице маршрутов задач
+func storeHandler(handler func(s Store) http.HandlerFunc) func(ts *TaskStore) http.HandlerFunc {
+	return func(ts *TaskStore) http.HandlerFunc {
+		return handler(ts)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeStore Подменное хранилище: реализует только GetTask и DeleteTask, остальные методы не вызываются
+type fakeStore struct {
+	Store
+	task    Task
+	deleted []int
+}
+
+// GetTask Возвращает заранее заданную задачу
+func (f *fakeStore) GetTask(id int) (Task, error) {
+	if id != f.task.ID {
+		return Task{}, fmt.Errorf("task with id %d not found", id)
+	}
+	return f.task, nil
+}
+
+// DeleteTask Запоминает ID удалённой задачи
+func (f *fakeStore) DeleteTask(id int, _ int) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+// Проверка работы обработчика с подменным хранилищем через интерфейс Store
+// Сценарий:
+// 1. Выполнить GET /todos/7 - ожидаем задачу из подменного хранилища (200 OK).
+// 2. Выполнить GET /todos/8 - ожидаем 404 Not Found.
+// 3. Выполнить DELETE /todos/7 - ожидаем 204 No Content и вызов DeleteTask у хранилища.
+func TestHandlerWithFakeStore(t *testing.T) {
+	store := &fakeStore{task: Task{ID: 7, Title: "Fake", Status: StatusCompleted, Version: 3}}
+	handler := todoHandler(store)
+	serve := func(method, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/todos/"+id, nil)
+		req.SetPathValue("id", id)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	// Получение существующей задачи
+	rec := serve(http.MethodGet, "7")
+	if rec.Code != http.StatusOK { // получили НЕ 200
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var task Task
+	if err := json.NewDecoder(rec.Body).Decode(&task); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if task.Title != "Fake" || rec.Header().Get("ETag") != `"3"` { // задача НЕ из подменного хранилища
+		t.Errorf("expected fake task with ETag \"3\", got %+v (ETag %q)", task, rec.Header().Get("ETag"))
+	}
+	// Получение несуществующей задачи
+	if rec := serve(http.MethodGet, "8"); rec.Code != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404, got %d", rec.Code)
+	}
+	// Удаление
+	if rec := serve(http.MethodDelete, "7"); rec.Code != http.StatusNoContent { // получили НЕ 204
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != 7 { // хранилище НЕ получило запрос на удаление
+		t.Errorf("expected DeleteTask(7), got %v", store.deleted)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidParent Ошибка ссылки на недопустимую родительскую задачу
+var ErrInvalidParent = errors.New("invalid parent task")
+
+// ErrHasSubtasks Ошибка удаления задачи, у которой есть подзадачи, без каскадного удаления
+var ErrHasSubtasks = errors.New("task has subtasks")
+
+// ErrIncompleteSubtasks Ошибка завершения задачи с незавершёнными подзадачами
+var ErrIncompleteSubtasks = errors.New("task has incomplete subtasks")
+
+// ErrInferredStatus Ошибка ручной смены статуса задачи, статус которой выводится из подзадач
+var ErrInferredStatus = errors.New("status is inferred from subtasks")
+
+// checkParent Проверяет, что родительская задача существует, не совпадает с самой задачей, не образует цикла
+// и не завершена, если сама задача ещё не завершена (вызывается под блокировкой)
+func (ds *TaskStore) checkParent(parentID *int, selfID int, status TaskStatus) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == selfID {
+		return fmt.Errorf("%w: task cannot be its own parent", ErrInvalidParent)
+	}
+	parent, ok := ds.liveTask(*parentID)
+	if !ok {
+		return fmt.Errorf("%w: task with id %d not found", ErrInvalidParent, *parentID)
+	}
+	// при выводе статуса незавершённая подзадача переоткрывает родителя, а не отклоняется
+	if parent.Status == StatusCompleted && status != StatusCompleted && !ds.config.InferParentStatus {
+		return fmt.Errorf("%w: task %d is completed", ErrInvalidParent, *parentID)
+	}
+	if err := ds.checkReopenedAncestors(parentID, status); err != nil { // переоткрытым предкам не хватит места в лимите
+		return err
+	}
+	// поднимаемся по предкам родителя; шагов не больше, чем задач, даже если в данных уже есть цикл
+	for ancestor, steps := parent.ParentID, 0; ancestor != nil && steps < len(ds.tasks); steps++ {
+		if *ancestor == selfID {
+			return fmt.Errorf("%w: task %d is a subtask of %d", ErrInvalidParent, *parentID, selfID)
+		}
+		next, ok := ds.tasks[*ancestor]
+		if !ok {
+			break
+		}
+		ancestor = next.ParentID
+	}
+	return nil
+}
+
+// indexParent Добавляет задачу в индекс подзадач по родителю (вызывается под блокировкой)
+func (ds *TaskStore) indexParent(task Task) {
+	if task.ParentID == nil {
+		return
+	}
+	ids, ok := ds.byParent[*task.ParentID]
+	if !ok {
+		ids = make(map[int]struct{})
+		ds.byParent[*task.ParentID] = ids
+	}
+	ids[task.ID] = struct{}{}
+}
+
+// unindexParent Удаляет задачу из индекса подзадач по родителю (вызывается под блокировкой)
+func (ds *TaskStore) unindexParent(task Task) {
+	if task.ParentID == nil {
+		return
+	}
+	ids, ok := ds.byParent[*task.ParentID]
+	if !ok {
+		return
+	}
+	delete(ids, task.ID)
+	if len(ids) == 0 {
+		delete(ds.byParent, *task.ParentID)
+	}
+}
+
+// hasSubtasks Ссылается ли на задачу хоть одна подзадача, в том числе удалённая в корзину (вызывается под блокировкой)
+func (ds *TaskStore) hasSubtasks(id int) bool {
+	return len(ds.byParent[id]) > 0
+}
+
+// children Неудалённые подзадачи задачи, отсортированные по ID (вызывается под блокировкой)
+func (ds *TaskStore) children(id int) []Task {
+	var list []Task
+	for childID := range ds.byParent[id] {
+		if task, ok := ds.liveTask(childID); ok {
+			list = append(list, task)
+		}
+	}
+	slices.SortFunc(list, func(a, b Task) int { return cmp.Compare(a.ID, b.ID) })
+	return list
+}
+
+// checkCompletable Проверяет, что у задачи нет незавершённых подзадач (вызывается под блокировкой)
+func (ds *TaskStore) checkCompletable(id int) error {
+	for _, child := range ds.children(id) {
+		if child.Status != StatusCompleted {
+			return fmt.Errorf("%w: subtask %d is %s", ErrIncompleteSubtasks, child.ID, child.Status)
+		}
+	}
+	return nil
+}
+
+// inferStatus Статус задачи по статусам её подзадач: не начата, если не начаты все,
+// завершена, если завершены все, иначе в работе
+func inferStatus(children []Task) TaskStatus {
+	counts := make(map[TaskStatus]int)
+	for _, child := range children {
+		counts[child.Status]++
+	}
+	switch len(children) {
+	case counts[StatusNotStarted]:
+		return StatusNotStarted
+	case counts[StatusCompleted]:
+		return StatusCompleted
+	}
+	return StatusInProgress
+}
+
+// inferStatuses Пересчитывает выводимые статусы после изменения задачи: её прежнего и нового родителя
+// и её самой, если у неё есть подзадачи (вызывается из record под блокировкой).
+// Изменение родителя тоже проходит через record, поэтому пересчёт поднимается до корня дерева
+func (ds *TaskStore) inferStatuses(before, after *Task) {
+	if !ds.config.InferParentStatus {
+		return
+	}
+	for _, task := range []*Task{before, after} {
+		if task != nil && task.ParentID != nil {
+			ds.syncInferredStatus(*task.ParentID)
+		}
+	}
+	if after != nil { // например, отменённое изменение или восстановление задачи с подзадачами
+		ds.syncInferredStatus(after.ID)
+	}
+}
+
+// syncInferredStatus Выставляет задаче с подзадачами статус, выведенный из них (вызывается под блокировкой).
+// Переход выполняется в обход CanTransition: статус родителя следует за подзадачами
+func (ds *TaskStore) syncInferredStatus(id int) {
+	task, ok := ds.liveTask(id)
+	if !ok {
+		return
+	}
+	children := ds.children(id)
+	if len(children) == 0 { // без подзадач статус задаётся вручную
+		return
+	}
+	status := inferStatus(children)
+	if task.Status == status {
+		return
+	}
+	before := task
+	now := time.Now().UTC()
+	task.statusSince = now
+	task.UpdatedAt = now
+	task.Version++
+	task.Status = status
+	trackCompletion(before.Status, &task, now)
+	ds.coupleProgress(before.Status, &task)
+	ds.unindexAssignee(before)
+	ds.tasks[id] = task
+	ds.indexAssignee(task)
+	slog.Info("[syncInferredStatus] Inferred status from subtasks", "task_id", id, "from", before.Status, "to", status)
+	ds.record(TaskEventUpdated, &before, &task)
+}
+
+// Subtasks Возвращает подзадачи задачи по её ID
+func (ds *TaskStore) Subtasks(id int) ([]Task, error) {
+	ds.mutex.RLock()
+	defer ds.mutex.RUnlock()
+	if _, ok := ds.liveTask(id); !ok {
+		return nil, fmt.Errorf("task with id %d not found", id)
+	}
+	list := ds.children(id)
+	ds.numberTasks(list)
+	return list, nil
+}
+
+// DeleteTaskTree Удаляет задачу в корзину вместе со всеми её подзадачами
+// (version, если не 0, - ожидаемая версия самой задачи)
+func (ds *TaskStore) DeleteTaskTree(id int, version int) error {
+	ds.mutex.Lock()
+	task, ok := ds.liveTask(id)
+	if !ok { // задача с таким ID не найдена
+		ds.mutex.Unlock()
+		err := fmt.Errorf("task with id %d not found", id)
+		slog.Warn("[DeleteTaskTree] Task not found", "task_id", id, "error", err)
+		return err
+	}
+	if err := checkVersion(task, version); err != nil { // задачу успели изменить
+		ds.mutex.Unlock()
+		slog.Warn("[DeleteTaskTree] Rejecting delete", "task_id", id, "error", err)
+		return err
+	}
+	tree := []Task{task}
+	for i := 0; i < len(tree); i++ { // обход в ширину по подзадачам
+		tree = append(tree, ds.children(tree[i].ID)...)
+	}
+	now := time.Now().UTC()
+	for _, task := range tree {
+		ds.softDelete(task, now)
+	}
+	ds.mutex.Unlock()
+	for range tree {
+		ds.config.Stats.TaskDeleted()
+	}
+	return nil
+}
+
+// subtasksHandler Обработчик эндпоинта /todos/{id}/subtasks
+func subtasksHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			logRequest(r, slog.LevelWarn, "[subtasksHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[subtasksHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		tasks, err := ts.Subtasks(id)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[subtasksHandler] Listing subtasks", err, "task_id", id)
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if tasks == nil {
+			tasks = []Task{}
+		}
+		if err := writeTaskJSON(w, r, http.StatusOK, tasks); err != nil {
+			logRequest(r, slog.LevelError, "[subtasksHandler] Encoding tasks", err, "task_id", id)
+			return
+		}
+	}
+}
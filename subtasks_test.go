@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка подзадач
+// Сценарий:
+// 1. Создать задачу 1 и её подзадачу 2 - ожидаем, что GET /todos/1/subtasks вернёт задачу 2.
+// 2. Создать подзадачу несуществующей задачи - ожидаем 400 Bad Request.
+// 3. Сделать задачу 1 подзадачей задачи 2 - ожидаем 400 (цикл).
+// 4. Завершить задачу 1 при незавершённой подзадаче - ожидаем 409 Conflict.
+// 5. Удалить задачу 1 без cascade - ожидаем 409, с cascade=true - ожидаем 204 и удалённую подзадачу.
+func TestSubtasks(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	do := func(method, path string, task *Task) int {
+		var body bytes.Buffer
+		if task != nil {
+			if err := json.NewEncoder(&body).Encode(task); err != nil {
+				t.Fatalf("failed to encode task: %v", err)
+			}
+		}
+		req, err := http.NewRequest(method, ts.URL+path, &body)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode
+	}
+	parentID, missingID, childID := 1, 99, 2
+	if status := do(http.MethodPost, "/todos", &Task{Title: "Parent", Status: StatusInProgress}); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if status := do(http.MethodPost, "/todos", &Task{Title: "Child", Status: StatusInProgress, ParentID: &parentID}); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	// Список подзадач
+	resp, err := http.Get(ts.URL + "/todos/1/subtasks")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var subtasks []Task
+	if err := json.NewDecoder(resp.Body).Decode(&subtasks); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if len(subtasks) != 1 || subtasks[0].ID != 2 { // подзадача НЕ найдена
+		t.Errorf("expected subtask 2, got %+v", subtasks)
+	}
+	// Несуществующий родитель
+	if status := do(http.MethodPost, "/todos", &Task{Title: "Orphan", Status: StatusNotStarted, ParentID: &missingID}); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for missing parent, got %d", status)
+	}
+	// Цикл
+	if status := do(http.MethodPut, "/todos/1", &Task{Title: "Parent", Status: StatusInProgress, ParentID: &childID}); status != http.StatusBadRequest {
+		t.Errorf("expected 400 for cycle, got %d", status)
+	}
+	// Завершение родителя с незавершённой подзадачей
+	if status := do(http.MethodPut, "/todos/1", &Task{Title: "Parent", Status: StatusCompleted}); status != http.StatusConflict {
+		t.Errorf("expected 409 for incomplete subtasks, got %d", status)
+	}
+	// Удаление родителя
+	if status := do(http.MethodDelete, "/todos/1", nil); status != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409 without cascade, got %d", status)
+	}
+	if status := do(http.MethodDelete, "/todos/1?cascade=true", nil); status != http.StatusNoContent { // получили НЕ 204
+		t.Errorf("expected 204 with cascade, got %d", status)
+	}
+	if status := do(http.MethodGet, "/todos/2", nil); status != http.StatusNotFound { // подзадача НЕ удалена
+		t.Errorf("expected subtask to be deleted, got %d", status)
+	}
+}
+
+// Проверка вывода статуса родителя из подзадач
+// Сценарий:
+// 1. Включить вывод статуса, создать задачу 1 и её подзадачи 2 и 3 (не начаты).
+// 2. Взять подзадачу 2 в работу - ожидаем родителя в работе.
+// 3. Завершить обе подзадачи - ожидаем завершённого родителя с прогрессом 100.
+// 4. Сменить статус родителя вручную - ожидаем 409 Conflict.
+// 5. Добавить незавершённую подзадачу 4 - ожидаем, что родитель переоткрыт, после её удаления - снова завершён.
+// 6. Сделать задачу 1 подзадачей новой задачи 5 - ожидаем, что статус 5 тоже выведен (завершена).
+func TestInferParentStatus(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.InferParentStatus = true
+	ds := NewTaskStoreWithConfig(config)
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	parentID := 1
+	add := func(title string, parent *int) Task {
+		task, err := ds.AddTask(Task{Title: title, Status: StatusNotStarted, ParentID: parent})
+		if err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+		return task
+	}
+	update := func(id int, change func(*Task)) error {
+		task, err := ds.GetTask(id)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+		change(&task)
+		_, err = ds.UpdateTask(id, task)
+		return err
+	}
+	setStatus := func(id int, status TaskStatus) {
+		if err := update(id, func(task *Task) { task.Status = status }); err != nil {
+			t.Fatalf("failed to set status of task %d: %v", id, err)
+		}
+	}
+	expectStatus := func(id int, want TaskStatus) Task {
+		t.Helper()
+		task, err := ds.GetTask(id)
+		if err != nil {
+			t.Fatalf("failed to get task: %v", err)
+		}
+		if task.Status != want { // статус НЕ выведен из подзадач
+			t.Fatalf("task %d: expected status %q, got %q", id, want, task.Status)
+		}
+		return task
+	}
+
+	add("Parent", nil)
+	add("Child 2", &parentID)
+	add("Child 3", &parentID)
+	expectStatus(1, StatusNotStarted)
+	setStatus(2, StatusInProgress)
+	expectStatus(1, StatusInProgress)
+	setStatus(2, StatusCompleted)
+	setStatus(3, StatusInProgress)
+	setStatus(3, StatusCompleted)
+	if parent := expectStatus(1, StatusCompleted); parent.Progress != 100 || parent.CompletedAt == nil { // завершение НЕ учтено
+		t.Errorf("expected inferred completion to set progress and completed_at, got %+v", parent)
+	}
+
+	body, _ := json.Marshal(Task{Title: "Parent", Status: StatusInProgress})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/todos/1", bytes.NewBuffer(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to make PUT: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusConflict { // получили НЕ 409
+		t.Fatalf("manual status change: expected 409, got %d", resp.StatusCode)
+	}
+
+	add("Child 4", &parentID)
+	expectStatus(1, StatusInProgress)
+	if err := ds.DeleteTask(4, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	expectStatus(1, StatusCompleted)
+
+	grandparentID := add("Grandparent", nil).ID
+	if err := update(1, func(task *Task) { task.ParentID = &grandparentID }); err != nil {
+		t.Fatalf("failed to move task: %v", err)
+	}
+	expectStatus(grandparentID, StatusCompleted)
+}
+
+// Проверка индекса подзадач по родителю
+// Сценарий:
+// 1. Создать задачи 1 и 2 и подзадачу 3 задачи 1.
+// 2. Перенести подзадачу 3 к задаче 2 - ожидаем, что она числится только у задачи 2.
+// 3. Удалить подзадачу 3 в корзину - ожидаем, что в списке подзадач её нет, но в индексе она остаётся.
+func TestSubtaskIndex(t *testing.T) {
+	ds := NewTaskStore()
+	first, second := 1, 2
+	for _, task := range []Task{
+		{Title: "First", Status: StatusNotStarted},
+		{Title: "Second", Status: StatusNotStarted},
+		{Title: "Child", Status: StatusNotStarted, ParentID: &first},
+	} {
+		if _, err := ds.AddTask(task); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	if _, err := ds.UpdateTask(3, Task{Title: "Child", Status: StatusNotStarted, ParentID: &second}); err != nil {
+		t.Fatalf("failed to move subtask: %v", err)
+	}
+	if list, _ := ds.Subtasks(1); len(list) != 0 { // подзадача осталась у прежнего родителя
+		t.Errorf("expected no subtasks of task 1, got %+v", list)
+	}
+	if _, ok := ds.byParent[1]; ok { // пустая запись индекса не удалена
+		t.Error("expected index entry of task 1 to be removed")
+	}
+	if list, _ := ds.Subtasks(2); len(list) != 1 || list[0].ID != 3 { // подзадача НЕ перенесена
+		t.Errorf("expected subtask 3 of task 2, got %+v", list)
+	}
+	if err := ds.DeleteTask(3, 0); err != nil {
+		t.Fatalf("failed to delete subtask: %v", err)
+	}
+	if list, _ := ds.Subtasks(2); len(list) != 0 { // удалённая подзадача в списке
+		t.Errorf("expected no live subtasks of task 2, got %+v", list)
+	}
+	if _, ok := ds.byParent[2][3]; !ok { // задача в корзине по-прежнему ссылается на родителя
+		t.Error("expected trashed subtask to stay indexed")
+	}
+}
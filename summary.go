@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// SummaryByAssignee Возвращает число задач в каждом статусе по исполнителям за один проход по хранилищу
+// (удалённые в корзину не учитываются, задачи без исполнителя - под ключом "").
+// При only = true учитывается только исполнитель assignee
+func (ds *TaskStore) SummaryByAssignee(assignee string, only bool) map[string]map[TaskStatus]int {
+	summary := make(map[string]map[TaskStatus]int)
+	if only { // исполнитель без задач - с нулевыми счётчиками
+		summary[assignee] = map[TaskStatus]int{StatusNotStarted: 0, StatusInProgress: 0, StatusCompleted: 0}
+	}
+	ds.mutex.RLock()
+	for _, t := range ds.tasks {
+		if t.DeletedAt != nil || (only && t.Assignee != assignee) {
+			continue
+		}
+		counts, ok := summary[t.Assignee]
+		if !ok {
+			counts = map[TaskStatus]int{StatusNotStarted: 0, StatusInProgress: 0, StatusCompleted: 0}
+			summary[t.Assignee] = counts
+		}
+		counts[t.Status]++
+	}
+	ds.mutex.RUnlock()
+	return summary
+}
+
+// summaryHandler Обработчик эндпоинта /todos/summary: число задач в каждом статусе по исполнителям,
+// с ?assignee= или ?unassigned=true - только для одного исполнителя
+func summaryHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[summaryHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		assignee, only, err := parseAssigneeFilter(r)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[summaryHandler] Invalid assignee filter", err)
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(ts.SummaryByAssignee(assignee, only)); err != nil {
+			logRequest(r, slog.LevelError, "[summaryHandler] Encoding summary", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// Проверка сводки по исполнителям
+// Сценарий:
+// 1. Создать задачи Alice в разных статусах, задачу Bob, задачу без исполнителя и удалить задачу Bob в корзину.
+// 2. Запросить GET /todos/summary - ожидаем счётчики Alice и задачи без исполнителя, без Bob.
+// 3. Запросить GET /todos/summary?assignee=Bob - ожидаем нулевые счётчики Bob.
+// 4. Запросить GET /todos/summary?assignee=Bob&unassigned=true - ожидаем 400 Bad Request.
+func TestSummaryByAssignee(t *testing.T) {
+	ds := NewTaskStore()
+	for _, task := range []Task{
+		{Title: "A1", Status: StatusNotStarted, Assignee: "Alice"},
+		{Title: "A2", Status: StatusInProgress, Assignee: "Alice"},
+		{Title: "A3", Status: StatusInProgress, Assignee: "Alice"},
+		{Title: "B1", Status: StatusCompleted, Assignee: "Bob"},
+		{Title: "N1", Status: StatusCompleted},
+	} {
+		if _, err := ds.AddTask(task); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	if err := ds.DeleteTask(4, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	get := func(path string) (map[string]map[TaskStatus]int, int) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		var summary map[string]map[TaskStatus]int
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return summary, resp.StatusCode
+	}
+
+	want := map[string]map[TaskStatus]int{
+		"Alice": {StatusNotStarted: 1, StatusInProgress: 2, StatusCompleted: 0},
+		"":      {StatusNotStarted: 0, StatusInProgress: 0, StatusCompleted: 1},
+	}
+	if summary, _ := get("/todos/summary"); !reflect.DeepEqual(summary, want) { // неверная сводка
+		t.Errorf("expected %v, got %v", want, summary)
+	}
+	want = map[string]map[TaskStatus]int{"Bob": {StatusNotStarted: 0, StatusInProgress: 0, StatusCompleted: 0}}
+	if summary, _ := get("/todos/summary?assignee=Bob"); !reflect.DeepEqual(summary, want) { // неверная сводка по исполнителю
+		t.Errorf("expected %v, got %v", want, summary)
+	}
+	if _, status := get("/todos/summary?assignee=Bob&unassigned=true"); status != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", status)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// TaskStats Сводные показатели по задачам (удалённые в корзину не учитываются)
+type TaskStats struct {
+	Total            int                  `json:"total"`
+	ByStatus         map[TaskStatus]int   `json:"by_status"`
+	ByPriority       map[TaskPriority]int `json:"by_priority"`
+	Overdue          int                  `json:"overdue"`                     // Незавершённые задачи с истёкшим сроком
+	OldestIncomplete *OldestTask          `json:"oldest_incomplete,omitempty"` // Нет, если все задачи завершены
+	// AvgCompletionSeconds Среднее время от создания до завершения по завершённым задачам (нет, если таких нет)
+	AvgCompletionSeconds *float64 `json:"avg_completion_seconds,omitempty"`
+}
+
+// OldestTask Самая старая незавершённая задача
+type OldestTask struct {
+	ID         int       `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	AgeSeconds int64     `json:"age_seconds"`
+}
+
+// Stats Сводные показатели по задачам за один проход под блокировкой на чтение
+func (ds *TaskStore) Stats() TaskStats {
+	now := time.Now().UTC()
+	overdue := dueFilter{overdue: true, now: now}
+	stats := TaskStats{
+		ByStatus:   map[TaskStatus]int{StatusNotStarted: 0, StatusInProgress: 0, StatusCompleted: 0},
+		ByPriority: map[TaskPriority]int{PriorityLow: 0, PriorityMedium: 0, PriorityHigh: 0},
+	}
+	var oldest *Task
+	var completed int
+	var completion time.Duration
+	ds.mutex.RLock()
+	for _, task := range ds.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		stats.Total++
+		stats.ByStatus[task.Status]++
+		stats.ByPriority[task.Priority]++
+		if overdue.match(task) {
+			stats.Overdue++
+		}
+		if task.Status == StatusCompleted && task.CompletedAt != nil {
+			completed++
+			completion += task.CompletedAt.Sub(task.CreatedAt)
+		}
+		if task.Status != StatusCompleted && (oldest == nil || task.CreatedAt.Before(oldest.CreatedAt) ||
+			task.CreatedAt.Equal(oldest.CreatedAt) && task.ID < oldest.ID) {
+			oldest = &task
+		}
+	}
+	ds.mutex.RUnlock()
+	if completed > 0 {
+		avg := completion.Seconds() / float64(completed)
+		stats.AvgCompletionSeconds = &avg
+	}
+	if oldest != nil {
+		stats.OldestIncomplete = &OldestTask{
+			ID:         oldest.ID,
+			CreatedAt:  oldest.CreatedAt,
+			AgeSeconds: int64(now.Sub(oldest.CreatedAt).Seconds()),
+		}
+	}
+	return stats
+}
+
+// taskStatsHandler Обработчик эндпоинта /todos/stats
+func taskStatsHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logRequest(r, slog.LevelWarn, "[taskStatsHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(ts.Stats()); err != nil {
+			logRequest(r, slog.LevelError, "[taskStatsHandler] Encoding stats", err)
+			return
+		}
+	}
+}
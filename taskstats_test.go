@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Проверка сводных показателей /todos/stats
+// Сценарий:
+// 1. Создать задачи: просроченную high, завершённую low, обычную medium и удалённую в корзину.
+// 2. Получить GET /todos/stats - ожидаем число задач по статусам и приоритетам без удалённой,
+// одну просроченную и самую старую незавершённую задачу с ID 1.
+// 3. Выполнить POST /todos/stats - ожидаем ошибку (405 Method Not Allowed).
+func TestTaskStats(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	// Создаём задачи
+	past := time.Now().Add(-time.Hour)
+	for _, task := range []Task{
+		{Title: "Overdue", Status: StatusInProgress, Priority: PriorityHigh, DueAt: &past},
+		{Title: "Done", Status: StatusCompleted, Priority: PriorityLow, DueAt: &past},
+		{Title: "Regular", Status: StatusNotStarted, Priority: PriorityMedium},
+		{Title: "Deleted", Status: StatusNotStarted, Priority: PriorityHigh},
+	} {
+		if _, err := ds.AddTask(task); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	if err := ds.DeleteTask(4, 0); err != nil {
+		t.Fatalf("failed to delete task: %v", err)
+	}
+	// Получаем сводку
+	resp, err := http.Get(ts.URL + "/todos/stats")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var stats TaskStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if stats.Total != 3 || stats.Overdue != 1 { // общие числа НЕ совпадают
+		t.Errorf("expected total 3 and overdue 1, got %d and %d", stats.Total, stats.Overdue)
+	}
+	for status, want := range map[TaskStatus]int{StatusNotStarted: 1, StatusInProgress: 1, StatusCompleted: 1} {
+		if stats.ByStatus[status] != want {
+			t.Errorf("expected %d tasks in %q, got %d", want, status, stats.ByStatus[status])
+		}
+	}
+	for priority, want := range map[TaskPriority]int{PriorityLow: 1, PriorityMedium: 1, PriorityHigh: 1} {
+		if stats.ByPriority[priority] != want {
+			t.Errorf("expected %d tasks with %q priority, got %d", want, priority, stats.ByPriority[priority])
+		}
+	}
+	if stats.OldestIncomplete == nil || stats.OldestIncomplete.ID != 1 || stats.OldestIncomplete.AgeSeconds < 0 { // самая старая задача НЕ та
+		t.Errorf("expected oldest incomplete task 1, got %+v", stats.OldestIncomplete)
+	}
+
+	// Неподдерживаемый метод
+	resp, err = http.Post(ts.URL+"/todos/stats", "application/json", nil)
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusMethodNotAllowed { // получили НЕ 405
+		t.Errorf("expected 405, got %d", resp.StatusCode)
+	}
+}
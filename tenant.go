@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// defaultMaxTenants Лимит числа хранилищ тенантов по умолчанию
+const defaultMaxTenants = 1000
+
+// ErrTooManyTenants Ошибка создания хранилища тенанта сверх лимита реестра
+var ErrTooManyTenants = errors.New("too many tenants")
+
+// tenantPattern Допустимый формат идентификатора тенанта (латиница, цифры, '-' и '_', до 64 символов)
+var tenantPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,63}$`)
+
+// TenantRegistry Реестр изолированных хранилищ задач тенантов
+type TenantRegistry struct {
+	mutex  sync.RWMutex // Мьютекс для защиты от гонок данных
+	config StoreConfig  // Настройки, с которыми создаются хранилища тенантов
+	stores map[string]*TaskStore
+	active map[string]int // Число выполняющихся запросов на запись по тенанту
+	empty  *TaskStore     // Пустое хранилище для чтения у тенантов, в которые ещё ничего не записано
+}
+
+// NewTenantRegistry Создание нового реестра тенантов
+func NewTenantRegistry(config StoreConfig) *TenantRegistry {
+	return &TenantRegistry{
+		config: config,
+		stores: make(map[string]*TaskStore),
+		active: make(map[string]int),
+		empty:  NewTaskStoreWithConfig(config),
+	}
+}
+
+// Lookup Возвращает хранилище тенанта, не создавая его
+func (tr *TenantRegistry) Lookup(tenant string) (*TaskStore, bool) {
+	tr.mutex.RLock()
+	ts, ok := tr.stores[tenant]
+	tr.mutex.RUnlock()
+	return ts, ok
+}
+
+// Store Возвращает хранилище тенанта, создавая его при первом обращении, пока не исчерпан лимит тенантов
+func (tr *TenantRegistry) Store(tenant string) (*TaskStore, error) {
+	if ts, ok := tr.Lookup(tenant); ok {
+		return ts, nil
+	}
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	return tr.store(tenant)
+}
+
+// store Возвращает хранилище тенанта, создавая его, пока не исчерпан лимит тенантов (вызывается под блокировкой)
+func (tr *TenantRegistry) store(tenant string) (*TaskStore, error) {
+	if ts, ok := tr.stores[tenant]; ok { // хранилище могли создать, пока мы ждали блокировку
+		return ts, nil
+	}
+	if tr.config.MaxTenants > 0 && len(tr.stores) >= tr.config.MaxTenants {
+		return nil, fmt.Errorf("%w: limit is %d tenants", ErrTooManyTenants, tr.config.MaxTenants)
+	}
+	ts := NewTaskStoreWithConfig(tr.config)
+	ts.tenant = tenant
+	tr.stores[tenant] = ts
+	return ts, nil
+}
+
+// acquire Возвращает хранилище тенанта для запроса на запись, создавая его при необходимости.
+// Пока запрос не вызвал release, хранилище не удаляется
+func (tr *TenantRegistry) acquire(tenant string) (*TaskStore, error) {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	ts, err := tr.store(tenant)
+	if err != nil {
+		return nil, err
+	}
+	tr.active[tenant]++
+	return ts, nil
+}
+
+// release Завершает запрос на запись. Если запросов к тенанту не осталось, а в его хранилище нет ни одной задачи
+// (например, запрос отклонён), хранилище удаляется, чтобы неудачные запросы не занимали лимит тенантов
+func (tr *TenantRegistry) release(tenant string) {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	if tr.active[tenant]--; tr.active[tenant] > 0 {
+		return
+	}
+	delete(tr.active, tenant)
+	if ts, ok := tr.stores[tenant]; ok && ts.isEmpty() {
+		delete(tr.stores, tenant)
+	}
+}
+
+// Stores Возвращает хранилища всех известных тенантов
+func (tr *TenantRegistry) Stores() []*TaskStore {
+	tr.mutex.RLock()
+	stores := make([]*TaskStore, 0, len(tr.stores))
+	for _, ts := range tr.stores {
+		stores = append(stores, ts)
+	}
+	tr.mutex.RUnlock()
+	return stores
+}
+
+// createsTenant Создаёт ли запрос хранилище тенанта: чтение неизвестного тенанта обслуживается пустым хранилищем,
+// а поток событий подписывается на настоящее хранилище, иначе он не увидит последующих изменений.
+// Хранилище, которое запрос оставил пустым, удаляется после его завершения (см. release)
+func createsTenant(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return true
+	}
+	return strings.HasSuffix(r.Pattern, "/todos/events")
+}
+
+// tenantHandler Обёртка над обработчиком задач, подставляющая хранилище тенанта из пути /t/{tenant}/...
+func tenantHandler(tr *TenantRegistry, handler func(ts *TaskStore) http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.PathValue("tenant")
+		if !tenantPattern.MatchString(tenant) {
+			logRequest(r, slog.LevelWarn, "[tenantHandler] Invalid tenant", nil, "tenant", tenant)
+			writeJSONError(w, http.StatusBadRequest, "invalid tenant")
+			return
+		}
+		if !createsTenant(r) {
+			ts, ok := tr.Lookup(tenant)
+			if !ok {
+				ts = tr.empty
+			}
+			handler(ts)(w, r)
+			return
+		}
+		ts, err := tr.acquire(tenant)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[tenantHandler] Rejecting new tenant", err, "tenant", tenant)
+			writeJSONError(w, http.StatusInsufficientStorage, err.Error())
+			return
+		}
+		defer tr.release(tenant)
+		handler(ts)(w, r)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Проверка изоляции задач между тенантами
+// Сценарий:
+// 1. Создать задачу у тенанта acme - ожидаем успех (201 Created).
+// 2. Создать задачу с тем же ID у тенанта globex - ожидаем успех (201 Created), ID не конфликтуют.
+// 3. Получить список задач тенанта acme - ожидаем только его задачу.
+// 4. Получить задачу acme по ID через общий /todos - ожидаем ошибку (404 Not Found).
+func TestTenantIsolation(t *testing.T) {
+	ts := startTestServer()
+
+	body, _ := json.Marshal(Task{ID: 1, Title: "Acme task", Status: StatusNotStarted})
+	// Создаём задачу у первого тенанта
+	resp, err := http.Post(ts.URL+"/t/acme/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Errorf("expected 201, got %d", resp.StatusCode)
+	}
+	// Создаём задачу с тем же ID у второго тенанта
+	body2, _ := json.Marshal(Task{ID: 1, Title: "Globex task", Status: StatusNotStarted})
+	resp2, err := http.Post(ts.URL+"/t/globex/todos", "application/json", bytes.NewBuffer(body2))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if resp2.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Errorf("expected 201 for same id in another tenant, got %d", resp2.StatusCode)
+	}
+	// Получаем список задач первого тенанта
+	resp3, err := http.Get(ts.URL + "/t/acme/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var tasks []Task
+	if err := json.NewDecoder(resp3.Body).Decode(&tasks); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "Acme task" { // задачи других тенантов попали в список
+		t.Errorf("unexpected tenant tasks %+v", tasks)
+	}
+	// Задача тенанта не должна быть видна в общем пространстве
+	resp4, err := http.Get(ts.URL + "/todos/1")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	if resp4.StatusCode != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404 outside tenant, got %d", resp4.StatusCode)
+	}
+	for _, r := range []*http.Response{resp, resp2, resp3, resp4} {
+		if err := r.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	ts.Close()
+}
+
+// Проверка валидации идентификатора тенанта
+// Сценарий:
+// 1. Запросить список задач тенанта с недопустимыми символами - ожидаем ошибку (400 Bad Request).
+func TestTenantValidation(t *testing.T) {
+	ts := startTestServer()
+
+	resp, err := http.Get(ts.URL + "/t/bad.tenant!/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	// Ожидаем ошибку 400
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	ts.Close()
+}
+
+// Проверка лимита тенантов и того, что чтение не создаёт хранилище
+// Сценарий:
+// 1. Запросить список задач неизвестного тенанта - ожидаем пустой список (200 OK), хранилище не создано.
+// 2. Удалить задачу и отправить битое тело в неизвестного тенанта - ожидаем ошибки (404, 400), хранилище не осталось.
+// 3. Создать задачу у тенанта acme при лимите в одного тенанта - ожидаем успех (201 Created).
+// 4. Создать задачу у тенанта globex - ожидаем ошибку (507 Insufficient Storage).
+// 5. Создать ещё одну задачу у acme - ожидаем успех (201 Created), лимит касается только новых тенантов.
+func TestTenantLimit(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.MaxTenants = 1
+	tr := NewTenantRegistry(config)
+	ready := new(atomic.Bool)
+	ready.Store(true)
+	ts := httptest.NewServer(newMux(NewTaskStoreWithConfig(config), tr, NewStats(), ready))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/t/ghost/todos")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	var tasks []Task
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || len(tasks) != 0 { // получили НЕ пустой список
+		t.Errorf("expected 200 with empty list, got %d %+v", resp.StatusCode, tasks)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if _, ok := tr.Lookup("ghost"); ok { // чтение создало хранилище
+		t.Error("expected GET not to create a tenant store")
+	}
+	// Отклонённые запросы на запись не оставляют хранилище
+	for _, step := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodDelete, "/t/junk/todos/5", "", http.StatusNotFound},
+		{http.MethodPost, "/t/junk/todos", "{", http.StatusBadRequest},
+	} {
+		req, _ := http.NewRequest(step.method, ts.URL+step.path, strings.NewReader(step.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", step.method, err)
+		}
+		if resp.StatusCode != step.status { // получили НЕ тот статус
+			t.Errorf("%s %s: expected %d, got %d", step.method, step.path, step.status, resp.StatusCode)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	if _, ok := tr.Lookup("junk"); ok { // неудачная запись заняла место в лимите
+		t.Error("expected rejected writes not to keep a tenant store")
+	}
+
+	for _, step := range []struct {
+		tenant string
+		status int
+	}{
+		{"acme", http.StatusCreated},
+		{"globex", http.StatusInsufficientStorage},
+		{"acme", http.StatusCreated},
+	} {
+		body, _ := json.Marshal(Task{Title: "Task", Status: StatusNotStarted})
+		resp, err := http.Post(ts.URL+"/t/"+step.tenant+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if resp.StatusCode != step.status { // получили НЕ тот статус
+			t.Errorf("tenant %s: expected %d, got %d", step.tenant, step.status, resp.StatusCode)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+	if stores := tr.Stores(); len(stores) != 1 { // создано больше хранилищ, чем позволяет лимит
+		t.Errorf("expected 1 tenant store, got %d", len(stores))
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizeTitle Нормализация заголовка: удаление управляющих символов и символов нулевой ширины,
+// схлопывание пробелов. Строку с некорректным UTF-8 не трогаем, чтобы её отклонила валидация
+func normalizeTitle(title string) string {
+	if !utf8.ValidString(title) {
+		return title
+	}
+	title = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) { // переводы строк и табуляции схлопываются в пробел ниже
+			return r
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) { // управляющие и невидимые символы форматирования
+			return -1
+		}
+		return r
+	}, title)
+	return strings.Join(strings.Fields(title), " ")
+}
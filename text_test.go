@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Проверка нормализации заголовка
+// Сценарий:
+// 1. Заголовки с табуляцией, повторными пробелами, переводом строки и символами нулевой ширины
+// - ожидаем схлопнутые пробелы и удалённые невидимые символы.
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  Купить\tмолоко  ", "Купить молоко"},
+		{"Купить   \n  молоко", "Купить молоко"},
+		{"Куп\u200bить\u0007 молоко\ufeff", "Купить молоко"},
+		{"\u200b", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeTitle(tt.in); got != tt.want { // заголовок НЕ нормализован
+			t.Errorf("normalizeTitle(%q): expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+// Проверка отклонения некорректного UTF-8
+// Сценарий:
+// 1. Отправить POST /todos с некорректными байтами в заголовке - ожидаем 400.
+// 2. Проверить Validate задачи с некорректным UTF-8 в описании (как при импорте CSV) - ожидаем ошибку.
+// 3. Создать задачу с переводами строк в описании и пробелами в заголовке - ожидаем 201,
+// нормализованный заголовок и нетронутое описание.
+func TestInvalidUTF8(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Некорректные байты в теле запроса
+	body := []byte("{\"title\":\"Bad \xff\xfe title\",\"status\":\"not started\"}")
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+
+	// Некорректный UTF-8 в обход JSON
+	task := Task{Title: "Task", Description: "Bad \xff", Status: StatusNotStarted}
+	if err := task.Validate(ValidationConfig{}); err == nil || !strings.Contains(err.Error(), "UTF-8") {
+		t.Errorf("expected UTF-8 validation error, got %v", err)
+	}
+
+	// Нормализация заголовка при создании
+	body, _ = json.Marshal(Task{Title: "  Купить \u200b  молоко ", Description: "строка 1\nстрока 2", Status: StatusNotStarted})
+	resp, err = http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	var created Task
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if created.Title != "Купить молоко" { // заголовок НЕ нормализован
+		t.Errorf("expected normalized title, got %q", created.Title)
+	}
+	if created.Description != "строка 1\nстрока 2" { // описание изменено
+		t.Errorf("expected description to keep newlines, got %q", created.Description)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// ServerTimeouts Таймауты соединений http.Server (0 - без ограничения). Защищают от медленных клиентов (slowloris):
+// без них соединение, по байту присылающее заголовки, держится сколько угодно
+type ServerTimeouts struct {
+	ReadHeader time.Duration // Чтение заголовков запроса
+	Read       time.Duration // Чтение запроса целиком, вместе с телом
+	Write      time.Duration // Запись ответа (от окончания чтения заголовков); поток /todos/events снимает его для себя
+	Idle       time.Duration // Ожидание следующего запроса на keep-alive соединении
+}
+
+// DefaultServerTimeouts Таймауты по умолчанию. Таймаут записи больше -request-timeout,
+// чтобы обработчик успел ответить 503 раньше, чем оборвётся соединение
+func DefaultServerTimeouts() ServerTimeouts {
+	return ServerTimeouts{
+		ReadHeader: 5 * time.Second,
+		Read:       30 * time.Second,
+		Write:      30 * time.Second,
+		Idle:       120 * time.Second,
+	}
+}
+
+// apply Применяет таймауты к серверу
+func (st ServerTimeouts) apply(server *http.Server) {
+	server.ReadHeaderTimeout = st.ReadHeader
+	server.ReadTimeout = st.Read
+	server.WriteTimeout = st.Write
+	server.IdleTimeout = st.Idle
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Проверка таймаутов сервера
+// Сценарий:
+// 1. Запустить сервер с таймаутами в 200мс и начать присылать заголовки запроса, не заканчивая их, -
+// ожидаем, что сервер закроет соединение.
+// 2. Подключиться к /todos/events, подождать дольше таймаутов и создать задачу - ожидаем событие created:
+// поток событий таймаутами не обрывается.
+func TestServerTimeouts(t *testing.T) {
+	store := NewTaskStore()
+	stats := NewStats()
+	ready := new(atomic.Bool)
+	ready.Store(true)
+	mux := timeoutMiddleware(defaultRequestTimeout, newMux(store, NewTenantRegistry(store.config), stats, ready))
+	ts := httptest.NewUnstartedServer(statsMiddleware(stats, gzipMiddleware(mux)))
+	ServerTimeouts{ReadHeader: 200 * time.Millisecond, Read: 200 * time.Millisecond,
+		Write: 200 * time.Millisecond, Idle: 200 * time.Millisecond}.apply(ts.Config)
+	ts.Start()
+	defer ts.Close()
+
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	if _, err := conn.Write([]byte("GET /todos HTTP/1.1\r\nHost: test\r\n")); err != nil {
+		t.Fatalf("failed to write headers: %v", err)
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("failed to set deadline: %v", err)
+	}
+	if _, err := io.ReadAll(conn); err != nil { // сервер НЕ закрыл соединение
+		t.Errorf("expected server to close slow connection, got %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("failed to close connection: %v", err)
+	}
+
+	resp, err := http.Get(ts.URL + "/todos/events")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	body, _ := json.Marshal(Task{Title: "Late", Status: StatusNotStarted})
+	created, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := created.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "event: created" { // поток оборвался по таймауту
+		t.Errorf("expected created event after timeouts, got %q (%v)", line, err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsEnabled Проверка флагов -tls-cert и -tls-key: TLS включается, только если заданы оба
+func tlsEnabled(certFile, keyFile string) (bool, error) {
+	switch {
+	case certFile == "" && keyFile == "":
+		return false, nil
+	case certFile == "":
+		return false, fmt.Errorf("-tls-key is set without -tls-cert")
+	case keyFile == "":
+		return false, fmt.Errorf("-tls-cert is set without -tls-key")
+	}
+	return true, nil
+}
+
+// newTLSConfig Настройки TLS сервера (не ниже TLS 1.2)
+func newTLSConfig() *tls.Config {
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+// Проверка флагов TLS
+// Сценарий:
+// 1. Без флагов - ожидаем обычный HTTP без ошибки.
+// 2. С сертификатом и ключом - ожидаем включённый TLS не ниже версии 1.2.
+// 3. Только с сертификатом или только с ключом - ожидаем ошибку конфигурации.
+func TestTLSFlags(t *testing.T) {
+	if enabled, err := tlsEnabled("", ""); enabled || err != nil {
+		t.Errorf("expected plain HTTP without flags, got enabled=%v err=%v", enabled, err)
+	}
+	if enabled, err := tlsEnabled("cert.pem", "key.pem"); !enabled || err != nil {
+		t.Errorf("expected TLS with both flags, got enabled=%v err=%v", enabled, err)
+	}
+	if newTLSConfig().MinVersion != tls.VersionTLS12 { // допускаются версии ниже 1.2
+		t.Errorf("expected minimum TLS 1.2")
+	}
+	for _, files := range [][2]string{{"cert.pem", ""}, {"", "key.pem"}} {
+		if _, err := tlsEnabled(files[0], files[1]); err == nil { // тихий откат на HTTP
+			t.Errorf("expected error for cert=%q key=%q", files[0], files[1])
+		}
+	}
+}
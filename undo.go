@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// undoDepth Сколько прежних версий задачи хранится для отмены
+const undoDepth = 10
+
+// ErrNothingToUndo Ошибка отмены: у задачи нет сохранённой прежней версии
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// pushUndo Запоминает версию задачи до изменения (вызывается под блокировкой, хранятся последние undoDepth версий)
+func (ds *TaskStore) pushUndo(before Task) {
+	versions := append(ds.undo[before.ID], before)
+	if len(versions) > undoDepth {
+		versions = versions[len(versions)-undoDepth:]
+	}
+	ds.undo[before.ID] = versions
+}
+
+// UndoTask Возвращает задачу к версии до последнего изменения. Отмена сама не запоминается,
+// поэтому повторные вызовы откатывают задачу всё дальше назад. Удаление так не отменяется - для него есть RestoreTask.
+// Прежняя версия проверяется так же, как обновление (checkChange), поэтому, например, завершение задачи отменить нельзя
+func (ds *TaskStore) UndoTask(id int) (Task, error) {
+	ds.mutex.Lock()
+	task, ok := ds.liveTask(id)
+	if !ok { // задача с таким ID не найдена
+		ds.mutex.Unlock()
+		err := fmt.Errorf("task with id %d not found", id)
+		slog.Warn("[UndoTask] Task not found", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	versions := ds.undo[id]
+	if len(versions) == 0 {
+		ds.mutex.Unlock()
+		err := fmt.Errorf("%w: task %d has no previous version", ErrNothingToUndo, id)
+		slog.Warn("[UndoTask] Rejecting undo", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	previous := versions[len(versions)-1]
+	// отмена подчиняется тем же правилам, что и обновление: прежний внешний ID, родитель или место исполнителя
+	// могли занять, а статус задачи с подзадачами при выводе статуса вручную не меняется
+	if err := ds.checkChange(task, previous); err != nil {
+		ds.mutex.Unlock()
+		slog.Warn("[UndoTask] Rejecting undo", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	ds.undo[id] = versions[:len(versions)-1]
+	before := task
+	reverted := previous
+	reverted.Version = task.Version + 1 // версия только растёт, чтобы If-Match и ETag не совпали со старыми
+	reverted.UpdatedAt = time.Now().UTC()
+	reverted.DeletedAt = nil
+	ds.unindexExternalID(before)
+	ds.unindexAssignee(before)
+	ds.unindexParent(before)
+	ds.tasks[id] = reverted
+	ds.indexExternalID(reverted)
+	ds.indexAssignee(reverted)
+	ds.indexParent(reverted)
+	reverted = ds.numberTask(reverted)
+	// record пересчитывает выводимые статусы прежнего и нового родителя
+	ds.record(TaskEventReverted, &before, &reverted)
+	ds.mutex.Unlock()
+	return reverted, nil
+}
+
+// undoHandler Обработчик эндпоинта POST /todos/{id}/undo
+func undoHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			logRequest(r, slog.LevelWarn, "[undoHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[undoHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		task, err := ts.UndoTask(id)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[undoHandler] Undoing task", err, "task_id", id)
+			writeJSONError(w, revertErrorStatus(err), err.Error())
+			return
+		}
+		w.Header().Set("ETag", taskETag(task))
+		if err := writeTaskJSON(w, r, http.StatusOK, task); err != nil {
+			logRequest(r, slog.LevelError, "[undoHandler] Encoding task", err, "task_id", id)
+			return
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// Проверка отмены последнего изменения задачи
+// Сценарий:
+// 1. Создать задачу и отменить изменение - ожидаем ошибку (409 Conflict): отменять нечего.
+// 2. Дважды переименовать задачу и отменить изменение - ожидаем первое новое название и выросшую версию.
+// 3. Отменить ещё раз - ожидаем исходное название, затем снова 409.
+// 4. Отменить изменение у несуществующей задачи - ожидаем ошибку (404 Not Found).
+func TestUndo(t *testing.T) {
+	ds := NewTaskStore()
+	ts := startTestServerWithStore(ds)
+	defer ts.Close()
+
+	undo := func(id int) (*http.Response, Task) {
+		resp, err := http.Post(fmt.Sprintf("%s/todos/%d/undo", ts.URL, id), "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var task Task
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&task); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, task
+	}
+
+	// Отменять нечего
+	if _, err := ds.AddTask(Task{Title: "Original", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	if resp, _ := undo(1); resp.StatusCode != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409 without previous version, got %d", resp.StatusCode)
+	}
+	// Переименовываем и отменяем
+	for _, title := range []string{"First edit", "Second edit"} {
+		if _, err := ds.UpdateTask(1, Task{Title: title, Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to update task: %v", err)
+		}
+	}
+	for i, want := range []string{"First edit", "Original"} {
+		resp, task := undo(1)
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		if task.Title != want || task.Version != 4+i { // задача НЕ откатилась или версия не выросла
+			t.Errorf("expected %q at version %d, got %q at version %d", want, 4+i, task.Title, task.Version)
+		}
+	}
+	if resp, _ := undo(1); resp.StatusCode != http.StatusConflict { // получили НЕ 409
+		t.Errorf("expected 409 after undoing everything, got %d", resp.StatusCode)
+	}
+	// Несуществующая задача
+	if resp, _ := undo(99); resp.StatusCode != http.StatusNotFound { // получили НЕ 404
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// Проверка ограничения глубины отмены
+// Сценарий:
+// 1. Изменить задачу больше undoDepth раз - ожидаем, что отменить можно ровно undoDepth изменений.
+func TestUndoDepth(t *testing.T) {
+	ds := NewTaskStore()
+	if _, err := ds.AddTask(Task{Title: "Task", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	for i := range undoDepth + 2 {
+		if _, err := ds.UpdateTask(1, Task{Title: fmt.Sprintf("Edit %d", i), Status: StatusNotStarted}); err != nil {
+			t.Fatalf("failed to update task: %v", err)
+		}
+	}
+	undone := 0
+	for {
+		if _, err := ds.UndoTask(1); err != nil {
+			break
+		}
+		undone++
+	}
+	if undone != undoDepth { // глубина отмены НЕ ограничена
+		t.Errorf("expected %d undos, got %d", undoDepth, undone)
+	}
+}
+
+// Проверка запрета отмены, нарушающей правила переходов статусов
+// Сценарий:
+// 1. Перевести задачу из in progress в completed.
+// 2. Отменить изменение - ожидаем ErrForbiddenTransition: завершённую задачу нельзя вернуть в работу.
+// 3. Убедиться, что задача осталась завершённой, а прежняя версия не потеряна из стека отмены.
+func TestUndoForbiddenTransition(t *testing.T) {
+	ds := NewTaskStore()
+	if _, err := ds.AddTask(Task{Title: "Task", Status: StatusInProgress}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	if _, err := ds.UpdateTask(1, Task{Title: "Task", Status: StatusCompleted}); err != nil {
+		t.Fatalf("failed to update task: %v", err)
+	}
+	if _, err := ds.UndoTask(1); !errors.Is(err, ErrForbiddenTransition) {
+		t.Errorf("expected ErrForbiddenTransition, got %v", err)
+	}
+	task, err := ds.GetTask(1)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if task.Status != StatusCompleted { // отмена изменила статус
+		t.Errorf("expected task to stay completed, got %q", task.Status)
+	}
+	if len(ds.undo[1]) != 1 { // отклонённая отмена сняла версию со стека
+		t.Errorf("expected the previous version to be kept, got %d versions", len(ds.undo[1]))
+	}
+}
+
+// Проверка отмены изменений при выводе статуса родителя из подзадач
+// Сценарий:
+// 1. Перевести подзадачу 3 родителя 1 в работу и отменить изменение - ожидаем, что родитель снова не начат.
+// 2. Отменить выведенную смену статуса родителя - ожидаем ErrInferredStatus.
+// 3. Перенести подзадачу 3 к родителю 2, добавить родителю 1 неначатую подзадачу и отменить перенос -
+// ожидаем, что родитель 1 снова в работе.
+func TestUndoInferredStatus(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.InferParentStatus = true
+	ds := NewTaskStoreWithConfig(config)
+	first, second := 1, 2
+	add := func(task Task) {
+		if _, err := ds.AddTask(task); err != nil {
+			t.Fatalf("failed to add task: %v", err)
+		}
+	}
+	update := func(id int, task Task) {
+		if _, err := ds.UpdateTask(id, task); err != nil {
+			t.Fatalf("failed to update task %d: %v", id, err)
+		}
+	}
+	undo := func(id int) {
+		if _, err := ds.UndoTask(id); err != nil {
+			t.Fatalf("failed to undo task %d: %v", id, err)
+		}
+	}
+	status := func(id int) TaskStatus {
+		task, err := ds.GetTask(id)
+		if err != nil {
+			t.Fatalf("failed to get task %d: %v", id, err)
+		}
+		return task.Status
+	}
+
+	add(Task{Title: "First", Status: StatusNotStarted})
+	add(Task{Title: "Second", Status: StatusNotStarted})
+	add(Task{Title: "Child", Status: StatusNotStarted, ParentID: &first})
+	// Отмена смены статуса подзадачи пересчитывает родителя
+	update(3, Task{Title: "Child", Status: StatusInProgress, ParentID: &first})
+	undo(3)
+	if got := status(1); got != StatusNotStarted { // родитель НЕ пересчитан
+		t.Errorf("expected parent to be not started after undo, got %q", got)
+	}
+	// Выведенный статус родителя отменой не меняется
+	if _, err := ds.UndoTask(1); !errors.Is(err, ErrInferredStatus) {
+		t.Errorf("expected ErrInferredStatus, got %v", err)
+	}
+	// Отмена переноса подзадачи пересчитывает прежнего родителя
+	update(3, Task{Title: "Child", Status: StatusInProgress, ParentID: &first})
+	update(3, Task{Title: "Child", Status: StatusInProgress, ParentID: &second})
+	add(Task{Title: "Late", Status: StatusNotStarted, ParentID: &first})
+	if got := status(1); got != StatusNotStarted {
+		t.Fatalf("expected parent 1 to follow its only subtask, got %q", got)
+	}
+	undo(3)
+	if got := status(1); got != StatusInProgress { // родитель НЕ пересчитан после возврата подзадачи
+		t.Errorf("expected parent 1 to be in progress after undo, got %q", got)
+	}
+}
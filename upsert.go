@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// UpsertTask Создаёт задачу с заданным ID, если её нет, иначе обновляет её как UpdateTask (created - задача была создана).
+// Задачу из корзины PUT не пересоздаёт: её нужно сначала восстановить через RestoreTask
+func (ds *TaskStore) UpsertTask(id int, task Task) (Task, bool, error) {
+	return ds.upsertTask(id, task, false)
+}
+
+// upsertTask Создание или обновление задачи (при dryRun - только проверки и результат, который получился бы)
+func (ds *TaskStore) upsertTask(id int, task Task, dryRun bool) (Task, bool, error) {
+	task.ID = id
+	ds.mutex.RLock()
+	existing, exists := ds.tasks[id]
+	ds.mutex.RUnlock()
+	if exists && existing.DeletedAt != nil { // ID занят задачей из корзины
+		err := fmt.Errorf("%w: task %d is in the trash, restore it first", ErrTaskExists, id)
+		slog.Warn("[UpsertTask] Rejecting task", "task_id", id, "error", err)
+		return Task{}, false, err
+	}
+	if !exists {
+		if task.Version != 0 { // If-Match не может совпасть с версией несуществующей задачи
+			err := fmt.Errorf("%w: task %d does not exist", ErrVersionMismatch, id)
+			slog.Warn("[UpsertTask] Rejecting task", "task_id", id, "error", err)
+			return Task{}, false, err
+		}
+		created, err := ds.createTask(task, dryRun)
+		if !errors.Is(err, ErrTaskExists) {
+			return created, err == nil, err
+		}
+		// задачу с этим ID успели создать параллельно - обновляем её
+	}
+	updated, err := ds.updateTask(id, task, dryRun)
+	return updated, false, err
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// Проверка создания задачи через PUT /todos/{id}
+// Сценарий:
+// 1. Отправить PUT /todos/5 на пустом хранилище - ожидаем создание (201 Created) задачи с ID 5 и Location: /todos/5.
+// 2. Повторить PUT /todos/5 с другим заголовком - ожидаем обновление (200 OK) и версию 2.
+// 3. Создать задачу через POST - ожидаем ID 6: автоматические ID не пересекаются с созданными через PUT.
+// 4. Отправить PUT /todos/7 с невалидной задачей - ожидаем ошибку (422 Unprocessable Entity), задача не создаётся.
+// 5. Отправить PUT /todos/8 с If-Match - ожидаем ошибку (412 Precondition Failed).
+// 6. Удалить задачу 5 в корзину и отправить PUT /todos/5 - ожидаем ошибку (409 Conflict).
+func TestUpsertTask(t *testing.T) {
+	ts := startTestServer()
+
+	do := func(method, path, ifMatch string, task Task) (*http.Response, Task) {
+		body, _ := json.Marshal(task)
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		var got Task
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, got
+	}
+
+	resp, task := do(http.MethodPut, "/todos/5", "", Task{Title: "Created", Status: StatusNotStarted})
+	if resp.StatusCode != http.StatusCreated || task.ID != 5 || task.Version != 1 { // задача НЕ создана
+		t.Fatalf("expected 201 with task 5, got %d %+v", resp.StatusCode, task)
+	}
+	if location := resp.Header.Get("Location"); location != "/todos/5" {
+		t.Errorf("expected Location /todos/5, got %q", location)
+	}
+
+	resp, task = do(http.MethodPut, "/todos/5", "", Task{Title: "Replaced", Status: StatusInProgress})
+	if resp.StatusCode != http.StatusOK || task.Title != "Replaced" || task.Version != 2 { // задача НЕ обновлена
+		t.Errorf("expected 200 with updated task, got %d %+v", resp.StatusCode, task)
+	}
+
+	resp, task = do(http.MethodPost, "/todos", "", Task{Title: "Next", Status: StatusNotStarted})
+	if resp.StatusCode != http.StatusCreated || task.ID != 6 { // автоматический ID пересёкся с заданным
+		t.Errorf("expected 201 with task 6, got %d %+v", resp.StatusCode, task)
+	}
+
+	if resp, _ := do(http.MethodPut, "/todos/7", "", Task{Status: StatusNotStarted}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid task, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(http.MethodGet, "/todos/7", "", Task{}); resp.StatusCode != http.StatusNotFound { // невалидная задача создана
+		t.Errorf("expected 404 for rejected task, got %d", resp.StatusCode)
+	}
+
+	if resp, _ := do(http.MethodPut, "/todos/8", `"1"`, Task{Title: "Conditional", Status: StatusNotStarted}); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 for If-Match on missing task, got %d", resp.StatusCode)
+	}
+
+	if resp, _ := do(http.MethodDelete, "/todos/5", "", Task{}); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 on delete, got %d", resp.StatusCode)
+	}
+	if resp, _ := do(http.MethodPut, "/todos/5", "", Task{Title: "Again", Status: StatusNotStarted}); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected 409 for task in trash, got %d", resp.StatusCode)
+	}
+	ts.Close()
+}
+
+// Проверка PUT /todos/{id} для ID вне допустимого диапазона и dry run создания
+// Сценарий:
+// 1. Отправить PUT /todos/0 и PUT /todos/{MaxTaskID+1} - ожидаем ошибку (400 Bad Request).
+// 2. Отправить PUT /todos/9223372036854775807 - ожидаем ошибку (400 Bad Request), затем POST - ожидаем ID 1:
+// счётчик автоматических ID не переполнен.
+// 3. Отправить PUT /todos/5?dry_run=true - ожидаем 201 Created с задачей 5, но задача не создаётся (GET - 404).
+func TestUpsertTaskLimits(t *testing.T) {
+	ts := startTestServer()
+
+	do := func(method, path string, task Task) (*http.Response, Task) {
+		body, _ := json.Marshal(task)
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		var got Task
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, got
+	}
+	task := Task{Title: "Task", Status: StatusNotStarted}
+
+	for _, path := range []string{"/todos/0", "/todos/" + strconv.Itoa(MaxTaskID+1), "/todos/9223372036854775807"} {
+		if resp, _ := do(http.MethodPut, path, task); resp.StatusCode != http.StatusBadRequest { // ID вне диапазона принят
+			t.Errorf("PUT %s: expected 400, got %d", path, resp.StatusCode)
+		}
+	}
+	if resp, created := do(http.MethodPost, "/todos", task); resp.StatusCode != http.StatusCreated || created.ID != 1 {
+		t.Errorf("expected 201 with task 1, got %d %+v", resp.StatusCode, created)
+	}
+
+	resp, preview := do(http.MethodPut, "/todos/5?dry_run=true", task)
+	if resp.StatusCode != http.StatusCreated || preview.ID != 5 { // dry run НЕ показал создание
+		t.Errorf("expected dry run 201 with task 5, got %d %+v", resp.StatusCode, preview)
+	}
+	if resp, _ := do(http.MethodGet, "/todos/5", Task{}); resp.StatusCode != http.StatusNotFound { // dry run сохранил задачу
+		t.Errorf("expected 404 after dry run, got %d", resp.StatusCode)
+	}
+	ts.Close()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Users Список допустимых исполнителей задач. Безопасен для конкурентного доступа:
+// файл можно перечитать через Reload без перезапуска сервера
+type Users struct {
+	list  string // исполнители из флага через запятую
+	file  string // файл с исполнителями, по одному в строке (пусто - без файла)
+	mutex sync.RWMutex
+	names map[string]struct{}
+}
+
+// loadUsers Загрузка списка исполнителей из флагов: список через запятую и файл
+// (nil, если ни то, ни другое не задано - исполнитель может быть любым)
+func loadUsers(list, file string) (*Users, error) {
+	if strings.TrimSpace(list) == "" && file == "" {
+		return nil, nil
+	}
+	users := &Users{list: list, file: file}
+	if err := users.Reload(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Reload Перечитывает файл исполнителей. При ошибке действующий список не меняется
+func (u *Users) Reload() error {
+	names := make(map[string]struct{})
+	for _, name := range strings.Split(u.list, ",") {
+		if name = normalizeAssignee(name); name != "" {
+			names[name] = struct{}{}
+		}
+	}
+	if u.file != "" {
+		f, err := os.Open(u.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := readUsers(f, names); err != nil {
+			return fmt.Errorf("%s: %w", u.file, err)
+		}
+	}
+	u.mutex.Lock()
+	u.names = names
+	u.mutex.Unlock()
+	return nil
+}
+
+// readUsers Чтение исполнителей из потока: по имени в строке, пустые строки и строки с # пропускаются
+func readUsers(r io.Reader, names map[string]struct{}) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name := normalizeAssignee(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		names[name] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// Contains Проверка, что исполнитель есть в списке (безопасно вызывать на nil: без списка допустим любой)
+func (u *Users) Contains(name string) bool {
+	if u == nil {
+		return true
+	}
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	_, ok := u.names[name]
+	return ok
+}
+
+// Len Число исполнителей в списке
+func (u *Users) Len() int {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return len(u.names)
+}
+
+// reloadUsersHandler Обработчик эндпоинта POST /admin/users/reload служебного listener'а: перечитывает список исполнителей
+func reloadUsersHandler(users *Users) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			logRequest(r, slog.LevelWarn, "[reloadUsersHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if err := users.Reload(); err != nil {
+			logRequest(r, slog.LevelError, "[reloadUsersHandler] Reloading users", err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		slog.Info("[reloadUsersHandler] Users reloaded", "users", users.Len())
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]int{"users": users.Len()}); err != nil {
+			logRequest(r, slog.LevelError, "[reloadUsersHandler] Encoding response", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Проверка списка допустимых исполнителей
+// Сценарий:
+// 1. Задать исполнителей флагом (carol) и файлом (alice, Bob Smith, строка-комментарий).
+// 2. Создать задачи alice, "Bob   Smith" и без исполнителя - ожидаем 201, задачу dave - ожидаем 422 с полем assignee.
+// 3. Дописать dave в файл и вызвать POST /admin/users/reload - ожидаем 200 с числом исполнителей 4, задача dave создаётся.
+// 4. Удалить файл и снова вызвать перечитывание - ожидаем 500, прежний список продолжает действовать.
+// 5. Загрузить список без флага и файла - ожидаем nil: допустим любой исполнитель.
+func TestUsers(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "users.txt")
+	if err := os.WriteFile(file, []byte("alice\n# team B\n\n Bob  Smith \n"), 0o644); err != nil {
+		t.Fatalf("failed to write users file: %v", err)
+	}
+	users, err := loadUsers("carol", file)
+	if err != nil {
+		t.Fatalf("failed to load users: %v", err)
+	}
+	config := DefaultStoreConfig()
+	config.Validation.Users = users
+	ts := startTestServerWithStore(NewTaskStoreWithConfig(config))
+	defer ts.Close()
+	admin := httptest.NewServer(newAdminMux(false, users))
+	defer admin.Close()
+
+	create := func(assignee string) (int, ErrorResponse) {
+		body, _ := json.Marshal(Task{Title: "Task", Status: StatusNotStarted, Assignee: assignee})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var errResp ErrorResponse
+		if resp.StatusCode != http.StatusCreated {
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode, errResp
+	}
+	reload := func() (int, map[string]int) {
+		resp, err := http.Post(admin.URL+"/admin/users/reload", "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		var body map[string]int
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode, body
+	}
+
+	for _, assignee := range []string{"alice", "Bob   Smith", "carol", ""} {
+		if status, _ := create(assignee); status != http.StatusCreated { // известный исполнитель отклонён
+			t.Errorf("%q: expected 201, got %d", assignee, status)
+		}
+	}
+	status, errResp := create("dave")
+	if status != http.StatusUnprocessableEntity || len(errResp.Errors) != 1 || errResp.Errors[0].Field != "assignee" { // неизвестный исполнитель принят
+		t.Fatalf("expected 422 for assignee, got %d %+v", status, errResp)
+	}
+
+	if err := os.WriteFile(file, []byte("alice\nBob Smith\ndave\n"), 0o644); err != nil {
+		t.Fatalf("failed to write users file: %v", err)
+	}
+	if status, body := reload(); status != http.StatusOK || body["users"] != 4 { // список НЕ перечитан
+		t.Fatalf("expected 200 with 4 users, got %d %v", status, body)
+	}
+	if status, _ := create("dave"); status != http.StatusCreated { // получили НЕ 201
+		t.Errorf("dave after reload: expected 201, got %d", status)
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatalf("failed to remove users file: %v", err)
+	}
+	if status, _ := reload(); status != http.StatusInternalServerError { // получили НЕ 500
+		t.Errorf("reload without file: expected 500, got %d", status)
+	}
+	if !users.Contains("dave") || users.Contains("eve") { // прежний список потерян
+		t.Errorf("expected previous user list to stay in effect")
+	}
+
+	if users, err := loadUsers(" ", ""); users != nil || err != nil {
+		t.Errorf("expected no user list, got %v, %v", users, err)
+	}
+}
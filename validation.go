@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError Проблема валидации конкретного поля задачи
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors Все проблемы валидации задачи
+type ValidationErrors []FieldError
+
+// add Добавляет проблему валидации поля
+func (v *ValidationErrors) add(field, message string) {
+	*v = append(*v, FieldError{Field: field, Message: message})
+}
+
+// Error Сообщения всех проблем через "; "
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, problem := range v {
+		messages[i] = problem.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ValidationConfig Настраиваемые правила валидации задач (нулевое значение - без дополнительных ограничений)
+type ValidationConfig struct {
+	MaxTitleLen        int    // Максимальная длина заголовка в символах (0 - без ограничения)
+	MaxDescriptionLen  int    // Максимальная длина описания в символах (0 - без ограничения)
+	RequireDescription bool   // Запрещать задачи с пустым описанием
+	SingleError        bool   // Отвечать на ошибку валидации 400 с первой проблемой (прежний формат) вместо 422 со всеми
+	Users              *Users // Допустимые исполнители (nil - любой непустой исполнитель)
+}
+
+// validate Проверка задачи по настраиваемым правилам
+func (c ValidationConfig) validate(t *Task, problems *ValidationErrors) {
+	if c.MaxTitleLen > 0 && utf8.RuneCountInString(t.Title) > c.MaxTitleLen {
+		problems.add("title", fmt.Sprintf("title must be at most %d characters", c.MaxTitleLen))
+	}
+	// описание к этому моменту уже обрезано в Preprocess, краевые пробелы в длину не входят
+	if n := utf8.RuneCountInString(t.Description); c.MaxDescriptionLen > 0 && n > c.MaxDescriptionLen {
+		problems.add("description", fmt.Sprintf("description must be at most %d characters, got %d", c.MaxDescriptionLen, n))
+	}
+	if c.RequireDescription && t.Description == "" {
+		problems.add("description", "description cannot be empty")
+	}
+	if t.Assignee != "" && !c.Users.Contains(t.Assignee) {
+		problems.add("assignee", fmt.Sprintf("unknown assignee %q", t.Assignee))
+	}
+}
+
+// writeValidationError Ответ на ошибку валидации: 422 со списком всех проблем
+// или 400 с первой из них, если включён прежний формат
+func writeValidationError(w http.ResponseWriter, config ValidationConfig, err error) {
+	var problems ValidationErrors
+	if !errors.As(err, &problems) || len(problems) == 0 {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if config.SingleError {
+		writeJSONError(w, http.StatusBadRequest, problems[0].Message)
+		return
+	}
+	writeErrorResponse(w, ErrorResponse{Error: problems.Error(), Status: http.StatusUnprocessableEntity, Errors: problems})
+}
+
+// validationConfig Правила валидации хранилища (для хранилищ, кроме *TaskStore, - без дополнительных ограничений)
+func validationConfig(s Store) ValidationConfig {
+	if ts, ok := s.(*TaskStore); ok {
+		return ts.config.Validation
+	}
+	return ValidationConfig{}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// Проверка настраиваемых правил валидации
+// Сценарий:
+// 1. Создать хранилище с ограничением заголовка в 5 символов и обязательным описанием.
+// 2. Создать задачу с заголовком из 6 символов - ожидаем 422 с указанием лимита.
+// 3. Создать задачу без описания - ожидаем 422.
+// 4. Создать задачу с заголовком из 5 символов (кириллица) и описанием - ожидаем 201.
+func TestValidationConfig(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.Validation = ValidationConfig{MaxTitleLen: 5, RequireDescription: true}
+	ts := startTestServerWithStore(NewTaskStoreWithConfig(config))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		task    Task
+		status  int
+		message string
+	}{
+		{"long title", Task{Title: "Задача", Description: "Описание", Status: StatusNotStarted}, http.StatusUnprocessableEntity,
+			"title must be at most 5 characters"},
+		{"no description", Task{Title: "Дело", Status: StatusNotStarted}, http.StatusUnprocessableEntity,
+			"description cannot be empty"},
+		{"valid", Task{Title: "Дело!", Description: "Описание", Status: StatusNotStarted}, http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(tt.task)
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if resp.StatusCode != tt.status { // неожиданный статус
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+		if tt.message != "" {
+			var errResp ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.Error != tt.message { // сообщение НЕ содержит лимит
+				t.Errorf("%s: expected %q, got %q", tt.name, tt.message, errResp.Error)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+}
+
+// Проверка ограничения длины описания
+// Сценарий:
+// 1. Создать хранилище с ограничением описания в 4 символа.
+// 2. Создать задачу с описанием из 5 символов - ожидаем 422 с лимитом и фактической длиной.
+// 3. Создать задачу с описанием из 4 символов кириллицы (8 байт) с пробелами по краям - ожидаем 201.
+func TestMaxDescriptionLen(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.Validation = ValidationConfig{MaxDescriptionLen: 4}
+	ts := startTestServerWithStore(NewTaskStoreWithConfig(config))
+	defer ts.Close()
+
+	tests := []struct {
+		name        string
+		description string
+		status      int
+		message     string
+	}{
+		{"long description", "Длинн", http.StatusUnprocessableEntity, "description must be at most 4 characters, got 5"},
+		{"trimmed description", "  Дело  ", http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(Task{Title: "Task", Description: tt.description, Status: StatusNotStarted})
+		resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("failed to make POST: %v", err)
+		}
+		if resp.StatusCode != tt.status { // неожиданный статус
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, resp.StatusCode)
+		}
+		if tt.message != "" {
+			var errResp ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if errResp.Error != tt.message { // сообщение НЕ содержит лимит и длину
+				t.Errorf("%s: expected %q, got %q", tt.name, tt.message, errResp.Error)
+			}
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+	}
+}
+
+// Проверка прежнего формата ошибок валидации
+// Сценарий:
+// 1. Создать хранилище с SingleError и создать задачу с двумя проблемами - ожидаем 400 только с первой из них.
+func TestSingleValidationError(t *testing.T) {
+	config := DefaultStoreConfig()
+	config.Validation = ValidationConfig{SingleError: true}
+	ts := startTestServerWithStore(NewTaskStoreWithConfig(config))
+	defer ts.Close()
+
+	body, _ := json.Marshal(Task{Title: "", Status: "bad"})
+	resp, err := http.Post(ts.URL+"/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+	var errResp ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if errResp.Error != "title cannot be empty" || errResp.Errors != nil { // ответ НЕ в прежнем формате
+		t.Errorf("expected only the first problem, got %+v", errResp)
+	}
+}
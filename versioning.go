@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// apiVersions Версии API: маршруты задач каждой версии доступны под её префиксом (последняя - текущая)
+var apiVersions = []struct {
+	prefix string
+	routes []taskRoute
+}{
+	{"/v1", taskRoutes},
+}
+
+// unversionedWarning Предупреждение для запросов к путям без версии
+const unversionedWarning = `299 - "Unversioned API paths are deprecated, use /v1"`
+
+// deprecatedPath Обёртка обработчика пути без версии: добавляет заголовок Warning
+func deprecatedPath(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Warning", unversionedWarning)
+		next(w, r)
+	}
+}
+
+// Версии схемы задач (заголовок X-API-Version или Accept-Version): v1 - без меток времени, v2 - с ними
+const (
+	schemaV1            = 1
+	schemaV2            = 2
+	latestSchemaVersion = schemaV2
+)
+
+// v1OmittedFields Поля задачи, которых нет в схеме v1 (метки времени, назначаемые сервером)
+var v1OmittedFields = []string{"created_at", "updated_at", "completed_at", "deleted_at"}
+
+// taskFieldNames Имена JSON-полей задачи (для маски полей схемы v1 без X-Fields)
+var taskFieldNames = func() []string {
+	var names []string
+	taskType := reflect.TypeFor[Task]()
+	for i := range taskType.NumField() {
+		field := taskType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}()
+
+// schemaVersion Версия схемы из заголовка X-API-Version (или Accept-Version); без заголовка - последняя
+func schemaVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("X-API-Version"))
+	if header == "" {
+		header = strings.TrimSpace(r.Header.Get("Accept-Version"))
+	}
+	if header == "" {
+		return latestSchemaVersion, nil
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(header), "v"))
+	if err != nil || version < schemaV1 || version > latestSchemaVersion {
+		return 0, fmt.Errorf("unsupported API version %q, supported versions are 1 and 2", header)
+	}
+	return version, nil
+}
+
+// versionedSchema Обёртка обработчика задач: отвечает 400 на неизвестную версию схемы до обработки запроса
+// и сообщает выбранную версию в X-API-Version. Тела запросов обеих версий читаются одинаково: метки времени
+// назначает сервер, в v2 присланные значения игнорируются, в v1 их нет
+func versionedSchema(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, err := schemaVersion(r)
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[versionedSchema] Unknown schema version", err)
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Add("Vary", "X-API-Version, Accept-Version") // форма задачи зависит от версии схемы
+		w.Header().Set("X-API-Version", strconv.Itoa(version))
+		next(w, r)
+	}
+}
+
+// registerTaskRoutes Регистрация маршрутов задач под префиксом в общем пространстве и в пространствах тенантов.
+// wrap, если не nil, оборачивает каждый обработчик
+func registerTaskRoutes(mux *http.ServeMux, prefix string, routes []taskRoute, ts *TaskStore, tr *TenantRegistry,
+	wrap func(http.HandlerFunc) http.HandlerFunc) {
+	for _, route := range routes {
+		handler := versionedSchema(route.handler(ts))
+		tenant := versionedSchema(tenantHandler(tr, route.handler))
+		if wrap != nil {
+			handler, tenant = wrap(handler), wrap(tenant)
+		}
+		mux.HandleFunc(prefix+route.pattern, handler)
+		mux.HandleFunc(prefix+"/t/{tenant}"+route.pattern, tenant)
+	}
+}
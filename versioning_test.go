@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Проверка версионирования API
+// Сценарий:
+// 1. Создать задачу через /v1/todos - ожидаем успех (201 Created) и отсутствие Warning.
+// 2. Получить её по пути без версии - ожидаем ту же задачу и заголовок Warning: 299.
+// 3. Запросить задачу тенанта через /v1/t/{tenant}/todos - ожидаем успех (200 OK).
+// 4. Запросить /healthz - ожидаем успех без версии и без Warning.
+func TestAPIVersioning(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	// Создаём задачу через /v1
+	body, _ := json.Marshal(Task{Title: "Versioned", Status: StatusNotStarted})
+	resp, err := http.Post(ts.URL+"/v1/todos", "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to make POST: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("failed to close response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Warning"); got != "" { // версионированный путь помечен устаревшим
+		t.Errorf("expected no Warning on /v1, got %q", got)
+	}
+	// Проверяем остальные пути
+	for path, deprecated := range map[string]bool{
+		"/v1/todos/1":      false,
+		"/todos/1":         true,
+		"/v1/t/acme/todos": false,
+		"/healthz":         false,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("failed to make GET: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Errorf("GET %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Warning"); deprecated != strings.HasPrefix(got, "299 ") {
+			t.Errorf("GET %s: unexpected Warning %q", path, got)
+		}
+	}
+}
+
+// Проверка выбора версии схемы заголовком
+// Сценарий:
+// 1. Создать задачу и получить её без заголовка версии - ожидаем метки времени и X-API-Version: 2.
+// 2. Получить её с X-API-Version: 1 (и с Accept-Version: 1) - ожидаем задачу без меток времени.
+// 3. Получить её с X-API-Version: 1 и X-Fields: id,created_at - ожидаем только id.
+// 4. Создать задачу с X-API-Version: 1 - ожидаем успех (201 Created) и ответ без меток времени.
+// 5. Запросить задачу с X-API-Version: 3 - ожидаем ошибку (400 Bad Request).
+func TestSchemaVersion(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	do := func(method, path string, body []byte, headers map[string]string) (*http.Response, map[string]any) {
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		var object map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&object)
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp, object
+	}
+
+	body, _ := json.Marshal(Task{Title: "Versioned schema", Status: StatusNotStarted})
+	if resp, _ := do(http.MethodPost, "/todos", body, nil); resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	// Последняя версия по умолчанию
+	resp, task := do(http.MethodGet, "/todos/1", nil, nil)
+	if got := resp.Header.Get("X-API-Version"); got != "2" { // версия по умолчанию НЕ последняя
+		t.Errorf("expected X-API-Version 2, got %q", got)
+	}
+	if _, ok := task["created_at"]; !ok { // в v2 нет меток времени
+		t.Errorf("expected created_at in v2, got %v", task)
+	}
+	if vary := strings.Join(resp.Header.Values("Vary"), ", "); !strings.Contains(vary, "X-API-Version") { // кэш НЕ различает версии
+		t.Errorf("expected Vary to include X-API-Version, got %q", vary)
+	}
+	// Версия 1 через оба заголовка
+	for _, header := range []string{"X-API-Version", "Accept-Version"} {
+		resp, task := do(http.MethodGet, "/todos/1", nil, map[string]string{header: "1"})
+		if resp.StatusCode != http.StatusOK { // получили НЕ 200
+			t.Fatalf("%s: expected 200, got %d", header, resp.StatusCode)
+		}
+		if got := resp.Header.Get("X-API-Version"); got != "1" {
+			t.Errorf("%s: expected X-API-Version 1, got %q", header, got)
+		}
+		for _, field := range v1OmittedFields {
+			if _, ok := task[field]; ok { // в v1 осталась метка времени
+				t.Errorf("%s: unexpected %s in v1: %v", header, field, task)
+			}
+		}
+		if task["title"] != "Versioned schema" { // потеряли остальные поля
+			t.Errorf("%s: expected title in v1, got %v", header, task)
+		}
+	}
+	// Маска X-Fields не возвращает полей, которых нет в версии
+	_, task = do(http.MethodGet, "/todos/1", nil, map[string]string{"X-API-Version": "1", "X-Fields": "id,created_at"})
+	if len(task) != 1 || task["id"] != float64(1) {
+		t.Errorf("expected only id, got %v", task)
+	}
+	// Создание в v1
+	resp, task = do(http.MethodPost, "/todos", body, map[string]string{"X-API-Version": "1"})
+	if resp.StatusCode != http.StatusCreated { // получили НЕ 201
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+	if _, ok := task["updated_at"]; ok { // в v1 осталась метка времени
+		t.Errorf("unexpected updated_at in v1: %v", task)
+	}
+	// Неизвестная версия
+	if resp, _ := do(http.MethodGet, "/todos/1", nil, map[string]string{"X-API-Version": "3"}); resp.StatusCode != http.StatusBadRequest { // получили НЕ 400
+		t.Errorf("expected 400 for unknown version, got %d", resp.StatusCode)
+	}
+}
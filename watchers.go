@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxWatchers Максимальное число наблюдателей задачи
+const maxWatchers = 100
+
+// maxWatcherLen Максимальная длина идентификатора наблюдателя в символах
+const maxWatcherLen = 64
+
+// ErrTooManyWatchers Ошибка добавления наблюдателя сверх maxWatchers
+var ErrTooManyWatchers = errors.New("too many watchers")
+
+// WatcherOp Операция над наблюдателями задачи (тело PATCH /todos/{id}/watchers)
+type WatcherOp struct {
+	Op      string `json:"op"`      // add или remove
+	Watcher string `json:"watcher"` // идентификатор наблюдателя
+}
+
+// validateWatcher Проверка идентификатора наблюдателя: 1-64 символа, буквы, цифры и . _ @ -
+func validateWatcher(watcher string) error {
+	if !utf8.ValidString(watcher) {
+		return fmt.Errorf("watcher must be valid UTF-8")
+	}
+	if n := utf8.RuneCountInString(watcher); n == 0 || n > maxWatcherLen {
+		return fmt.Errorf("watcher must be 1 to %d characters long", maxWatcherLen)
+	}
+	for _, r := range watcher {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("._@-", r) {
+			return fmt.Errorf("watcher %q may contain only letters, digits and . _ @ -", watcher)
+		}
+	}
+	return nil
+}
+
+// checkWatcher Проверка наблюдателя: корректный идентификатор и, если задан список пользователей, пользователь из него
+func (c ValidationConfig) checkWatcher(watcher string) error {
+	if err := validateWatcher(watcher); err != nil {
+		return err
+	}
+	if !c.Users.Contains(watcher) {
+		return fmt.Errorf("unknown watcher %q", watcher)
+	}
+	return nil
+}
+
+// normalizeWatchers Нормализация наблюдателей: обрезка пробелов и удаление повторов с сохранением порядка
+func normalizeWatchers(watchers []string) []string {
+	var normalized []string
+	for _, watcher := range watchers {
+		watcher = strings.TrimSpace(watcher)
+		if !slices.Contains(normalized, watcher) {
+			normalized = append(normalized, watcher)
+		}
+	}
+	return normalized
+}
+
+// Validate Валидация операции над наблюдателями (идентификатор уже нормализован)
+func (op *WatcherOp) Validate(config ValidationConfig) error {
+	if op.Op != "add" && op.Op != "remove" {
+		return fmt.Errorf("unknown watchers operation %q", op.Op)
+	}
+	if op.Op == "remove" { // удалить можно и наблюдателя, которого уже нет в списке пользователей
+		return validateWatcher(op.Watcher)
+	}
+	return config.checkWatcher(op.Watcher)
+}
+
+// UpdateWatchers Применяет операцию к наблюдателям задачи по ID (version, если не 0, - ожидаемая версия задачи).
+// Добавление имеющегося и удаление отсутствующего наблюдателя не меняют задачу
+func (ds *TaskStore) UpdateWatchers(id int, op WatcherOp, version int) (Task, error) {
+	ds.mutex.Lock()
+	task, ok := ds.liveTask(id)
+	if !ok { // задача с таким ID не найдена
+		ds.mutex.Unlock()
+		err := fmt.Errorf("task with id %d not found", id)
+		slog.Warn("[UpdateWatchers] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	if err := checkVersion(task, version); err != nil { // задачу успели изменить
+		ds.mutex.Unlock()
+		slog.Warn("[UpdateWatchers] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	watching := slices.Contains(task.Watchers, op.Watcher)
+	if watching == (op.Op == "add") { // менять нечего
+		task = ds.numberTask(task)
+		ds.mutex.Unlock()
+		return task, nil
+	}
+	if op.Op == "add" && len(task.Watchers) >= maxWatchers {
+		ds.mutex.Unlock()
+		err := fmt.Errorf("%w: limit is %d", ErrTooManyWatchers, maxWatchers)
+		slog.Warn("[UpdateWatchers] Rejecting update", "task_id", id, "error", err)
+		return Task{}, err
+	}
+	before := task
+	// новый срез, чтобы не менять срез, который мог быть отдан читателям
+	if op.Op == "add" {
+		task.Watchers = append(slices.Clip(task.Watchers), op.Watcher)
+	} else {
+		task.Watchers = slices.DeleteFunc(slices.Clone(task.Watchers), func(w string) bool { return w == op.Watcher })
+	}
+	task.UpdatedAt = time.Now().UTC()
+	task.Version++
+	ds.tasks[id] = task
+	task = ds.numberTask(task)
+	ds.record(TaskEventUpdated, &before, &task)
+	ds.mutex.Unlock()
+	return task, nil
+}
+
+// watchersHandler Обработчик эндпоинта /todos/{id}/watchers
+func watchersHandler(ts *TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			logRequest(r, slog.LevelWarn, "[watchersHandler] Invalid method", nil)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[watchersHandler] Invalid id", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		var op WatcherOp
+		if err := json.NewDecoder(r.Body).Decode(&op); err != nil {
+			logRequest(r, slog.LevelWarn, "[watchersHandler] Decoding", err, "task_id", id)
+			writeDecodeError(w, err)
+			return
+		}
+		op.Watcher = strings.TrimSpace(op.Watcher)
+		config := validationConfig(ts)
+		if err := op.Validate(config); err != nil {
+			logRequest(r, slog.LevelWarn, "[watchersHandler] Validation", err, "task_id", id)
+			writeValidationError(w, config, ValidationErrors{{Field: "watcher", Message: err.Error()}})
+			return
+		}
+		task, err := ts.UpdateWatchers(id, op, ifMatchVersion(r))
+		if err != nil {
+			logRequest(r, slog.LevelWarn, "[watchersHandler] Updating watchers", err, "task_id", id)
+			status := updateErrorStatus(err)
+			if errors.Is(err, ErrTooManyWatchers) {
+				status = http.StatusConflict
+			}
+			writeJSONError(w, status, err.Error())
+			return
+		}
+		location := strings.TrimSuffix(r.URL.Path, "/watchers")
+		if err := writeMutationResult(w, r, http.StatusOK, task, location, true); err != nil {
+			logRequest(r, slog.LevelError, "[watchersHandler] Encoding task", err, "task_id", id)
+			return
+		}
+	}
+}
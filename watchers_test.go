@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Проверка наблюдателей задачи
+// Сценарий:
+// 1. Создать задачу с наблюдателями " alice ", "alice" и "bob" - ожидаем нормализованный список [alice bob].
+// 2. Создать задачу с наблюдателем "a b" - ожидаем 422 Unprocessable Entity с полем watchers[0].
+// 3. Подписаться на /todos/events?watcher=carol и добавить carol наблюдателем задачи 2 (без наблюдателей) через PATCH -
+// ожидаем 200, версию 2 и событие updated только по задаче 2 (изменение задачи 1 в поток carol не попадает).
+// 4. Повторно добавить carol - ожидаем 200 без изменения версии.
+// 5. Удалить bob из задачи 1 - ожидаем [alice dave]; неизвестная операция - ожидаем 422.
+func TestWatchers(t *testing.T) {
+	ts := startTestServer()
+	defer ts.Close()
+
+	send := func(method, path string, v any) (int, Task, ErrorResponse) {
+		body, _ := json.Marshal(v)
+		req, _ := http.NewRequest(method, ts.URL+path, bytes.NewBuffer(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make %s: %v", method, err)
+		}
+		var task Task
+		var errResp ErrorResponse
+		if resp.StatusCode < http.StatusBadRequest {
+			err = json.NewDecoder(resp.Body).Decode(&task)
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&errResp)
+		}
+		if err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatalf("failed to close response body: %v", err)
+		}
+		return resp.StatusCode, task, errResp
+	}
+
+	_, task, _ := send(http.MethodPost, "/todos", Task{Title: "Watched", Status: StatusNotStarted, Watchers: []string{" alice ", "alice", "bob"}})
+	if !slices.Equal(task.Watchers, []string{"alice", "bob"}) { // наблюдатели НЕ нормализованы
+		t.Fatalf("expected watchers [alice bob], got %v", task.Watchers)
+	}
+	status, _, errResp := send(http.MethodPost, "/todos", Task{Title: "Bad", Status: StatusNotStarted, Watchers: []string{"a b"}})
+	if status != http.StatusUnprocessableEntity || len(errResp.Errors) != 1 || errResp.Errors[0].Field != "watchers[0]" { // некорректный наблюдатель принят
+		t.Fatalf("expected 422 for watchers[0], got %d %+v", status, errResp)
+	}
+	send(http.MethodPost, "/todos", Task{Title: "Unwatched", Status: StatusNotStarted})
+
+	resp, err := http.Get(ts.URL + "/todos/events?watcher=carol")
+	if err != nil {
+		t.Fatalf("failed to make GET: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	// readEvent Чтение одного события (тип и данные)
+	readEvent := func() (string, Task) {
+		var eventType string
+		var task Task
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return eventType, task
+			case strings.HasPrefix(line, "event: "):
+				eventType = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &task); err != nil {
+					t.Fatalf("failed to decode event data: %v", err)
+				}
+			}
+		}
+	}
+
+	// задача 1 меняется раньше, её событие должно быть отфильтровано
+	send(http.MethodPatch, "/todos/1/watchers", WatcherOp{Op: "add", Watcher: "dave"})
+	status, task, _ = send(http.MethodPatch, "/todos/2/watchers", WatcherOp{Op: "add", Watcher: " carol "})
+	if status != http.StatusOK || task.Version != 2 || !slices.Equal(task.Watchers, []string{"carol"}) { // наблюдатель НЕ добавлен
+		t.Fatalf("expected carol added in version 2, got %d %+v", status, task)
+	}
+	if eventType, event := readEvent(); eventType != "updated" || event.ID != 2 { // событие НЕ для carol
+		t.Errorf("expected updated event for task 2, got %q %+v", eventType, event)
+	}
+	if _, task, _ = send(http.MethodPatch, "/todos/2/watchers", WatcherOp{Op: "add", Watcher: "carol"}); task.Version != 2 { // повтор изменил задачу
+		t.Errorf("expected repeated add to keep version 2, got %d", task.Version)
+	}
+	if _, task, _ = send(http.MethodPatch, "/todos/1/watchers", WatcherOp{Op: "remove", Watcher: "bob"}); !slices.Equal(task.Watchers, []string{"alice", "dave"}) {
+		t.Errorf("expected watchers [alice dave], got %v", task.Watchers)
+	}
+	if status, _, _ = send(http.MethodPatch, "/todos/1/watchers", WatcherOp{Op: "follow", Watcher: "bob"}); status != http.StatusUnprocessableEntity { // получили НЕ 422
+		t.Errorf("unknown operation: expected 422, got %d", status)
+	}
+}
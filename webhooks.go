@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	webhookQueueSize = 256              // Размер очереди доставки на один адрес (при переполнении события теряются)
+	webhookTimeout   = 10 * time.Second // Таймаут одной попытки доставки
+)
+
+// WebhookConfig Настройки уведомлений об изменениях задач
+type WebhookConfig struct {
+	URLs    []string      // Адреса, на которые отправляются события
+	Secret  string        // Ключ подписи тела (пустой - без подписи)
+	Retries int           // Число повторов после неудачной попытки
+	Backoff time.Duration // Пауза перед первым повтором, дальше удваивается
+	Client  *http.Client  // nil - клиент с таймаутом webhookTimeout
+}
+
+// webhookPayload Тело уведомления
+type webhookPayload struct {
+	Event  TaskEventType `json:"event"`
+	Tenant string        `json:"tenant,omitempty"` // Тенант задачи (нет - общее пространство)
+	Task   Task          `json:"task"`
+	Time   time.Time     `json:"time"`
+}
+
+// parseWebhookURLs Разбор списка адресов через запятую (допускаются только http и https)
+func parseWebhookURLs(list string) ([]string, error) {
+	var urls []string
+	for _, raw := range strings.Split(list, ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook url %q: must be an absolute http or https url", raw)
+		}
+		urls = append(urls, raw)
+	}
+	return urls, nil
+}
+
+// signPayload Подпись тела уведомления: "sha256=" + HMAC-SHA256 в hex
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// runWebhooks Рассылает события хранилищ на адреса уведомлений (блокирует вызывающую горутину до закрытия events).
+// У каждого адреса своя очередь и своя горутина доставки, поэтому медленный получатель не задерживает остальных и API
+func runWebhooks(config WebhookConfig, events <-chan TaskEvent) {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: webhookTimeout}
+	}
+	queues := make([]chan webhookPayload, len(config.URLs))
+	for i, target := range config.URLs {
+		queues[i] = make(chan webhookPayload, webhookQueueSize)
+		go func() {
+			for payload := range queues[i] {
+				config.deliver(target, payload)
+			}
+		}()
+	}
+	for event := range events {
+		payload := webhookPayload{Event: event.Type, Tenant: event.Tenant, Task: event.Task, Time: time.Now().UTC()}
+		for i, queue := range queues {
+			select {
+			case queue <- payload:
+			default: // получатель не успевает принимать уведомления
+				slog.Warn("[runWebhooks] Dropping event for slow webhook", "url", config.URLs[i], "task_id", event.Task.ID, "event", event.Type)
+			}
+		}
+	}
+	for _, queue := range queues {
+		close(queue)
+	}
+}
+
+// deliver Доставка уведомления с повторами и экспоненциальной паузой между ними
+func (c WebhookConfig) deliver(target string, payload webhookPayload) bool {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("[deliver] Encoding webhook payload", "error", err)
+		return false
+	}
+	backoff := c.Backoff
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = c.send(target, payload.Event, body); err == nil {
+			return true
+		}
+		slog.Warn("[deliver] Webhook attempt failed", "url", target, "attempt", attempt+1, "error", err)
+	}
+	slog.Error("[deliver] Giving up on webhook", "url", target, "task_id", payload.Task.ID, "event", payload.Event,
+		"attempts", c.Retries+1, "error", err)
+	return false
+}
+
+// send Одна попытка доставки (успех - ответ 2xx)
+func (c WebhookConfig) send(target string, event TaskEventType, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", string(event))
+	if c.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", signPayload(c.Secret, body))
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	if err := resp.Body.Close(); err != nil {
+		slog.Warn("[send] Closing webhook response body", "url", target, "error", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Проверка уведомлений об изменениях задач
+// Сценарий:
+// 1. Поднять получателя, который дважды отвечает 500, а затем принимает уведомления.
+// 2. Подписать рассылку на хранилище и создать задачу - ожидаем, что после двух повторов получатель
+// примет уведомление created с задачей и корректной подписью.
+func TestWebhookDelivery(t *testing.T) {
+	const secret = "webhook-secret"
+	var attempts atomic.Int32
+	received := make(chan webhookPayload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= 2 { // первые попытки завершаются ошибкой
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get("X-Webhook-Signature"); got != signPayload(secret, body) { // подпись НЕ совпадает
+			t.Errorf("unexpected signature %q", got)
+		}
+		if got := r.Header.Get("X-Webhook-Event"); got != string(TaskEventCreated) {
+			t.Errorf("expected event header %q, got %q", TaskEventCreated, got)
+		}
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	// Подписываем рассылку и создаём задачу
+	ds := NewTaskStore()
+	events, unsubscribe := ds.Subscribe()
+	defer unsubscribe()
+	go runWebhooks(WebhookConfig{URLs: []string{receiver.URL}, Secret: secret, Retries: 3, Backoff: time.Millisecond}, events)
+	if _, err := ds.AddTask(Task{Title: "Notify", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	select {
+	case payload := <-received:
+		if payload.Event != TaskEventCreated || payload.Task.ID != 1 || payload.Task.Title != "Notify" { // уведомление НЕ то
+			t.Errorf("unexpected payload %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	if got := attempts.Load(); got != 3 { // повторов было НЕ два
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+// Проверка отказа от доставки после исчерпания повторов
+// Сценарий:
+// 1. Доставить уведомление получателю, который всегда отвечает 500, с двумя повторами - ожидаем
+// ровно три попытки и неуспех доставки.
+// 2. Разобрать список адресов с относительным адресом - ожидаем ошибку.
+func TestWebhookGivesUp(t *testing.T) {
+	var attempts atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	config := WebhookConfig{Retries: 2, Backoff: time.Millisecond, Client: receiver.Client()}
+	if config.deliver(receiver.URL, webhookPayload{Event: TaskEventDeleted, Task: Task{ID: 1}}) { // доставка считается успешной
+		t.Error("expected delivery to fail")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+
+	if _, err := parseWebhookURLs("https://example.com/hook, /relative"); err == nil { // относительный адрес принят
+		t.Error("expected error for relative webhook url")
+	}
+}
+
+// Проверка уведомлений для тенантов
+// Сценарий:
+// 1. Направить события всех хранилищ в общий канал рассылки.
+// 2. Создать задачу у тенанта acme - ожидаем уведомление created с tenant "acme".
+func TestWebhookTenants(t *testing.T) {
+	received := make(chan webhookPayload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	notify := make(chan TaskEvent, webhookQueueSize)
+	config := DefaultStoreConfig()
+	config.Notify = notify
+	go runWebhooks(WebhookConfig{URLs: []string{receiver.URL}}, notify)
+	tr := NewTenantRegistry(config)
+	store, err := tr.Store("acme")
+	if err != nil {
+		t.Fatalf("failed to create tenant store: %v", err)
+	}
+	if _, err := store.AddTask(Task{Title: "Tenant task", Status: StatusNotStarted}); err != nil {
+		t.Fatalf("failed to add task: %v", err)
+	}
+	select {
+	case payload := <-received:
+		if payload.Event != TaskEventCreated || payload.Tenant != "acme" || payload.Task.Title != "Tenant task" { // уведомление НЕ то
+			t.Errorf("unexpected payload %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	close(notify)
+}